@@ -19,15 +19,22 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/GoogleContainerTools/container-diff/cmd/util/output"
 	"github.com/GoogleContainerTools/container-diff/differs"
 	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/GoogleContainerTools/container-diff/version"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+var outputFormat string
+var groupBy string
+
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze image",
 	Short: "Analyzes an image: container-diff analyze image",
@@ -35,7 +42,7 @@ var analyzeCmd = &cobra.Command{
 
 For details on how to specify images, run: container-diff help`,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if err := validateArgs(args, checkAnalyzeArgNum, checkIfValidAnalyzer); err != nil {
+		if err := validateArgs(args, checkAnalyzeArgNum, checkOutputFormat, checkGroupBy, checkIfValidAnalyzer, checkVulnDBFlag); err != nil {
 			return err
 		}
 		return nil
@@ -55,6 +62,40 @@ func checkAnalyzeArgNum(args []string) error {
 	return nil
 }
 
+// sbomAnalyzers are the analyzers run by default for SBOM output formats
+var sbomAnalyzers = []string{"apt", "rpm", "pip", "node", "emerge"}
+
+// checkOutputFormat checks the SBOM output format, if any, and defaults to the
+// sbomAnalyzers when no analyzer is specified.
+func checkOutputFormat(_ []string) error {
+	if outputFormat == "" {
+		return nil
+	}
+	for _, sbomFormat := range util.SBOMFormats {
+		if outputFormat == sbomFormat {
+			if len(types) == 0 {
+				types = sbomAnalyzers
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("Output format %s is not valid, expected one of %s", outputFormat, strings.Join(util.SBOMFormats, ", "))
+}
+
+// checkGroupBy checks that results are only grouped by package URL, and not
+// when writing an SBOM.
+func checkGroupBy(_ []string) error {
+	switch {
+	case groupBy == "":
+		return nil
+	case groupBy != "purl":
+		return fmt.Errorf("Cannot group by %s, only by purl", groupBy)
+	case outputFormat != "":
+		return errors.New("--group-by can't be used with --output-format")
+	}
+	return nil
+}
+
 func analyzeImage(imageName string, analyzerArgs []string) error {
 	analyzeTypes, err := differs.GetAnalyzers(analyzerArgs)
 	if err != nil {
@@ -82,7 +123,15 @@ func analyzeImage(imageName string, analyzerArgs []string) error {
 	}
 
 	logrus.Info("retrieving analyses")
-	outputResults(analyses)
+	if outputFormat != "" {
+		if err := outputSBOM(image, analyses); err != nil {
+			return errors.Wrap(err, "writing SBOM")
+		}
+	} else if groupBy == "purl" {
+		outputResults(groupByPackageURL(image, analyses))
+	} else {
+		outputResults(analyses)
+	}
 
 	if noCache && save {
 		logrus.Infof("image was saved at %s", image.FSPath)
@@ -91,8 +140,49 @@ func analyzeImage(imageName string, analyzerArgs []string) error {
 	return nil
 }
 
+// outputSBOM writes the packages of the analyses as an SBOM in the output format,
+// with the image digest as provenance.
+func outputSBOM(image pkgutil.Image, analyses map[string]util.Result) error {
+	writer, err := getWriter(outputFile)
+	if err != nil {
+		return errors.Wrap(err, "getting writer for output file")
+	}
+	source := util.SBOMSource{
+		Image:   image.Source,
+		Distro:  util.GetDistro(image.FSPath),
+		Tool:    version.GetShortVersion(),
+		Created: time.Now(),
+	}
+	if image.Digest.Hex != "" {
+		source.Digest = image.Digest.String()
+	}
+	return util.WriteSBOM(writer, outputFormat, source, util.GetSBOMPackages(analyses, source.Distro))
+}
+
+// groupByPackageURL replaces the package analysis results with a single list of
+// packages keyed by package URL.
+func groupByPackageURL(image pkgutil.Image, analyses map[string]util.Result) map[string]util.Result {
+	grouped := map[string]util.Result{
+		"PURL": &util.PURLAnalyzeResult{
+			Image:       image.Source,
+			AnalyzeType: "PURL",
+			Analysis:    util.GroupByPackageURL(analyses, util.GetDistro(image.FSPath)),
+		},
+	}
+	for name, result := range analyses {
+		switch result.(type) {
+		case *util.SingleVersionPackageAnalyzeResult, *util.MultiVersionPackageAnalyzeResult:
+			continue
+		}
+		grouped[name] = result
+	}
+	return grouped
+}
+
 func init() {
 	RootCmd.AddCommand(analyzeCmd)
 	addSharedFlags(analyzeCmd)
+	analyzeCmd.Flags().StringVar(&groupBy, "group-by", "", "Set to purl to merge the packages found by the package analyzers into a single list keyed by package URL.")
+	analyzeCmd.Flags().StringVar(&outputFormat, "output-format", "", fmt.Sprintf("SBOM format to output the packages found by the package analyzers in, one of %s. Defaults to the apt, rpm, pip, node and emerge analyzers when no --type is set.", strings.Join(util.SBOMFormats, ", ")))
 	output.AddFlags(analyzeCmd)
 }
@@ -38,3 +38,47 @@ func TestAnalyzeArgNum(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckOutputFormat(t *testing.T) {
+	defer func() { outputFormat, types = "", nil }()
+
+	outputFormat, types = "spdx-json", nil
+	if err := checkOutputFormat(nil); err != nil {
+		t.Errorf("Got unexpected error: %s", err)
+	}
+	if len(types) != len(sbomAnalyzers) {
+		t.Errorf("Expected the SBOM analyzers by default but got %v", types)
+	}
+
+	outputFormat, types = "cyclonedx-json", []string{"pip"}
+	if err := checkOutputFormat(nil); err != nil {
+		t.Errorf("Got unexpected error: %s", err)
+	}
+	if len(types) != 1 {
+		t.Errorf("Expected the specified analyzers to be kept but got %v", types)
+	}
+
+	outputFormat = "spdx-xml"
+	if err := checkOutputFormat(nil); err == nil {
+		t.Errorf("Expected error for invalid output format")
+	}
+}
+
+func TestCheckGroupBy(t *testing.T) {
+	defer func() { groupBy, outputFormat = "", "" }()
+
+	for _, test := range []struct {
+		groupBy, outputFormat string
+		shouldError           bool
+	}{
+		{"", "", false},
+		{"purl", "", false},
+		{"name", "", true},
+		{"purl", "spdx-json", true},
+	} {
+		groupBy, outputFormat = test.groupBy, test.outputFormat
+		if err := checkGroupBy(nil); (err != nil) != test.shouldError {
+			t.Errorf("--group-by=%s --output-format=%s: expected error %t but got %v", test.groupBy, test.outputFormat, test.shouldError, err)
+		}
+	}
+}
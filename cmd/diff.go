@@ -40,7 +40,7 @@ var diffCmd = &cobra.Command{
 
 For details on how to specify images, run: container-diff help`,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if err := validateArgs(args, checkDiffArgNum, checkIfValidAnalyzer, checkFilenameFlag); err != nil {
+		if err := validateArgs(args, checkDiffArgNum, checkIfValidAnalyzer, checkFilenameFlag, checkVulnDBFlag); err != nil {
 			return err
 		}
 		return nil
@@ -72,8 +72,27 @@ func checkFilenameFlag(_ []string) error {
 	return errors.New("please include --type=file with the --filename flag")
 }
 
-// processImage is a concurrency-friendly wrapper around getImageForName
+// checkSBOMAnalyzers ensures that only package analyzers are used when an SBOM
+// is given in place of an image.
+func checkSBOMAnalyzers(image1Arg, image2Arg string, analyzers []differs.Analyzer) error {
+	if !util.IsSBOM(image1Arg) && !util.IsSBOM(image2Arg) {
+		return nil
+	}
+	for _, analyzer := range analyzers {
+		if !differs.SupportsSBOM(analyzer) {
+			return fmt.Errorf("%s can't be used with an SBOM, only the package analyzers can", analyzer.Name())
+		}
+	}
+	return nil
+}
+
+// processImage is a concurrency-friendly wrapper around getImageForName.
+// SBOM documents given in place of an image aren't retrieved: their packages
+// are read by the package analyzers.
 func processImage(imageName string, errChan chan<- error) *pkgutil.Image {
+	if util.IsSBOM(imageName) {
+		return &pkgutil.Image{Source: imageName, SBOMPath: imageName}
+	}
 	image, err := getImage(imageName)
 	if err != nil {
 		errChan <- fmt.Errorf("error retrieving image %s: %s", imageName, err)
@@ -100,6 +119,9 @@ func diffImages(image1Arg, image2Arg string, diffArgs []string) error {
 	if err != nil {
 		return errors.Wrap(err, "getting analyzers")
 	}
+	if err := checkSBOMAnalyzers(image1Arg, image2Arg, diffTypes); err != nil {
+		return err
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(2)
@@ -175,6 +197,9 @@ func diffFile(image1, image2 *pkgutil.Image) error {
 
 func init() {
 	diffCmd.Flags().StringVarP(&filename, "filename", "f", "", "Set this flag to the path of a file in both containers to view the diff of the file. Must be used with --type=file flag.")
+	diffCmd.Flags().BoolVar(&util.OnlyUpgrades, "only-upgrades", false, "Set this flag to only report package version differences that are upgrades.")
+	diffCmd.Flags().BoolVar(&util.OnlyDowngrades, "only-downgrades", false, "Set this flag to only report package version differences that are downgrades.")
+	diffCmd.Flags().BoolVar(&differs.Changelogs, "changelog", false, "Set this flag to attach the changelog entries between both versions of upgraded apt and rpm packages, read from the second image.")
 	RootCmd.AddCommand(diffCmd)
 	addSharedFlags(diffCmd)
 	output.AddFlags(diffCmd)
@@ -130,6 +130,18 @@ func checkIfValidAnalyzer(_ []string) error {
 	return nil
 }
 
+func checkVulnDBFlag(_ []string) error {
+	if differs.VulnDB != "" {
+		return nil
+	}
+	for _, t := range types {
+		if t == "vuln" {
+			return errors.New("please set --vuln-db to an OSV vulnerability database directory with --type=vuln")
+		}
+	}
+	return nil
+}
+
 func includeLayers() bool {
 	for _, t := range types {
 		for _, a := range differs.LayerAnalyzers {
@@ -272,4 +284,7 @@ func addSharedFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&cacheDir, "cache-dir", "c", "", "cache directory base to create .container-diff (default is $HOME).")
 	cmd.Flags().StringVarP(&outputFile, "output", "w", "", "output file to write to (default writes to the screen).")
 	cmd.Flags().BoolVar(&forceWrite, "force", false, "force overwrite output file, if exists already.")
+	cmd.Flags().StringSliceVar(&differs.NodeRoots, "node-root", []string{"/"}, "Directory in the image to search for node_modules trees with the node analyzers. Set it repeatedly to search multiple directories.")
+	cmd.Flags().StringVar(&differs.VulnDB, "vuln-db", "", "Directory of the OSV vulnerability database, as JSON entries or per-ecosystem zip archives, the vuln analyzer matches packages against.")
+	cmd.Flags().IntVar(&differs.NodeDepth, "node-depth", 6, "Maximum directory depth below each node root at which node_modules trees are searched for.")
 }
@@ -50,7 +50,21 @@ func (a AptAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
 }
 
 func (a AptAnalyzer) getPackages(image pkgutil.Image) (map[string]util.PackageInfo, error) {
-	return readStatusFile(image.FSPath)
+	packages, err := readStatusFile(image.FSPath)
+	if err != nil {
+		return packages, err
+	}
+	setDpkgLicenses(image.FSPath, packages)
+	return packages, nil
+}
+
+// setDpkgLicenses sets the license of the packages from their copyright files,
+// which the dpkg status file does not record.
+func setDpkgLicenses(root string, packages map[string]util.PackageInfo) {
+	for name, info := range packages {
+		info.License = getDpkgLicense(root, name)
+		packages[name] = info
+	}
 }
 
 func readStatusFile(root string) (map[string]util.PackageInfo, error) {
@@ -82,7 +96,7 @@ func readStatusFile(root string) (map[string]util.PackageInfo, error) {
 }
 
 func parseLine(text string, currPackage string, packages map[string]util.PackageInfo) string {
-	line := strings.Split(text, ": ")
+	line := strings.SplitN(text, ": ", 2)
 	if len(line) == 2 {
 		key := line[0]
 		value := line[1]
@@ -120,6 +134,9 @@ func parseLine(text string, currPackage string, packages map[string]util.Package
 			currPackageInfo.Size = size * 1024
 			packages[currPackage] = currPackageInfo
 			return currPackage
+		case "Architecture", "Source", "Maintainer", "Homepage", "Depends", "Pre-Depends":
+			packages[currPackage] = setAptMetadata(packages[currPackage], key, value)
+			return currPackage
 		default:
 			return currPackage
 		}
@@ -127,6 +144,34 @@ func parseLine(text string, currPackage string, packages map[string]util.Package
 	return currPackage
 }
 
+// setAptMetadata records the value of a status file field describing the package.
+func setAptMetadata(info util.PackageInfo, key, value string) util.PackageInfo {
+	switch key {
+	case "Architecture":
+		info.Arch = value
+	case "Source":
+		// the source version is only given when it differs, e.g. "glibc (2.36-9)"
+		if fields := strings.Fields(value); len(fields) > 0 {
+			info.Source = fields[0]
+		}
+	case "Maintainer":
+		info.Maintainer = value
+	case "Homepage":
+		info.Homepage = value
+	case "Depends", "Pre-Depends":
+		for _, dep := range strings.Split(value, ",") {
+			info.Dependencies = append(info.Dependencies, strings.TrimSpace(dep))
+		}
+	}
+	return info
+}
+
+// compareAptVersions restores the '+' replaced by parseLine before comparing
+// the versions as dpkg does.
+func compareAptVersions(v1, v2 string) int {
+	return util.CompareDebianVersions(strings.Replace(v1, " ", "+", 1), strings.Replace(v2, " ", "+", 1))
+}
+
 type AptLayerAnalyzer struct {
 }
 
@@ -161,6 +206,8 @@ func (a AptLayerAnalyzer) getPackages(image pkgutil.Image) ([]map[string]util.Pa
 		if err != nil {
 			return packages, err
 		}
+		// copyright files are only written by the layer installing the package
+		setDpkgLicenses(image.FSPath, layerPackages)
 		packages = append(packages, layerPackages)
 	}
 
@@ -80,6 +80,38 @@ func TestParseLine(t *testing.T) {
 			expPackage:  "La-Croix",
 			expected:    map[string]util.PackageInfo{"La-Croix": {Version: "Lime", Size: 12288}},
 		},
+		{
+			descrip:     "Source line with source version",
+			line:        "Source: Sparkling-Water (1.0-2)",
+			packages:    map[string]util.PackageInfo{"La-Croix": {Version: "Lime"}},
+			currPackage: "La-Croix",
+			expPackage:  "La-Croix",
+			expected:    map[string]util.PackageInfo{"La-Croix": {Version: "Lime", Source: "Sparkling-Water"}},
+		},
+		{
+			descrip:     "Architecture line",
+			line:        "Architecture: amd64",
+			packages:    map[string]util.PackageInfo{},
+			currPackage: "La-Croix",
+			expPackage:  "La-Croix",
+			expected:    map[string]util.PackageInfo{"La-Croix": {Arch: "amd64"}},
+		},
+		{
+			descrip:     "Maintainer line",
+			line:        "Maintainer: Fizz Team <fizz@example.com>",
+			packages:    map[string]util.PackageInfo{},
+			currPackage: "La-Croix",
+			expPackage:  "La-Croix",
+			expected:    map[string]util.PackageInfo{"La-Croix": {Maintainer: "Fizz Team <fizz@example.com>"}},
+		},
+		{
+			descrip:     "Depends and Pre-Depends lines",
+			line:        "Depends: water (>= 1.0), bubbles | fizz",
+			packages:    map[string]util.PackageInfo{"La-Croix": {Dependencies: []string{"can"}}},
+			currPackage: "La-Croix",
+			expPackage:  "La-Croix",
+			expected:    map[string]util.PackageInfo{"La-Croix": {Dependencies: []string{"can", "water (>= 1.0)", "bubbles | fizz"}}},
+		},
 	}
 
 	for _, test := range testCases {
@@ -119,6 +151,12 @@ func TestGetAptPackages(t *testing.T) {
 				"pac2": {Version: "2.0"},
 				"pac3": {Version: "3.0"}},
 		},
+		{
+			descrip: "license from copyright file",
+			path:    "testDirs/aptLicense1",
+			expected: map[string]util.PackageInfo{
+				"pac1": {Version: "1.0", License: "MIT"}},
+		},
 	}
 	for _, test := range testCases {
 		d := AptAnalyzer{}
@@ -135,3 +173,23 @@ func TestGetAptPackages(t *testing.T) {
 		}
 	}
 }
+
+func TestAptLicenseChange(t *testing.T) {
+	image1 := pkgutil.Image{FSPath: "testDirs/aptLicense1", Source: "image1"}
+	image2 := pkgutil.Image{FSPath: "testDirs/aptLicense2", Source: "image2"}
+	result, err := singleVersionDiff(image1, image2, AptAnalyzer{})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	diff := result.Diff.(util.PackageDiff)
+	if len(diff.InfoDiff) != 1 {
+		t.Fatalf("Expected one changed package but got: %v", diff.InfoDiff)
+	}
+	info := diff.InfoDiff[0]
+	if info.Package != "pac1" || info.Info1.License != "MIT" || info.Info2.License != "Apache-2.0" {
+		t.Errorf("Expected the license change of pac1 but got: %v", info)
+	}
+	if info.Change != util.MetadataChange {
+		t.Errorf("Expected change %s but got: %s", util.MetadataChange, info.Change)
+	}
+}
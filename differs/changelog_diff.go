@@ -0,0 +1,173 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/sirupsen/logrus"
+)
+
+// Changelogs attaches the changelog entries between both versions of upgraded
+// packages, read from the second image, to the version differences.
+var Changelogs bool
+
+// dpkgChangelogs are the changelogs in the documentation directory of a Debian
+// package, the latter being used by native packages.
+var dpkgChangelogs = []string{"changelog.Debian.gz", "changelog.gz"}
+
+// rpmChangelogCmd lists the changelog entries of the queried packages. Entries
+// are separated by a record separator and their fields by a unit separator, as
+// the entry text spans several lines.
+var rpmChangelogCmd = []string{
+	"rpm", "--nodigest", "--nosignature",
+	"-q", "--qf", "[%{=NAME}\x1f%{CHANGELOGTIME:day}\x1f%{CHANGELOGNAME}\x1f%{CHANGELOGTEXT}\x1e]",
+}
+
+// changelogReader is implemented by package analyzers able to read the
+// changelogs of the packages of an image.
+type changelogReader interface {
+	// getChangelogs returns the changelog entries of packages newer than
+	// their version in since, newest first.
+	getChangelogs(image pkgutil.Image, since map[string]util.PackageInfo) (map[string][]util.ChangelogEntry, error)
+}
+
+// addChangelogs attaches to every upgraded package of diff the changelog
+// entries of image2 for versions after the one in the first image, up to the
+// one in the second.
+func addChangelogs(diff *util.PackageDiff, image2 pkgutil.Image, reader changelogReader, compare util.VersionComparator) error {
+	since := make(map[string]util.PackageInfo)
+	for _, info := range diff.InfoDiff {
+		if info.Change == util.VersionUpgrade {
+			since[info.Package] = info.Info1
+		}
+	}
+	if len(since) == 0 {
+		return nil
+	}
+	changelogs, err := reader.getChangelogs(image2, since)
+	if err != nil {
+		return err
+	}
+	for i, info := range diff.InfoDiff {
+		for _, entry := range changelogs[info.Package] {
+			if compare(entry.Version, info.Info1.Version) > 0 && compare(entry.Version, info.Info2.Version) <= 0 {
+				diff.InfoDiff[i].Changelog = append(diff.InfoDiff[i].Changelog, entry)
+			}
+		}
+	}
+	return nil
+}
+
+func (a AptAnalyzer) getChangelogs(image pkgutil.Image, since map[string]util.PackageInfo) (map[string][]util.ChangelogEntry, error) {
+	changelogs := make(map[string][]util.ChangelogEntry)
+	for pkg, info := range since {
+		entries, err := readDpkgChangelog(image.FSPath, pkg, info)
+		if err != nil {
+			logrus.Warnf("Error reading changelog of %s: %s", pkg, err)
+			continue
+		}
+		changelogs[pkg] = entries
+	}
+	return changelogs, nil
+}
+
+// readDpkgChangelog reads the changelog entries of a Debian package newer than
+// the version of since, from the documentation directory of the package or of
+// its source package.
+func readDpkgChangelog(root, pkg string, since util.PackageInfo) ([]util.ChangelogEntry, error) {
+	dirs := []string{pkg}
+	if since.Source != "" && since.Source != pkg {
+		dirs = append(dirs, since.Source)
+	}
+	for _, dir := range dirs {
+		docDir := resolveInRoot(root, filepath.Join(dpkgDocDir, dir))
+		for _, name := range dpkgChangelogs {
+			file, err := os.Open(filepath.Join(docDir, name))
+			if err != nil {
+				continue
+			}
+			defer file.Close()
+			reader, err := gzip.NewReader(file)
+			if err != nil {
+				return nil, err
+			}
+			return util.ParseDebianChangelog(reader, func(version string) bool {
+				return compareAptVersions(version, since.Version) <= 0
+			})
+		}
+	}
+	return nil, nil
+}
+
+func (a RPMAnalyzer) getChangelogs(image pkgutil.Image, since map[string]util.PackageInfo) (map[string][]util.ChangelogEntry, error) {
+	query := append([]string{}, rpmChangelogCmd...)
+	for pkg := range since {
+		query = append(query, pkg)
+	}
+	output, err := rpmQuery(image, query)
+	if err != nil {
+		return nil, err
+	}
+	return parseRPMChangelogs(strings.Join(output, "\n")), nil
+}
+
+// parseRPMChangelogs parses the output of rpmChangelogCmd into the changelog
+// entries of each package.
+func parseRPMChangelogs(output string) map[string][]util.ChangelogEntry {
+	changelogs := make(map[string][]util.ChangelogEntry)
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(strings.TrimPrefix(record, "\n"), "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		pkg, date, name, text := fields[0], fields[1], fields[2], fields[3]
+		author, version := splitRPMChangelogName(name)
+		if version == "" {
+			continue
+		}
+		changelogs[pkg] = append(changelogs[pkg], util.NewChangelogEntry(version, author, date, text))
+	}
+	return changelogs
+}
+
+// splitRPMChangelogName splits the name of an rpm changelog entry, e.g.
+// "Jane Doe <jane@example.com> - 3.0.7-25", into its author and version. The
+// epoch is left out of the version as it is recorded apart from the version of
+// installed packages.
+func splitRPMChangelogName(name string) (author, version string) {
+	author, version = name, ""
+	if i := strings.LastIndex(name, ">"); i >= 0 {
+		author, version = name[:i+1], name[i+1:]
+	} else if i := strings.LastIndex(name, " - "); i >= 0 {
+		author, version = name[:i], name[i+3:]
+	}
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(version), "- "))
+	if len(fields) == 0 {
+		return strings.TrimSpace(author), ""
+	}
+	version = fields[0]
+	if i := strings.Index(version, ":"); i >= 0 {
+		version = version[i+1:]
+	}
+	return strings.TrimSpace(author), version
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+func writeGzipFile(t *testing.T, file, content string) {
+	os.MkdirAll(filepath.Dir(file), 0755)
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatalf("Unable to create %s: %s", file, err)
+	}
+	defer f.Close()
+	w := gzip.NewWriter(f)
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatalf("Unable to write %s: %s", file, err)
+	}
+	w.Close()
+}
+
+func TestAddAptChangelogs(t *testing.T) {
+	root, err := ioutil.TempDir("", "changelog")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	writeGzipFile(t, filepath.Join(root, "usr/share/doc/openssl/changelog.Debian.gz"), `openssl (3.0.2-0ubuntu1.12) jammy-security; urgency=medium
+
+  * SECURITY UPDATE: Excessive time spent checking DH keys
+    - CVE-2023-3446
+
+ -- Marc Deslauriers <marc.deslauriers@ubuntu.com>  Tue, 01 Aug 2023 12:21:09 -0400
+
+openssl (3.0.2-0ubuntu1.11) jammy-security; urgency=medium
+
+  * SECURITY UPDATE: AES-SIV ignores empty associated data
+    - CVE-2023-2975
+
+ -- Marc Deslauriers <marc.deslauriers@ubuntu.com>  Thu, 20 Jul 2023 07:47:47 -0400
+
+openssl (3.0.2-0ubuntu1.10) jammy-security; urgency=medium
+
+  * SECURITY UPDATE: Possible DoS translating ASN.1 object identifiers
+    - CVE-2023-2650
+
+ -- Marc Deslauriers <marc.deslauriers@ubuntu.com>  Tue, 30 May 2023 13:19:03 -0400
+`)
+	// binary packages may only ship the changelog of their source package
+	writeGzipFile(t, filepath.Join(root, "usr/share/doc/glibc/changelog.Debian.gz"), `glibc (2.35-0ubuntu3.4) jammy-security; urgency=medium
+
+  * SECURITY UPDATE: buffer overflow in ld.so
+    - CVE-2023-4911
+
+ -- Marc Deslauriers <marc.deslauriers@ubuntu.com>  Mon, 25 Sep 2023 08:06:12 -0400
+`)
+
+	diff := util.PackageDiff{InfoDiff: []util.Info{
+		{Package: "libc6", Info1: util.PackageInfo{Version: "2.35-0ubuntu3.1", Source: "glibc"}, Info2: util.PackageInfo{Version: "2.35-0ubuntu3.4", Source: "glibc"}, Change: util.VersionUpgrade},
+		{Package: "openssl", Info1: util.PackageInfo{Version: "3.0.2-0ubuntu1.10"}, Info2: util.PackageInfo{Version: "3.0.2-0ubuntu1.11"}, Change: util.VersionUpgrade},
+		{Package: "zlib1g", Info1: util.PackageInfo{Version: "1:1.2.11.dfsg-2ubuntu9.2"}, Info2: util.PackageInfo{Version: "1:1.2.11.dfsg-2ubuntu9"}, Change: util.VersionDowngrade},
+	}}
+	if err := addChangelogs(&diff, pkgutil.Image{FSPath: root}, AptAnalyzer{}, compareAptVersions); err != nil {
+		t.Fatalf("Error adding changelogs: %s", err)
+	}
+	expected := [][]util.ChangelogEntry{
+		{{
+			Version: "2.35-0ubuntu3.4",
+			Author:  "Marc Deslauriers <marc.deslauriers@ubuntu.com>",
+			Date:    "Mon, 25 Sep 2023 08:06:12 -0400",
+			Text:    "* SECURITY UPDATE: buffer overflow in ld.so\n  - CVE-2023-4911",
+			CVEs:    []string{"CVE-2023-4911"},
+		}},
+		{{
+			Version: "3.0.2-0ubuntu1.11",
+			Author:  "Marc Deslauriers <marc.deslauriers@ubuntu.com>",
+			Date:    "Thu, 20 Jul 2023 07:47:47 -0400",
+			Text:    "* SECURITY UPDATE: AES-SIV ignores empty associated data\n  - CVE-2023-2975",
+			CVEs:    []string{"CVE-2023-2975"},
+		}},
+		nil,
+	}
+	for i, info := range diff.InfoDiff {
+		if !reflect.DeepEqual(info.Changelog, expected[i]) {
+			t.Errorf("%s: expected changelog %v but got %v", info.Package, expected[i], info.Changelog)
+		}
+	}
+}
+
+func TestParseRPMChangelogs(t *testing.T) {
+	output := "openssl-libs\x1fMon Jan 15 2024\x1fDmitry Belyavskiy <dbelyavs@redhat.com> - 1:3.0.7-25\x1f- Fix possible DoS in DH key checks\n  Resolves: CVE-2023-3446 CVE-2023-3817\x1e" +
+		"openssl-libs\x1fWed Jun 14 2023\x1fDmitry Belyavskiy <dbelyavs@redhat.com> - 1:3.0.7-24\x1f- Fix AES-SIV\x1e" +
+		"bash\x1fTue Feb 28 2023\x1fSiteshwar Vashisht <svashisht@redhat.com> 5.1.8-6\x1f- Fix crash\x1e" +
+		"bash\x1fMon Aug 09 2021\x1fMohan Boddu <mboddu@redhat.com>\x1f- Rebuilt for IMA sigs\x1e\n"
+	expected := map[string][]util.ChangelogEntry{
+		"openssl-libs": {
+			{
+				Version: "3.0.7-25",
+				Author:  "Dmitry Belyavskiy <dbelyavs@redhat.com>",
+				Date:    "Mon Jan 15 2024",
+				Text:    "- Fix possible DoS in DH key checks\n  Resolves: CVE-2023-3446 CVE-2023-3817",
+				CVEs:    []string{"CVE-2023-3446", "CVE-2023-3817"},
+			},
+			{Version: "3.0.7-24", Author: "Dmitry Belyavskiy <dbelyavs@redhat.com>", Date: "Wed Jun 14 2023", Text: "- Fix AES-SIV"},
+		},
+		"bash": {
+			{Version: "5.1.8-6", Author: "Siteshwar Vashisht <svashisht@redhat.com>", Date: "Tue Feb 28 2023", Text: "- Fix crash"},
+		},
+	}
+	if changelogs := parseRPMChangelogs(output); !reflect.DeepEqual(changelogs, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, changelogs)
+	}
+}
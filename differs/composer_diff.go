@@ -0,0 +1,115 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/sirupsen/logrus"
+)
+
+type ComposerAnalyzer struct {
+}
+
+func (a ComposerAnalyzer) Name() string {
+	return "ComposerAnalyzer"
+}
+
+// ComposerDiff compares the PHP packages installed by Composer in each project of two images.
+func (a ComposerAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := multiVersionDiff(image1, image2, a)
+	return diff, err
+}
+
+func (a ComposerAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := multiVersionAnalysis(image, a)
+	return analysis, err
+}
+
+// getPackages returns the Composer packages of the image keyed by name and project directory.
+func (a ComposerAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]util.PackageInfo, error) {
+	root := filepath.Clean(image.FSPath)
+	packages := make(map[string]map[string]util.PackageInfo)
+	if _, err := os.Stat(root); err != nil {
+		// path provided invalid
+		return packages, err
+	}
+
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			logrus.Debugf("Unable to read %s: %s", file, err)
+			return nil
+		}
+		if info.IsDir() || info.Name() != "installed.json" || filepath.Base(filepath.Dir(file)) != "composer" {
+			return nil
+		}
+		vendorDir := filepath.Dir(filepath.Dir(file))
+		if filepath.Base(vendorDir) != "vendor" {
+			return nil
+		}
+		installed, err := readComposerInstalled(file)
+		if err != nil {
+			logrus.Warningf("Error reading Composer packages at %s: %s", file, err)
+			return nil
+		}
+		project := "/" + strings.TrimPrefix(strings.TrimPrefix(filepath.Dir(vendorDir), root), "/")
+		for _, pkg := range installed {
+			size := int64(-1)
+			installPath := filepath.Join(vendorDir, pkg.Name)
+			if pkg.InstallPath != "" {
+				installPath = filepath.Join(filepath.Dir(file), pkg.InstallPath)
+			}
+			if _, err := os.Stat(installPath); err == nil {
+				size = pkgutil.GetSize(installPath)
+			}
+			addToMap(packages, pkg.Name, project, util.PackageInfo{Version: pkg.Version, Size: size, Project: project})
+		}
+		return nil
+	})
+	return packages, err
+}
+
+type composerPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// InstallPath is relative to the vendor/composer directory; only Composer 2 records it
+	InstallPath string `json:"install-path"`
+}
+
+// readComposerInstalled reads the packages of an installed.json, written either by
+// Composer 1 as a list of packages or by Composer 2 as an object holding them.
+func readComposerInstalled(path string) ([]composerPackage, error) {
+	jsonBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var installed struct {
+		Packages []composerPackage `json:"packages"`
+	}
+	if err := json.Unmarshal(jsonBytes, &installed); err == nil {
+		return installed.Packages, nil
+	}
+	var packages []composerPackage
+	err = json.Unmarshal(jsonBytes, &packages)
+	return packages, err
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+func TestGetComposerPackages(t *testing.T) {
+	root, err := ioutil.TempDir("", "composer-analyzer")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		// Composer 2
+		"var/www/html/vendor/composer/installed.json": `{"packages": [
+			{"name": "monolog/monolog", "version": "2.9.1", "install-path": "../monolog/monolog"},
+			{"name": "psr/log", "version": "3.0.0", "install-path": "../psr/log"}
+		], "dev": true}`,
+		"var/www/html/vendor/monolog/monolog/src/Logger.php": "<?php",
+		// Composer 1
+		"srv/api/vendor/composer/installed.json":     `[{"name": "psr/log", "version": "1.1.4"}]`,
+		"srv/api/vendor/psr/log/LoggerInterface.php": "<?php // log",
+		"srv/api/composer/installed.json":            `[{"name": "not/vendored", "version": "1.0.0"}]`,
+	}
+	for name, content := range files {
+		file := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write %s: %s", file, err)
+		}
+	}
+
+	expected := map[string]map[string]util.PackageInfo{
+		"monolog/monolog": {
+			"/var/www/html": {Version: "2.9.1", Size: int64(len(files["var/www/html/vendor/monolog/monolog/src/Logger.php"])), Project: "/var/www/html"},
+		},
+		"psr/log": {
+			"/var/www/html": {Version: "3.0.0", Size: -1, Project: "/var/www/html"},
+			"/srv/api":      {Version: "1.1.4", Size: int64(len(files["srv/api/vendor/psr/log/LoggerInterface.php"])), Project: "/srv/api"},
+		},
+	}
+
+	packages, err := ComposerAnalyzer{}.getPackages(pkgutil.Image{FSPath: root})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+
+	if _, err := (ComposerAnalyzer{}).getPackages(pkgutil.Image{FSPath: "testDirs/notThere"}); err == nil {
+		t.Errorf("Expected error for missing path")
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/sirupsen/logrus"
+)
+
+// Conda base environment location; named environments live in its envs directory
+const condaBaseEnv string = "/opt/conda"
+
+type CondaAnalyzer struct {
+}
+
+func (a CondaAnalyzer) Name() string {
+	return "CondaAnalyzer"
+}
+
+// CondaDiff compares the packages installed by conda in each environment of two images.
+func (a CondaAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := multiVersionDiff(image1, image2, a)
+	return diff, err
+}
+
+func (a CondaAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := multiVersionAnalysis(image, a)
+	return analysis, err
+}
+
+// getPackages returns the conda packages of the image keyed by name and environment directory.
+func (a CondaAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]util.PackageInfo, error) {
+	path := image.FSPath
+	packages := make(map[string]map[string]util.PackageInfo)
+	if _, err := os.Stat(path); err != nil {
+		// path provided invalid
+		return packages, err
+	}
+
+	envs := []string{condaBaseEnv}
+	namedEnvs, err := ioutil.ReadDir(filepath.Join(path, condaBaseEnv, "envs"))
+	if err == nil {
+		for _, env := range namedEnvs {
+			if env.IsDir() {
+				envs = append(envs, filepath.Join(condaBaseEnv, "envs", env.Name()))
+			}
+		}
+	}
+
+	for _, env := range envs {
+		metaDir := filepath.Join(path, env, "conda-meta")
+		contents, err := ioutil.ReadDir(metaDir)
+		if err != nil {
+			// not a conda environment
+			continue
+		}
+		for _, c := range contents {
+			if c.IsDir() || !strings.HasSuffix(c.Name(), ".json") {
+				continue
+			}
+			meta, err := readCondaMeta(filepath.Join(metaDir, c.Name()))
+			if err != nil {
+				logrus.Warningf("Error reading conda metadata at %s: %s", filepath.Join(metaDir, c.Name()), err)
+				continue
+			}
+			currInfo := util.PackageInfo{
+				Version:     meta.Version,
+				Size:        meta.installedSize(filepath.Join(path, env)),
+				Build:       meta.Build,
+				Repository:  getCondaChannel(meta.Channel),
+				Environment: env,
+			}
+			addToMap(packages, meta.Name, env, currInfo)
+		}
+	}
+	return packages, nil
+}
+
+// condaMeta is the metadata conda records for each package linked into an environment.
+type condaMeta struct {
+	Name    string   `json:"name"`
+	Version string   `json:"version"`
+	Build   string   `json:"build"`
+	Channel string   `json:"channel"`
+	Size    int64    `json:"size"`
+	Files   []string `json:"files"`
+}
+
+func readCondaMeta(path string) (condaMeta, error) {
+	var meta condaMeta
+	jsonBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return meta, err
+	}
+	err = json.Unmarshal(jsonBytes, &meta)
+	return meta, err
+}
+
+// installedSize sums the size of the files the package linked into the environment at
+// envPath, falling back to the size of the package archive when none are listed.
+func (m condaMeta) installedSize(envPath string) int64 {
+	if len(m.Files) == 0 {
+		return m.Size
+	}
+	var size int64
+	for _, file := range m.Files {
+		if info, err := os.Lstat(filepath.Join(envPath, file)); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// getCondaChannel returns the channel name of a channel URL such as
+// https://conda.anaconda.org/conda-forge/linux-64, dropping the platform subdirectory.
+func getCondaChannel(channel string) string {
+	channel = strings.TrimSuffix(channel, "/")
+	if i := strings.LastIndex(channel, "/"); i >= 0 && isCondaSubdir(channel[i+1:]) {
+		channel = channel[:i]
+	}
+	return strings.TrimPrefix(channel, "https://conda.anaconda.org/")
+}
+
+func isCondaSubdir(subdir string) bool {
+	if subdir == "noarch" {
+		return true
+	}
+	for _, platform := range []string{"linux-", "osx-", "win-"} {
+		if strings.HasPrefix(subdir, platform) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+func TestGetCondaPackages(t *testing.T) {
+	testCases := []struct {
+		descrip  string
+		path     string
+		expected map[string]map[string]util.PackageInfo
+		err      bool
+	}{
+		{
+			descrip:  "no directory",
+			path:     "testDirs/notThere",
+			expected: map[string]map[string]util.PackageInfo{},
+			err:      true,
+		},
+		{
+			descrip:  "no conda installation",
+			path:     "testDirs/noPackages",
+			expected: map[string]map[string]util.PackageInfo{},
+		},
+		{
+			descrip: "base and named environments",
+			path:    "testDirs/condaTests",
+			expected: map[string]map[string]util.PackageInfo{
+				"numpy": {
+					"/opt/conda": {Version: "1.21.2", Size: 6, Build: "py39h20f2e39_0",
+						Repository: "https://repo.anaconda.com/pkgs/main", Environment: "/opt/conda"},
+					"/opt/conda/envs/ml": {Version: "1.22.0", Size: 7, Build: "py310h454958d_0",
+						Repository: "conda-forge", Environment: "/opt/conda/envs/ml"},
+				},
+				"ca-certificates": {
+					"/opt/conda/envs/ml": {Version: "2021.10.8", Size: 139, Build: "ha878542_0",
+						Repository: "conda-forge", Environment: "/opt/conda/envs/ml"},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		image := pkgutil.Image{FSPath: test.path}
+		packages, err := CondaAnalyzer{}.getPackages(image)
+		if err != nil && !test.err {
+			t.Errorf("%s: Got unexpected error: %s", test.descrip, err)
+		}
+		if err == nil && test.err {
+			t.Errorf("%s: Expected error but got none.", test.descrip)
+		}
+		if !reflect.DeepEqual(packages, test.expected) {
+			t.Errorf("%s: Expected: %v but got: %v", test.descrip, test.expected, packages)
+		}
+	}
+}
@@ -0,0 +1,519 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+// apt extended states location, marking the packages installed automatically
+const aptExtendedStatesFile string = "/var/lib/apt/extended_states"
+
+// apk world location, listing the packages installed explicitly
+const apkWorldFile string = "/etc/apk/world"
+
+// RPM command to list the requirements and provided capabilities of every installed package
+var rpmDependenciesCmd = []string{
+	"rpm", "--nodigest", "--nosignature",
+	"-qa", "--qf", "%{NAME}\t[%{REQUIRENAME},]\t[%{PROVIDENAME},]\n",
+}
+
+// dependencyGraph is the dependency graph of the packages installed by one
+// package manager.
+type dependencyGraph struct {
+	manager string
+	// dependencies maps every installed package to the installed packages it depends on
+	dependencies map[string][]string
+	dependents   map[string][]string
+	// manual are the packages installed explicitly, nil if the package manager
+	// does not record it
+	manual map[string]bool
+}
+
+// newDependencyGraph resolves the requirements of packages, each a list of
+// alternative packages or capabilities, to the installed packages satisfying
+// them. Capabilities are resolved to the packages providing them. manual lists
+// the packages or capabilities installed explicitly, or is nil if unknown.
+func newDependencyGraph(manager string, requires map[string][][]string, provides map[string][]string, manual []string) dependencyGraph {
+	graph := dependencyGraph{
+		manager:      manager,
+		dependencies: make(map[string][]string),
+		dependents:   make(map[string][]string),
+	}
+	resolve := func(alternatives []string) string {
+		for _, alt := range alternatives {
+			if _, ok := requires[alt]; ok {
+				return alt
+			}
+		}
+		for _, alt := range alternatives {
+			if providers := provides[alt]; len(providers) > 0 {
+				return providers[0]
+			}
+		}
+		return ""
+	}
+	for pkg, reqs := range requires {
+		graph.dependencies[pkg] = []string{}
+		seen := map[string]bool{pkg: true}
+		for _, alternatives := range reqs {
+			if dep := resolve(alternatives); dep != "" && !seen[dep] {
+				seen[dep] = true
+				graph.dependencies[pkg] = append(graph.dependencies[pkg], dep)
+				graph.dependents[dep] = append(graph.dependents[dep], pkg)
+			}
+		}
+	}
+	for _, pkgs := range graph.dependencies {
+		sort.Strings(pkgs)
+	}
+	for _, pkgs := range graph.dependents {
+		sort.Strings(pkgs)
+	}
+	if manual != nil {
+		graph.manual = make(map[string]bool)
+		for _, name := range manual {
+			if pkg := resolve([]string{name}); pkg != "" {
+				graph.manual[pkg] = true
+			}
+		}
+	}
+	return graph
+}
+
+func (g dependencyGraph) isTopLevel(pkg string) bool {
+	if g.manual != nil {
+		return g.manual[pkg]
+	}
+	return len(g.dependents[pkg]) == 0
+}
+
+// orphans returns the packages which no top-level package depends on, directly
+// or not, if the package manager records which packages were installed explicitly.
+func (g dependencyGraph) orphans() map[string]bool {
+	orphans := make(map[string]bool)
+	if g.manual == nil {
+		return orphans
+	}
+	for pkg := range g.dependencies {
+		orphans[pkg] = true
+	}
+	var queue []string
+	for pkg := range g.manual {
+		queue = append(queue, pkg)
+	}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if !orphans[pkg] {
+			continue
+		}
+		delete(orphans, pkg)
+		queue = append(queue, g.dependencies[pkg]...)
+	}
+	return orphans
+}
+
+// chain returns the shortest chain of dependencies from a top-level package
+// down to pkg, or nil if no top-level package depends on pkg.
+func (g dependencyGraph) chain(pkg string) []string {
+	parents := map[string]string{pkg: ""}
+	queue := []string{pkg}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if g.isTopLevel(current) {
+			var chain []string
+			for ; current != ""; current = parents[current] {
+				chain = append(chain, current)
+			}
+			return chain
+		}
+		for _, dependent := range g.dependents[current] {
+			if _, ok := parents[dependent]; !ok {
+				parents[dependent] = current
+				queue = append(queue, dependent)
+			}
+		}
+	}
+	return nil
+}
+
+func (g dependencyGraph) analysis() []util.DependencyPackage {
+	orphans := g.orphans()
+	var packages []util.DependencyPackage
+	for pkg, deps := range g.dependencies {
+		packages = append(packages, util.DependencyPackage{
+			Package:      pkg,
+			Manager:      g.manager,
+			TopLevel:     g.isTopLevel(pkg),
+			Orphan:       orphans[pkg],
+			Dependencies: deps,
+			Dependents:   g.dependents[pkg],
+		})
+	}
+	return packages
+}
+
+type DependencyAnalyzer struct {
+}
+
+func (a DependencyAnalyzer) Name() string {
+	return "DependencyAnalyzer"
+}
+
+// Diff reports the packages found in only one image, with the chain of
+// dependencies that pulled them in.
+func (a DependencyAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	graphs1, err := getDependencyGraphs(image1)
+	if err != nil {
+		return &util.DependencyDiffResult{}, err
+	}
+	graphs2, err := getDependencyGraphs(image2)
+	if err != nil {
+		return &util.DependencyDiffResult{}, err
+	}
+	return &util.DependencyDiffResult{
+		Image1:   image1.Source,
+		Image2:   image2.Source,
+		DiffType: "Dependency",
+		Diff: util.DependencyDiff{
+			Packages1: uniqueDependencyChains(graphs1, graphs2),
+			Packages2: uniqueDependencyChains(graphs2, graphs1),
+		},
+	}, nil
+}
+
+func (a DependencyAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	graphs, err := getDependencyGraphs(image)
+	if err != nil {
+		return &util.DependencyAnalyzeResult{}, err
+	}
+	analysis := []util.DependencyPackage{}
+	for _, graph := range graphs {
+		analysis = append(analysis, graph.analysis()...)
+	}
+	sort.Slice(analysis, func(i, j int) bool {
+		if analysis[i].Manager != analysis[j].Manager {
+			return analysis[i].Manager < analysis[j].Manager
+		}
+		return analysis[i].Package < analysis[j].Package
+	})
+	return &util.DependencyAnalyzeResult{
+		Image:       image.Source,
+		AnalyzeType: "Dependency",
+		Analysis:    analysis,
+	}, nil
+}
+
+// uniqueDependencyChains returns the packages of graphs not installed by the
+// same package manager in others, with the chain that pulled them in.
+func uniqueDependencyChains(graphs, others map[string]dependencyGraph) []util.DependencyChain {
+	chains := []util.DependencyChain{}
+	for manager, graph := range graphs {
+		for pkg := range graph.dependencies {
+			if _, ok := others[manager].dependencies[pkg]; ok {
+				continue
+			}
+			chains = append(chains, util.DependencyChain{Package: pkg, Manager: manager, Chain: graph.chain(pkg)})
+		}
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		if chains[i].Manager != chains[j].Manager {
+			return chains[i].Manager < chains[j].Manager
+		}
+		return chains[i].Package < chains[j].Package
+	})
+	return chains
+}
+
+// getDependencyGraphs builds the dependency graphs of the dpkg, rpm and apk
+// packages of the image, keyed by package manager.
+func getDependencyGraphs(image pkgutil.Image) (map[string]dependencyGraph, error) {
+	graphs := make(map[string]dependencyGraph)
+	root := image.FSPath
+	if _, err := os.Stat(root); err != nil {
+		// invalid image directory path
+		return graphs, err
+	}
+
+	aptGraph, err := getAptDependencyGraph(root)
+	if err != nil {
+		return graphs, err
+	}
+	if hasRPMBinary(root) {
+		output, err := rpmQuery(image, rpmDependenciesCmd)
+		if err != nil {
+			return graphs, err
+		}
+		requires, provides := parseRPMDependencies(output)
+		if hasFileRequirements(requires) {
+			files, err := rpmQuery(image, rpmFilesCmd)
+			if err != nil {
+				return graphs, err
+			}
+			for _, file := range parseRPMFileList(files) {
+				provides[file.path] = append(provides[file.path], file.pkg)
+			}
+		}
+		graphs["rpm"] = newDependencyGraph("rpm", requires, provides, nil)
+	}
+	apkGraph, err := getApkDependencyGraph(root)
+	if err != nil {
+		return graphs, err
+	}
+
+	graphs["apt"], graphs["apk"] = aptGraph, apkGraph
+	for manager, graph := range graphs {
+		if len(graph.dependencies) == 0 {
+			delete(graphs, manager)
+		}
+	}
+	return graphs, nil
+}
+
+// getAptDependencyGraph builds the graph of the Depends and Pre-Depends of the
+// dpkg packages. Packages not marked as automatically installed in the apt
+// extended states are top-level, if apt recorded any.
+func getAptDependencyGraph(root string) (dependencyGraph, error) {
+	packages, err := readStatusFile(root)
+	if err != nil {
+		return dependencyGraph{}, err
+	}
+	requires := make(map[string][][]string)
+	for pkg, info := range packages {
+		requires[pkg] = [][]string{}
+		for _, dep := range info.Dependencies {
+			var alternatives []string
+			for _, alt := range strings.Split(dep, "|") {
+				alternatives = append(alternatives, debianDependencyName(alt))
+			}
+			requires[pkg] = append(requires[pkg], alternatives)
+		}
+	}
+	provides, err := readDpkgProvides(root)
+	if err != nil {
+		return dependencyGraph{}, err
+	}
+
+	var manual []string
+	auto, err := readAptAutoInstalled(root)
+	if err != nil {
+		return dependencyGraph{}, err
+	}
+	if auto != nil {
+		manual = []string{}
+		for pkg := range packages {
+			if !auto[pkg] {
+				manual = append(manual, pkg)
+			}
+		}
+	}
+	return newDependencyGraph("apt", requires, provides, manual), nil
+}
+
+// debianDependencyName returns the package name of a dependency, e.g. "libc6"
+// from "libc6:any (>= 2.34)".
+func debianDependencyName(dep string) string {
+	fields := strings.Fields(dep)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.SplitN(strings.SplitN(fields[0], "(", 2)[0], ":", 2)[0]
+}
+
+// readDpkgProvides returns the dpkg packages providing each virtual package.
+func readDpkgProvides(root string) (map[string][]string, error) {
+	provides := make(map[string][]string)
+	file, err := os.Open(filepath.Join(root, dpkgStatusFile))
+	if err != nil {
+		// status file does not exist in this layer
+		return provides, nil
+	}
+	defer file.Close()
+
+	var pkg string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.SplitN(scanner.Text(), ": ", 2)
+		if len(line) != 2 {
+			continue
+		}
+		switch line[0] {
+		case "Package":
+			pkg = line[1]
+		case "Provides":
+			for _, virtual := range strings.Split(line[1], ",") {
+				if name := debianDependencyName(virtual); name != "" {
+					provides[name] = append(provides[name], pkg)
+				}
+			}
+		}
+	}
+	for _, pkgs := range provides {
+		sort.Strings(pkgs)
+	}
+	return provides, scanner.Err()
+}
+
+// readAptAutoInstalled returns the packages marked as automatically installed
+// in the apt extended states, or nil if apt recorded none.
+func readAptAutoInstalled(root string) (map[string]bool, error) {
+	file, err := os.Open(filepath.Join(root, aptExtendedStatesFile))
+	if err != nil {
+		return nil, nil
+	}
+	defer file.Close()
+
+	auto := make(map[string]bool)
+	var pkg string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.SplitN(scanner.Text(), ": ", 2)
+		if len(line) != 2 {
+			continue
+		}
+		switch line[0] {
+		case "Package":
+			pkg = line[1]
+		case "Auto-Installed":
+			if strings.TrimSpace(line[1]) == "1" {
+				auto[pkg] = true
+			}
+		}
+	}
+	return auto, scanner.Err()
+}
+
+// parseRPMDependencies parses the output of rpmDependenciesCmd into the
+// requirements of every package and the packages providing each capability.
+func parseRPMDependencies(rpmOutput []string) (map[string][][]string, map[string][]string) {
+	requires := make(map[string][][]string)
+	provides := make(map[string][]string)
+	for _, line := range rpmOutput {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		pkg := fields[0]
+		requires[pkg] = [][]string{}
+		for _, req := range getRPMRequires(fields[1]) {
+			requires[pkg] = append(requires[pkg], []string{req})
+		}
+		for _, capability := range getRPMRequires(fields[2]) {
+			provides[capability] = append(provides[capability], pkg)
+		}
+	}
+	for _, pkgs := range provides {
+		sort.Strings(pkgs)
+	}
+	return requires, provides
+}
+
+// hasFileRequirements checks whether any package requires a file, which is
+// provided by the package owning it.
+func hasFileRequirements(requires map[string][][]string) bool {
+	for _, reqs := range requires {
+		for _, alternatives := range reqs {
+			if strings.HasPrefix(alternatives[0], "/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getApkDependencyGraph builds the graph of the D: dependencies of the apk
+// packages, resolved through their p: provides. Packages of the apk world are
+// top-level.
+func getApkDependencyGraph(root string) (dependencyGraph, error) {
+	requires := make(map[string][][]string)
+	provides := make(map[string][]string)
+	for _, db := range apkInstalledDBs {
+		file, err := os.Open(filepath.Join(root, db))
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+
+		var pkg string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if len(line) < 2 || line[1] != ':' {
+				// blank line between packages
+				pkg = ""
+				continue
+			}
+			switch value := line[2:]; line[0] {
+			case 'P':
+				pkg = value
+				requires[pkg] = [][]string{}
+			case 'D':
+				for _, dep := range strings.Fields(value) {
+					// conflicts are prefixed with '!'
+					if !strings.HasPrefix(dep, "!") {
+						requires[pkg] = append(requires[pkg], []string{apkDependencyName(dep)})
+					}
+				}
+			case 'p':
+				for _, capability := range strings.Fields(value) {
+					name := apkDependencyName(capability)
+					provides[name] = append(provides[name], pkg)
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return dependencyGraph{}, err
+		}
+	}
+	for _, pkgs := range provides {
+		sort.Strings(pkgs)
+	}
+
+	var manual []string
+	if world, err := os.Open(filepath.Join(root, apkWorldFile)); err == nil {
+		defer world.Close()
+		manual = []string{}
+		scanner := bufio.NewScanner(world)
+		scanner.Split(bufio.ScanWords)
+		for scanner.Scan() {
+			manual = append(manual, apkDependencyName(scanner.Text()))
+		}
+		if err := scanner.Err(); err != nil {
+			return dependencyGraph{}, err
+		}
+	}
+	return newDependencyGraph("apk", requires, provides, manual), nil
+}
+
+// apkDependencyName returns the name of an apk dependency or provided
+// capability, e.g. "so:libc.musl-x86_64.so.1" from "so:libc.musl-x86_64.so.1=1"
+// or "busybox" from "busybox@edge>=1.36".
+func apkDependencyName(dep string) string {
+	if i := strings.IndexAny(dep, "<>=~@"); i >= 0 {
+		return dep[:i]
+	}
+	return dep
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+const testDependencyStatus = `Package: curl
+Status: install ok installed
+Version: 7.88.1-10+deb12u4
+Depends: libc6 (>= 2.34), libcurl4 (= 7.88.1-10+deb12u4), zlib1g (>= 1:1.1.4)
+
+Package: libcurl4
+Status: install ok installed
+Version: 7.88.1-10+deb12u4
+Depends: libc6 (>= 2.34), libssl3 (>= 3.0.0), zlib1g (>= 1:1.1.4)
+
+Package: libssl3
+Status: install ok installed
+Version: 3.0.11-1~deb12u2
+Depends: libc6 (>= 2.34)
+
+Package: zlib1g
+Status: install ok installed
+Version: 1:1.2.13.dfsg-1
+Depends: libc6 (>= 2.14)
+
+Package: libc6
+Status: install ok installed
+Version: 2.36-9+deb12u3
+Pre-Depends: debconf (>= 0.5) | debconf-2.0
+
+Package: cdebconf
+Status: install ok installed
+Version: 0.270
+Provides: debconf-2.0
+
+Package: libidn2-0
+Status: install ok installed
+Version: 2.3.3-1
+Depends: libc6 (>= 2.14)
+`
+
+func TestAptDependencyGraph(t *testing.T) {
+	root, err := ioutil.TempDir("", "dependency-analyzer")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	writeTestFiles(t, root, map[string]string{
+		dpkgStatusFile: testDependencyStatus,
+		aptExtendedStatesFile: "Package: libcurl4\nArchitecture: amd64\nAuto-Installed: 1\n\n" +
+			"Package: libssl3\nArchitecture: amd64\nAuto-Installed: 1\n\n" +
+			"Package: libidn2-0\nArchitecture: amd64\nAuto-Installed: 1\n",
+	})
+	graphs, err := getDependencyGraphs(pkgutil.Image{FSPath: root})
+	if err != nil {
+		t.Fatalf("Error building dependency graphs: %s", err)
+	}
+	if len(graphs) != 1 {
+		t.Fatalf("Expected only an apt dependency graph but got %v", graphs)
+	}
+	expected := []util.DependencyPackage{
+		{Package: "cdebconf", Manager: "apt", TopLevel: true, Dependencies: []string{}, Dependents: []string{"libc6"}},
+		{Package: "curl", Manager: "apt", TopLevel: true, Dependencies: []string{"libc6", "libcurl4", "zlib1g"}},
+		{Package: "libc6", Manager: "apt", TopLevel: true, Dependencies: []string{"cdebconf"}, Dependents: []string{"curl", "libcurl4", "libidn2-0", "libssl3", "zlib1g"}},
+		{Package: "libcurl4", Manager: "apt", Dependencies: []string{"libc6", "libssl3", "zlib1g"}, Dependents: []string{"curl"}},
+		{Package: "libidn2-0", Manager: "apt", Orphan: true, Dependencies: []string{"libc6"}},
+		{Package: "libssl3", Manager: "apt", Dependencies: []string{"libc6"}, Dependents: []string{"libcurl4"}},
+		{Package: "zlib1g", Manager: "apt", TopLevel: true, Dependencies: []string{"libc6"}, Dependents: []string{"curl", "libcurl4"}},
+	}
+	result, err := DependencyAnalyzer{}.Analyze(pkgutil.Image{FSPath: root})
+	if err != nil {
+		t.Fatalf("Error analyzing dependencies: %s", err)
+	}
+	if analysis := result.(*util.DependencyAnalyzeResult).Analysis; !reflect.DeepEqual(analysis, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, analysis)
+	}
+	if chain := graphs["apt"].chain("libssl3"); !reflect.DeepEqual(chain, []string{"curl", "libcurl4", "libssl3"}) {
+		t.Errorf("Expected libssl3 to be pulled in by curl but got %v", chain)
+	}
+	if chain := graphs["apt"].chain("libidn2-0"); chain != nil {
+		t.Errorf("Expected no chain for an orphan but got %v", chain)
+	}
+}
+
+func TestApkDependencyGraph(t *testing.T) {
+	root, err := ioutil.TempDir("", "dependency-analyzer")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	writeTestFiles(t, root, map[string]string{
+		"lib/apk/db/installed": "P:musl\nV:1.2.4-r2\np:so:libc.musl-x86_64.so.1=1\n\n" +
+			"P:busybox-binsh\nV:1.36.1-r15\nD:busybox=1.36.1-r15\np:/bin/sh cmd:sh=1.36.1-r15\n\n" +
+			"P:busybox\nV:1.36.1-r15\nD:so:libc.musl-x86_64.so.1\n\n" +
+			"P:curl\nV:8.5.0-r0\nD:ca-certificates-bundle so:libc.musl-x86_64.so.1 !curl-dev\n\n" +
+			"P:ca-certificates-bundle\nV:20230506-r0\nD:/bin/sh\n",
+		apkWorldFile: "busybox\ncurl@edge>=8\n",
+	})
+	graph, err := getApkDependencyGraph(root)
+	if err != nil {
+		t.Fatalf("Error building dependency graph: %s", err)
+	}
+	expected := map[string][]string{
+		"musl":                   {},
+		"busybox-binsh":          {"busybox"},
+		"busybox":                {"musl"},
+		"curl":                   {"ca-certificates-bundle", "musl"},
+		"ca-certificates-bundle": {"busybox-binsh"},
+	}
+	if !reflect.DeepEqual(graph.dependencies, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, graph.dependencies)
+	}
+	if !reflect.DeepEqual(graph.manual, map[string]bool{"busybox": true, "curl": true}) {
+		t.Errorf("Expected busybox and curl to be top-level but got %v", graph.manual)
+	}
+	if orphans := graph.orphans(); len(orphans) != 0 {
+		t.Errorf("Expected no orphans but got %v", orphans)
+	}
+}
+
+func TestParseRPMDependencies(t *testing.T) {
+	output := []string{
+		"bash\t/bin/sh,config(bash),filesystem >= 3,libc.so.6()(64bit),libtinfo.so.6()(64bit),rpmlib(BuiltinLuaScripts),\t/bin/bash,/bin/sh,bash,bash(x86-64),config(bash),",
+		"ncurses-libs\tlibc.so.6()(64bit),\tlibtinfo.so.6()(64bit),ncurses-libs,",
+		"glibc\t\tglibc,libc.so.6()(64bit),",
+		"",
+	}
+	requires, provides := parseRPMDependencies(output)
+	expectedRequires := map[string][][]string{
+		"bash":         {{"/bin/sh"}, {"config(bash)"}, {"filesystem >= 3"}, {"libc.so.6()(64bit)"}, {"libtinfo.so.6()(64bit)"}},
+		"ncurses-libs": {{"libc.so.6()(64bit)"}},
+		"glibc":        {},
+	}
+	if !reflect.DeepEqual(requires, expectedRequires) {
+		t.Errorf("Expected: %v but got: %v", expectedRequires, requires)
+	}
+	graph := newDependencyGraph("rpm", requires, provides, nil)
+	expected := map[string][]string{
+		"bash":         {"glibc", "ncurses-libs"},
+		"ncurses-libs": {"glibc"},
+		"glibc":        {},
+	}
+	if !reflect.DeepEqual(graph.dependencies, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, graph.dependencies)
+	}
+	if !graph.isTopLevel("bash") || graph.isTopLevel("glibc") {
+		t.Errorf("Expected only bash to be top-level")
+	}
+}
+
+func TestUniqueDependencyChains(t *testing.T) {
+	requires := map[string][][]string{
+		"curl":     {{"libcurl4"}},
+		"libcurl4": {{"libssl3"}},
+		"libssl3":  {},
+	}
+	graphs1 := map[string]dependencyGraph{
+		"apt": newDependencyGraph("apt", map[string][][]string{"libssl3": {}}, nil, nil),
+	}
+	graphs2 := map[string]dependencyGraph{
+		"apt": newDependencyGraph("apt", requires, nil, []string{"curl"}),
+	}
+	expected := []util.DependencyChain{
+		{Package: "curl", Manager: "apt", Chain: []string{"curl"}},
+		{Package: "libcurl4", Manager: "apt", Chain: []string{"curl", "libcurl4"}},
+	}
+	if chains := uniqueDependencyChains(graphs2, graphs1); !reflect.DeepEqual(chains, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, chains)
+	}
+	if chains := uniqueDependencyChains(graphs1, graphs2); len(chains) != 0 {
+		t.Errorf("Expected no packages only in the first image but got %v", chains)
+	}
+}
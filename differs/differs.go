@@ -26,6 +26,7 @@ import (
 
 const historyAnalyzer = "history"
 const metadataAnalyzer = "metadata"
+const manifestAnalyzer = "manifest"
 const fileAnalyzer = "file"
 const layerAnalyzer = "layer"
 const sizeAnalyzer = "size"
@@ -35,8 +36,25 @@ const aptLayerAnalyzer = "aptlayer"
 const rpmAnalyzer = "rpm"
 const rpmLayerAnalyzer = "rpmlayer"
 const pipAnalyzer = "pip"
+const pipLayerAnalyzer = "piplayer"
 const nodeAnalyzer = "node"
+const nodeLayerAnalyzer = "nodelayer"
 const emergeAnalyzer = "emerge"
+const emergeLayerAnalyzer = "emergelayer"
+const condaAnalyzer = "conda"
+const gemAnalyzer = "gem"
+const javaAnalyzer = "java"
+const goBinaryAnalyzer = "gobinary"
+const composerAnalyzer = "composer"
+const dotnetAnalyzer = "dotnet"
+const pacmanAnalyzer = "pacman"
+const pacmanLayerAnalyzer = "pacmanlayer"
+const rAnalyzer = "r"
+const licenseAnalyzer = "license"
+const ownershipAnalyzer = "ownership"
+const integrityAnalyzer = "integrity"
+const vulnAnalyzer = "vuln"
+const dependencyAnalyzer = "dependency"
 
 type DiffRequest struct {
 	Image1    pkgutil.Image
@@ -56,22 +74,40 @@ type Analyzer interface {
 }
 
 var Analyzers = map[string]Analyzer{
-	historyAnalyzer:   HistoryAnalyzer{},
-	metadataAnalyzer:  MetadataAnalyzer{},
-	fileAnalyzer:      FileAnalyzer{},
-	layerAnalyzer:     FileLayerAnalyzer{},
-	sizeAnalyzer:      SizeAnalyzer{},
-	sizeLayerAnalyzer: SizeLayerAnalyzer{},
-	aptAnalyzer:       AptAnalyzer{},
-	aptLayerAnalyzer:  AptLayerAnalyzer{},
-	rpmAnalyzer:       RPMAnalyzer{},
-	rpmLayerAnalyzer:  RPMLayerAnalyzer{},
-	pipAnalyzer:       PipAnalyzer{},
-	nodeAnalyzer:      NodeAnalyzer{},
-	emergeAnalyzer:    EmergeAnalyzer{},
+	historyAnalyzer:     HistoryAnalyzer{},
+	metadataAnalyzer:    MetadataAnalyzer{},
+	manifestAnalyzer:    ManifestAnalyzer{},
+	fileAnalyzer:        FileAnalyzer{},
+	layerAnalyzer:       FileLayerAnalyzer{},
+	sizeAnalyzer:        SizeAnalyzer{},
+	sizeLayerAnalyzer:   SizeLayerAnalyzer{},
+	aptAnalyzer:         AptAnalyzer{},
+	aptLayerAnalyzer:    AptLayerAnalyzer{},
+	rpmAnalyzer:         RPMAnalyzer{},
+	rpmLayerAnalyzer:    RPMLayerAnalyzer{},
+	pipAnalyzer:         PipAnalyzer{},
+	pipLayerAnalyzer:    PipLayerAnalyzer{},
+	nodeAnalyzer:        NodeAnalyzer{},
+	nodeLayerAnalyzer:   NodeLayerAnalyzer{},
+	emergeAnalyzer:      EmergeAnalyzer{},
+	emergeLayerAnalyzer: EmergeLayerAnalyzer{},
+	condaAnalyzer:       CondaAnalyzer{},
+	gemAnalyzer:         GemAnalyzer{},
+	javaAnalyzer:        JavaAnalyzer{},
+	goBinaryAnalyzer:    GoBinaryAnalyzer{},
+	composerAnalyzer:    ComposerAnalyzer{},
+	dotnetAnalyzer:      DotnetAnalyzer{},
+	pacmanAnalyzer:      PacmanAnalyzer{},
+	pacmanLayerAnalyzer: PacmanLayerAnalyzer{},
+	rAnalyzer:           RAnalyzer{},
+	licenseAnalyzer:     LicenseAnalyzer{},
+	ownershipAnalyzer:   OwnershipAnalyzer{},
+	integrityAnalyzer:   IntegrityAnalyzer{},
+	vulnAnalyzer:        VulnAnalyzer{},
+	dependencyAnalyzer:  DependencyAnalyzer{},
 }
 
-var LayerAnalyzers = [...]string{layerAnalyzer, sizeLayerAnalyzer, aptLayerAnalyzer, rpmLayerAnalyzer}
+var LayerAnalyzers = [...]string{layerAnalyzer, sizeLayerAnalyzer, aptLayerAnalyzer, rpmLayerAnalyzer, pipLayerAnalyzer, nodeLayerAnalyzer, emergeLayerAnalyzer, pacmanLayerAnalyzer}
 
 func (req DiffRequest) GetDiff() (map[string]util.Result, error) {
 	img1 := req.Image1
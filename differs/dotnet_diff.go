@@ -0,0 +1,156 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/sirupsen/logrus"
+)
+
+// .NET installation location; its shared directory holds the installed shared runtimes
+const dotnetRoot string = "/usr/share/dotnet"
+
+type DotnetAnalyzer struct {
+}
+
+func (a DotnetAnalyzer) Name() string {
+	return "DotnetAnalyzer"
+}
+
+// DotnetDiff compares the .NET dependencies of each application and the shared runtimes of two images.
+func (a DotnetAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := multiVersionDiff(image1, image2, a)
+	return diff, err
+}
+
+func (a DotnetAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := multiVersionAnalysis(image, a)
+	return analysis, err
+}
+
+// getPackages returns the libraries listed in the *.deps.json of each application keyed
+// by name and application directory, and the shared runtimes keyed by their directory.
+func (a DotnetAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]util.PackageInfo, error) {
+	root := filepath.Clean(image.FSPath)
+	packages := make(map[string]map[string]util.PackageInfo)
+	if _, err := os.Stat(root); err != nil {
+		// path provided invalid
+		return packages, err
+	}
+
+	getDotnetRuntimes(root, packages)
+
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			logrus.Debugf("Unable to read %s: %s", file, err)
+			return nil
+		}
+		if info.IsDir() {
+			if file == filepath.Join(root, dotnetRoot) {
+				// the runtimes and SDK ship their own deps.json
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(info.Name(), ".deps.json") {
+			return nil
+		}
+		libraries, err := readDotnetDeps(file)
+		if err != nil {
+			logrus.Warningf("Error reading .NET dependencies at %s: %s", file, err)
+			return nil
+		}
+		appDir := filepath.Dir(file)
+		project := "/" + strings.TrimPrefix(strings.TrimPrefix(appDir, root), "/")
+		for _, library := range libraries {
+			size := int64(-1)
+			if assembly, err := os.Stat(filepath.Join(appDir, library.name+".dll")); err == nil {
+				size = assembly.Size()
+			}
+			addToMap(packages, library.name, project, util.PackageInfo{Version: library.version, Size: size, Project: project})
+		}
+		return nil
+	})
+	return packages, err
+}
+
+// getDotnetRuntimes adds the shared runtimes, laid out as shared/{framework}/{version},
+// e.g. shared/Microsoft.NETCore.App/6.0.25.
+func getDotnetRuntimes(root string, packages map[string]map[string]util.PackageInfo) {
+	sharedDir := filepath.Join(dotnetRoot, "shared")
+	frameworks, err := ioutil.ReadDir(filepath.Join(root, sharedDir))
+	if err != nil {
+		// no shared runtimes installed
+		return
+	}
+	for _, framework := range frameworks {
+		if !framework.IsDir() {
+			continue
+		}
+		versions, err := ioutil.ReadDir(filepath.Join(root, sharedDir, framework.Name()))
+		if err != nil {
+			continue
+		}
+		for _, version := range versions {
+			if !version.IsDir() {
+				continue
+			}
+			runtimeDir := filepath.Join(sharedDir, framework.Name(), version.Name())
+			addToMap(packages, framework.Name(), runtimeDir, util.PackageInfo{
+				Version: version.Name(),
+				Size:    pkgutil.GetSize(filepath.Join(root, runtimeDir)),
+			})
+		}
+	}
+}
+
+type dotnetLibrary struct {
+	name    string
+	version string
+}
+
+// readDotnetDeps reads the libraries, keyed as {name}/{version}, of a deps.json.
+func readDotnetDeps(path string) ([]dotnetLibrary, error) {
+	jsonBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var deps struct {
+		Libraries map[string]struct {
+			Type string `json:"type"`
+		} `json:"libraries"`
+	}
+	if err := json.Unmarshal(jsonBytes, &deps); err != nil {
+		return nil, err
+	}
+	var libraries []dotnetLibrary
+	for key := range deps.Libraries {
+		parts := strings.SplitN(key, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		libraries = append(libraries, dotnetLibrary{name: parts[0], version: parts[1]})
+	}
+	return libraries, nil
+}
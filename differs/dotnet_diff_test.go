@@ -0,0 +1,82 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+func TestGetDotnetPackages(t *testing.T) {
+	root, err := ioutil.TempDir("", "dotnet-analyzer")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"app/MyApp.deps.json": `{
+			"runtimeTarget": {"name": ".NETCoreApp,Version=v6.0"},
+			"targets": {".NETCoreApp,Version=v6.0": {"MyApp/1.0.0": {}, "Newtonsoft.Json/13.0.1": {}}},
+			"libraries": {
+				"MyApp/1.0.0": {"type": "project"},
+				"Newtonsoft.Json/13.0.1": {"type": "package", "path": "newtonsoft.json/13.0.1"}
+			}
+		}`,
+		"app/MyApp.dll":           "assembly",
+		"app/Newtonsoft.Json.dll": "newtonsoft",
+		"usr/share/dotnet/shared/Microsoft.NETCore.App/6.0.25/Microsoft.NETCore.App.deps.json": `{"libraries": {"Microsoft.NETCore.App/6.0.25": {"type": "project"}}}`,
+		"usr/share/dotnet/shared/Microsoft.NETCore.App/8.0.0/System.dll":                       "system",
+		"usr/share/dotnet/sdk/8.0.100/dotnet.deps.json":                                        `{"libraries": {"dotnet/8.0.100": {"type": "project"}}}`,
+	}
+	for name, content := range files {
+		file := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write %s: %s", file, err)
+		}
+	}
+
+	expected := map[string]map[string]util.PackageInfo{
+		"MyApp":           {"/app": {Version: "1.0.0", Size: int64(len(files["app/MyApp.dll"])), Project: "/app"}},
+		"Newtonsoft.Json": {"/app": {Version: "13.0.1", Size: int64(len(files["app/Newtonsoft.Json.dll"])), Project: "/app"}},
+		"Microsoft.NETCore.App": {
+			"/usr/share/dotnet/shared/Microsoft.NETCore.App/6.0.25": {Version: "6.0.25",
+				Size: int64(len(files["usr/share/dotnet/shared/Microsoft.NETCore.App/6.0.25/Microsoft.NETCore.App.deps.json"]))},
+			"/usr/share/dotnet/shared/Microsoft.NETCore.App/8.0.0": {Version: "8.0.0",
+				Size: int64(len(files["usr/share/dotnet/shared/Microsoft.NETCore.App/8.0.0/System.dll"]))},
+		},
+	}
+
+	packages, err := DotnetAnalyzer{}.getPackages(pkgutil.Image{FSPath: root})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+
+	if _, err := (DotnetAnalyzer{}).getPackages(pkgutil.Image{FSPath: "testDirs/notThere"}); err == nil {
+		t.Errorf("Expected error for missing path")
+	}
+}
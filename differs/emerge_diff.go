@@ -49,6 +49,59 @@ func (em EmergeAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
 }
 
 func (em EmergeAnalyzer) getPackages(image pkgutil.Image) (map[string]util.PackageInfo, error) {
+	packages := make(map[string]util.PackageInfo)
+	pkgs, err := readEmergePackages(image)
+	if err != nil {
+		return packages, err
+	}
+	for _, pkg := range pkgs {
+		packages[pkg.name] = pkg.info
+	}
+	return packages, nil
+}
+
+type EmergeLayerAnalyzer struct{}
+
+func (em EmergeLayerAnalyzer) Name() string {
+	return "EmergeLayerAnalyzer"
+}
+
+// Diff compares the packages installed, removed or updated by emerge in each layer of two images.
+func (em EmergeLayerAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := singleVersionLayerDiff(image1, image2, em)
+	return diff, err
+}
+
+func (em EmergeLayerAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := singleVersionLayerAnalysis(image, em)
+	return analysis, err
+}
+
+func (em EmergeLayerAnalyzer) getPackages(image pkgutil.Image) ([]map[string]util.PackageInfo, error) {
+	var packages []map[string]util.PackageInfo
+	states, err := getLayerPackageStates(image, func(layer pkgutil.Image) ([]layerPackage, error) {
+		if _, err := os.Stat(filepath.Join(layer.FSPath, emergePkgFile)); err != nil {
+			// layer doesn't touch the emerge package database
+			return nil, nil
+		}
+		return readEmergePackages(layer)
+	})
+	if err != nil {
+		return packages, err
+	}
+	for _, state := range states {
+		layerPackages := make(map[string]util.PackageInfo)
+		for name, infos := range state {
+			layerPackages[name] = infos[""]
+		}
+		packages = append(packages, layerPackages)
+	}
+	return packages, nil
+}
+
+// readEmergePackages returns the packages recorded in the emerge package database of
+// the image filesystem, marked by their database entry.
+func readEmergePackages(image pkgutil.Image) ([]layerPackage, error) {
 	var path string
 	if image.FSPath == "" {
 		path = emergePkgFile
@@ -56,7 +109,7 @@ func (em EmergeAnalyzer) getPackages(image pkgutil.Image) (map[string]util.Packa
 		path = filepath.Join(image.FSPath, emergePkgFile)
 	}
 
-	packages := make(map[string]util.PackageInfo)
+	var packages []layerPackage
 	if _, err := os.Stat(path); err != nil {
 		// invalid image directory path
 		logrus.Errorf("Invalid image directory path %s", path)
@@ -73,6 +126,9 @@ func (em EmergeAnalyzer) getPackages(image pkgutil.Image) (map[string]util.Packa
 	for _, c := range contents {
 		// c := contents[i]
 		pkgPrefix := c.Name()
+		if pkgutil.IsWhiteout(pkgPrefix) {
+			continue
+		}
 		pkgContents, err := ioutil.ReadDir(filepath.Join(path, pkgPrefix))
 		if err != nil {
 			return packages, err
@@ -81,6 +137,9 @@ func (em EmergeAnalyzer) getPackages(image pkgutil.Image) (map[string]util.Packa
 		for _, c := range pkgContents {
 			// c := pkgContents[j]
 			pkgRawName := c.Name()
+			if pkgutil.IsWhiteout(pkgRawName) {
+				continue
+			}
 			// usually, the name of a package installed by emerge is formatted as '{pkgName}-{version}' e.g.(pymongo-3.9.0)
 			s := strings.Split(pkgRawName, "-")
 			if len(s) != 2 {
@@ -93,8 +152,13 @@ func (em EmergeAnalyzer) getPackages(image pkgutil.Image) (map[string]util.Packa
 				return packages, err
 			}
 			currPackage := util.PackageInfo{Version: version, Size: size}
+			readEmergeMetadata(filepath.Join(path, pkgPrefix, pkgRawName), &currPackage)
 			fullPackageName := strings.Join([]string{pkgPrefix, pkgName}, "/")
-			packages[fullPackageName] = currPackage
+			packages = append(packages, layerPackage{
+				name:   fullPackageName,
+				marker: filepath.Join(emergePkgFile, pkgPrefix, pkgRawName),
+				info:   currPackage,
+			})
 		}
 	}
 
@@ -123,3 +187,25 @@ func getPkgSize(pkgPath string) (int64, error) {
 	}
 	return size, nil
 }
+
+// readEmergeMetadata records the metadata emerge stores as one file per variable in
+// the package metadata directory, when present.
+func readEmergeMetadata(pkgDir string, info *util.PackageInfo) {
+	read := func(name string) string {
+		content, err := ioutil.ReadFile(filepath.Join(pkgDir, name))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(content))
+	}
+	info.Arch = read("CHOST")
+	info.License = read("LICENSE")
+	info.Homepage = read("HOMEPAGE")
+	for _, atom := range strings.Fields(read("RDEPEND")) {
+		// skip the USE conditional groups, e.g. "ssl? ( dev-libs/openssl )"
+		if atom == "(" || atom == ")" || atom == "||" || strings.HasSuffix(atom, "?") {
+			continue
+		}
+		info.Dependencies = append(info.Dependencies, atom)
+	}
+}
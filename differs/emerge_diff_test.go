@@ -48,7 +48,8 @@ func TestGetEmergePackages(t *testing.T) {
 			expected: map[string]util.PackageInfo{
 				"dev-python/pkg1": {Version: "0.0.1", Size: 167112},
 				"dev-python/pkg2": {Version: "0.0.2", Size: 167112},
-				"sys-libs/pkg3":   {Version: "0.0.3", Size: 167112}},
+				"sys-libs/pkg3": {Version: "0.0.3", Size: 167112, Arch: "x86_64-pc-linux-gnu", License: "GPL-2+",
+					Homepage: "https://example.org/pkg3", Dependencies: []string{">=sys-libs/glibc-2.37", "dev-libs/openssl:0="}}},
 		},
 	}
 	for _, test := range testCases {
@@ -66,3 +67,38 @@ func TestGetEmergePackages(t *testing.T) {
 		}
 	}
 }
+
+func TestGetEmergeLayerPackages(t *testing.T) {
+	image := pkgutil.Image{
+		FSPath: "testDirs/emergeLayers",
+		Layers: []pkgutil.Layer{
+			{FSPath: "testDirs/emergeLayers/layer1"},
+			{FSPath: "testDirs/emergeLayers/layer2"},
+			{FSPath: "testDirs/emergeLayers/layer3"},
+		},
+	}
+	expected := []map[string]util.PackageInfo{
+		{
+			"dev-python/pkg1": {Version: "0.0.1", Size: 1024},
+			"sys-libs/pkg3":   {Version: "0.0.3", Size: 2048},
+		},
+		// pkg1 is deleted through a whiteout
+		{
+			"dev-python/pkg2": {Version: "0.0.2", Size: 4096},
+			"sys-libs/pkg3":   {Version: "0.0.3", Size: 2048},
+		},
+		// sys-libs is replaced by an opaque directory
+		{
+			"dev-python/pkg2": {Version: "0.0.2", Size: 4096},
+			"sys-libs/pkg4":   {Version: "0.0.4", Size: 8192},
+		},
+	}
+
+	packages, err := EmergeLayerAnalyzer{}.getPackages(image)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+}
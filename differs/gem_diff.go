@@ -0,0 +1,169 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/sirupsen/logrus"
+)
+
+type GemAnalyzer struct {
+}
+
+func (a GemAnalyzer) Name() string {
+	return "GemAnalyzer"
+}
+
+// GemDiff compares the Ruby gems installed in each gem home of two images.
+func (a GemAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := multiVersionDiff(image1, image2, a)
+	return diff, err
+}
+
+func (a GemAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := multiVersionAnalysis(image, a)
+	return analysis, err
+}
+
+// getPackages returns the gems of the image keyed by name and installation
+// directory, within their gem home.
+func (a GemAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]util.PackageInfo, error) {
+	path := image.FSPath
+	packages := make(map[string]map[string]util.PackageInfo)
+	if _, err := os.Stat(path); err != nil {
+		// path provided invalid
+		return packages, err
+	}
+
+	for _, gemHome := range findGemHomes(path) {
+		mapPath := "/" + strings.TrimPrefix(strings.TrimPrefix(gemHome, filepath.Clean(path)), "/")
+		// default gems ship with Ruby and keep their specifications apart
+		for _, specDir := range []string{"specifications", "specifications/default"} {
+			specs, err := ioutil.ReadDir(filepath.Join(gemHome, specDir))
+			if err != nil {
+				continue
+			}
+			for _, spec := range specs {
+				if spec.IsDir() || !strings.HasSuffix(spec.Name(), ".gemspec") {
+					continue
+				}
+				gem, err := readGemspec(filepath.Join(gemHome, specDir, spec.Name()))
+				if err != nil {
+					logrus.Warningf("Error reading gemspec at %s: %s", filepath.Join(gemHome, specDir, spec.Name()), err)
+					continue
+				}
+				size := int64(-1)
+				gemDir := filepath.Join(gemHome, "gems", gem.fullName())
+				if _, err := os.Stat(gemDir); err == nil {
+					size = pkgutil.GetSize(gemDir)
+				}
+				currInfo := util.PackageInfo{
+					Version:     gem.version,
+					Size:        size,
+					Arch:        gem.platform,
+					Environment: mapPath,
+				}
+				// several versions of a gem can be installed in the same gem home
+				addToMap(packages, gem.name, filepath.Join(mapPath, "gems", gem.fullName()), currInfo)
+			}
+		}
+	}
+	return packages, nil
+}
+
+// findGemHomes returns the gem homes found anywhere in the filesystem at path, i.e.
+// the directories holding both a specifications and a gems directory.
+func findGemHomes(path string) []string {
+	var gemHomes []string
+	filepath.Walk(path, func(dir string, info os.FileInfo, err error) error {
+		if err != nil {
+			logrus.Debugf("Unable to read %s: %s", dir, err)
+			return nil
+		}
+		if !info.IsDir() || info.Name() != "specifications" {
+			return nil
+		}
+		gemHome := filepath.Dir(dir)
+		if gems, err := os.Stat(filepath.Join(gemHome, "gems")); err == nil && gems.IsDir() {
+			gemHomes = append(gemHomes, gemHome)
+		}
+		return filepath.SkipDir
+	})
+	return gemHomes
+}
+
+type gemspec struct {
+	name     string
+	version  string
+	platform string
+}
+
+// fullName returns the name of the directory the gem is installed to.
+func (g gemspec) fullName() string {
+	if g.platform == "" {
+		return g.name + "-" + g.version
+	}
+	return g.name + "-" + g.version + "-" + g.platform
+}
+
+var gemspecField = regexp.MustCompile(`^\s*\w+\.(name|version|platform)\s*=\s*(?:Gem::Version\.new\()?"([^"]*)"`)
+
+// readGemspec reads the name, version and platform of an installed gemspec.
+// Gems for the generic "ruby" platform have no platform.
+func readGemspec(path string) (gemspec, error) {
+	var gem gemspec
+	specFile, err := os.Open(path)
+	if err != nil {
+		return gem, err
+	}
+	defer specFile.Close()
+
+	scanner := bufio.NewScanner(specFile)
+	for scanner.Scan() {
+		match := gemspecField.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		switch match[1] {
+		case "name":
+			gem.name = match[2]
+		case "version":
+			gem.version = match[2]
+		case "platform":
+			gem.platform = match[2]
+		}
+	}
+	if gem.platform == "ruby" {
+		gem.platform = ""
+	}
+	if gem.name == "" || gem.version == "" {
+		// fall back to the gemspec file name, formatted as '{name}-{version}.gemspec'
+		fullName := strings.TrimSuffix(filepath.Base(path), ".gemspec")
+		if i := strings.LastIndex(fullName, "-"); i > 0 {
+			gem.name, gem.version = fullName[:i], fullName[i+1:]
+		}
+	}
+	return gem, scanner.Err()
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+func TestGetGemPackages(t *testing.T) {
+	testCases := []struct {
+		descrip  string
+		path     string
+		expected map[string]map[string]util.PackageInfo
+		err      bool
+	}{
+		{
+			descrip:  "no directory",
+			path:     "testDirs/notThere",
+			expected: map[string]map[string]util.PackageInfo{},
+			err:      true,
+		},
+		{
+			descrip:  "no gems",
+			path:     "testDirs/noPackages",
+			expected: map[string]map[string]util.PackageInfo{},
+		},
+		{
+			descrip: "system and bundle gem homes, with two versions of a gem in one home",
+			path:    "testDirs/gemTests",
+			expected: map[string]map[string]util.PackageInfo{
+				"rails": {
+					"/usr/local/bundle/gems/rails-7.0.4":        {Version: "7.0.4", Size: 6, Environment: "/usr/local/bundle"},
+					"/usr/lib/ruby/gems/3.1.0/gems/rails-6.1.7": {Version: "6.1.7", Size: 7, Environment: "/usr/lib/ruby/gems/3.1.0"},
+				},
+				"nokogiri": {
+					"/usr/local/bundle/gems/nokogiri-1.13.10-x86_64-linux": {Version: "1.13.10", Size: 9, Arch: "x86_64-linux", Environment: "/usr/local/bundle"},
+				},
+				"rake": {
+					"/usr/local/bundle/gems/rake-13.0.6": {Version: "13.0.6", Size: 5, Environment: "/usr/local/bundle"},
+					"/usr/local/bundle/gems/rake-13.1.0": {Version: "13.1.0", Size: 8, Environment: "/usr/local/bundle"},
+				},
+				"json": {
+					"/usr/lib/ruby/gems/3.1.0/gems/json-2.6.1": {Version: "2.6.1", Size: -1, Environment: "/usr/lib/ruby/gems/3.1.0"},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		image := pkgutil.Image{FSPath: test.path}
+		packages, err := GemAnalyzer{}.getPackages(image)
+		if err != nil && !test.err {
+			t.Errorf("%s: Got unexpected error: %s", test.descrip, err)
+		}
+		if err == nil && test.err {
+			t.Errorf("%s: Expected error but got none.", test.descrip)
+		}
+		if !reflect.DeepEqual(packages, test.expected) {
+			t.Errorf("%s: Expected: %v but got: %v", test.descrip, test.expected, packages)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"debug/buildinfo"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/sirupsen/logrus"
+)
+
+// goStdlibModule is the module name under which the Go toolchain a binary was built with is reported
+const goStdlibModule = "stdlib"
+
+// goDevelVersion is the version Go records for modules built from a local directory
+const goDevelVersion = "(devel)"
+
+type GoBinaryAnalyzer struct {
+}
+
+func (a GoBinaryAnalyzer) Name() string {
+	return "GoBinaryAnalyzer"
+}
+
+// GoBinaryDiff compares the Go modules built into the binaries of two images.
+func (a GoBinaryAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := multiVersionDiff(image1, image2, a)
+	return diff, err
+}
+
+func (a GoBinaryAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := multiVersionAnalysis(image, a)
+	return analysis, err
+}
+
+// getPackages returns the Go modules built into the executables of the image,
+// keyed by module path and binary.
+func (a GoBinaryAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]util.PackageInfo, error) {
+	root := filepath.Clean(image.FSPath)
+	packages := make(map[string]map[string]util.PackageInfo)
+	if _, err := os.Stat(root); err != nil {
+		// path provided invalid
+		return packages, err
+	}
+
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			logrus.Debugf("Unable to read %s: %s", file, err)
+			return nil
+		}
+		if !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			return nil
+		}
+		buildInfo, err := buildinfo.ReadFile(file)
+		if err != nil {
+			// not a Go binary, or built without module support
+			return nil
+		}
+		binary := "/" + strings.TrimPrefix(strings.TrimPrefix(file, root), "/")
+		for name, module := range getGoModules(buildInfo, info.Size()) {
+			addToMap(packages, name, binary, module)
+		}
+		return nil
+	})
+	return packages, err
+}
+
+// getGoModules returns the modules a binary was built from: the Go toolchain as the
+// stdlib module, the main module, with the build settings but the version control
+// ones as its build and the binary size as its size, and the dependencies along
+// with their replacements.
+func getGoModules(buildInfo *debug.BuildInfo, size int64) map[string]util.PackageInfo {
+	modules := make(map[string]util.PackageInfo)
+	modules[goStdlibModule] = util.PackageInfo{Version: strings.TrimPrefix(buildInfo.GoVersion, "go"), Size: -1}
+
+	var settings []string
+	for _, setting := range buildInfo.Settings {
+		if setting.Key == "vcs" || strings.HasPrefix(setting.Key, "vcs.") {
+			// the commit and time of the checkout change with every build
+			continue
+		}
+		settings = append(settings, setting.Key+"="+setting.Value)
+	}
+	if buildInfo.Main.Path != "" {
+		modules[buildInfo.Main.Path] = util.PackageInfo{
+			Version: buildInfo.Main.Version,
+			Size:    size,
+			Build:   strings.Join(settings, " "),
+		}
+	}
+
+	for _, dep := range buildInfo.Deps {
+		module := util.PackageInfo{Version: dep.Version, Size: -1}
+		if dep.Replace != nil {
+			// the replacement is what was built into the binary; local
+			// directory replacements have no version, like a main module
+			// built from a checkout
+			module.Version = dep.Replace.Version
+			if module.Version == "" {
+				module.Version = goDevelVersion
+			}
+			module.Replacement = dep.Replace.Path
+		}
+		modules[dep.Path] = module
+	}
+	return modules
+}
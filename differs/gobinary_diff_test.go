@@ -0,0 +1,97 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+func TestGetGoModules(t *testing.T) {
+	buildInfo := &debug.BuildInfo{
+		GoVersion: "go1.21.5",
+		Main:      debug.Module{Path: "example.com/server", Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "github.com/sirupsen/logrus", Version: "v1.9.3"},
+			{Path: "golang.org/x/net", Version: "v0.17.0", Replace: &debug.Module{Path: "golang.org/x/net", Version: "v0.19.0"}},
+			{Path: "example.com/lib", Version: "v1.0.0", Replace: &debug.Module{Path: "../lib"}},
+		},
+		Settings: []debug.BuildSetting{{Key: "CGO_ENABLED", Value: "0"}, {Key: "GOOS", Value: "linux"},
+			{Key: "vcs", Value: "git"}, {Key: "vcs.revision", Value: "2f1e9c7"}, {Key: "vcs.time", Value: "2024-01-15T10:30:00Z"}},
+	}
+	expected := map[string]util.PackageInfo{
+		"stdlib":                     {Version: "1.21.5", Size: -1},
+		"example.com/server":         {Version: "(devel)", Size: 1024, Build: "CGO_ENABLED=0 GOOS=linux"},
+		"github.com/sirupsen/logrus": {Version: "v1.9.3", Size: -1},
+		"golang.org/x/net":           {Version: "v0.19.0", Size: -1, Replacement: "golang.org/x/net"},
+		"example.com/lib":            {Version: "(devel)", Size: -1, Replacement: "../lib"},
+	}
+	if modules := getGoModules(buildInfo, 1024); !reflect.DeepEqual(modules, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, modules)
+	}
+}
+
+func TestGetGoBinaryPackages(t *testing.T) {
+	// the test binary itself is a Go binary carrying build info
+	testBinary, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Unable to locate test binary: %s", err)
+	}
+	content, err := ioutil.ReadFile(testBinary)
+	if err != nil {
+		t.Fatalf("Unable to read test binary: %s", err)
+	}
+	root, err := ioutil.TempDir("", "gobinary-analyzer")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+	os.MkdirAll(filepath.Join(root, "usr/local/bin"), 0755)
+	if err := ioutil.WriteFile(filepath.Join(root, "usr/local/bin/server"), content, 0755); err != nil {
+		t.Fatalf("Unable to write binary: %s", err)
+	}
+	// neither a non-executable copy nor a script is reported
+	ioutil.WriteFile(filepath.Join(root, "usr/local/bin/server.bak"), content, 0644)
+	ioutil.WriteFile(filepath.Join(root, "usr/local/bin/run.sh"), []byte("#!/bin/sh\n"), 0755)
+
+	packages, err := GoBinaryAnalyzer{}.getPackages(pkgutil.Image{FSPath: root})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	stdlib, ok := packages["stdlib"]["/usr/local/bin/server"]
+	if !ok || len(packages["stdlib"]) != 1 {
+		t.Fatalf("Expected stdlib to be found only in /usr/local/bin/server but got %v", packages["stdlib"])
+	}
+	buildInfo, _ := debug.ReadBuildInfo()
+	if stdlib.Version != strings.TrimPrefix(buildInfo.GoVersion, "go") {
+		t.Errorf("Expected Go version %s but got %s", buildInfo.GoVersion, stdlib.Version)
+	}
+	logrus := packages["github.com/sirupsen/logrus"]["/usr/local/bin/server"]
+	for _, dep := range buildInfo.Deps {
+		if dep.Path == "github.com/sirupsen/logrus" && logrus.Version != dep.Version {
+			t.Errorf("Expected logrus %s but got %s", dep.Version, logrus.Version)
+		}
+	}
+}
@@ -18,70 +18,78 @@ package differs
 
 import (
 	"strings"
+	"time"
 
 	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
 	"github.com/GoogleContainerTools/container-diff/util"
-	"github.com/google/go-containerregistry/pkg/v1"
 )
 
 type HistoryAnalyzer struct {
 }
 
-type HistDiff struct {
-	Adds []string
-	Dels []string
-}
-
 func (a HistoryAnalyzer) Name() string {
 	return "HistoryAnalyzer"
 }
 
+// Diff aligns the histories of two images per step, see util.GetHistoryDiff.
 func (a HistoryAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
-	diff, err := getHistoryDiff(image1, image2)
+	history1, err := getHistory(image1)
+	if err != nil {
+		return &util.HistDiffResult{}, err
+	}
+	history2, err := getHistory(image2)
+	if err != nil {
+		return &util.HistDiffResult{}, err
+	}
 	return &util.HistDiffResult{
 		Image1:   image1.Source,
 		Image2:   image2.Source,
 		DiffType: "History",
-		Diff:     diff,
-	}, err
+		Diff:     util.GetHistoryDiff(history1, history2),
+	}, nil
 }
 
 func (a HistoryAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
-	c, err := image.Image.ConfigFile()
+	history, err := getHistory(image)
 	if err != nil {
-		return util.ListAnalyzeResult{}, err
+		return &util.HistoryAnalyzeResult{}, err
 	}
-	history := getHistoryList(c.History)
-	result := util.ListAnalyzeResult{
+	return &util.HistoryAnalyzeResult{
 		Image:       image.Source,
 		AnalyzeType: "History",
 		Analysis:    history,
-	}
-	return &result, nil
+	}, nil
 }
 
-func getHistoryDiff(image1, image2 pkgutil.Image) (HistDiff, error) {
-	c1, err := image1.Image.ConfigFile()
+// getHistory returns the history of the image, each step creating a layer
+// being matched with the next layer of the manifest.
+func getHistory(image pkgutil.Image) ([]util.HistoryStep, error) {
+	configFile, err := image.Image.ConfigFile()
 	if err != nil {
-		return HistDiff{}, err
+		return nil, err
 	}
-	c2, err := image2.Image.ConfigFile()
+	manifest, err := image.Image.Manifest()
 	if err != nil {
-		return HistDiff{}, err
+		return nil, err
 	}
-	history1 := getHistoryList(c1.History)
-	history2 := getHistoryList(c2.History)
-
-	adds := util.GetAdditions(history1, history2)
-	dels := util.GetDeletions(history1, history2)
-	diff := HistDiff{adds, dels}
-	return diff, nil
-}
-
-func getHistoryList(historyItems []v1.History) []string {
-	strhistory := make([]string, len(historyItems))
-	for i, layer := range historyItems {
-		strhistory[i] = strings.TrimSpace(layer.CreatedBy)
+	history := []util.HistoryStep{}
+	layer := 0
+	for _, item := range configFile.History {
+		step := util.HistoryStep{
+			CreatedBy:  strings.TrimSpace(item.CreatedBy),
+			Author:     item.Author,
+			Comment:    item.Comment,
+			EmptyLayer: item.EmptyLayer,
+		}
+		if !item.Created.IsZero() {
+			step.Created = item.Created.UTC().Format(time.RFC3339)
+		}
+		if !item.EmptyLayer && layer < len(manifest.Layers) {
+			step.Layer = manifest.Layers[layer].Digest.String()
+			step.Size = manifest.Layers[layer].Size
+			layer++
+		}
+		history = append(history, step)
 	}
-	return strhistory
+	return history, nil
 }
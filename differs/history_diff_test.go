@@ -0,0 +1,68 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestGetHistory(t *testing.T) {
+	base, err := random.Layer(1024, types.DockerLayer)
+	if err != nil {
+		t.Fatalf("Unable to create layer: %s", err)
+	}
+	app, err := random.Layer(2048, types.DockerLayer)
+	if err != nil {
+		t.Fatalf("Unable to create layer: %s", err)
+	}
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+	image, err := mutate.Append(empty.Image,
+		mutate.Addendum{Layer: base, History: v1.History{CreatedBy: "/bin/sh -c #(nop) ADD file:abc in / ", Created: v1.Time{Time: created}}},
+		mutate.Addendum{History: v1.History{CreatedBy: "/bin/sh -c #(nop)  ENV LANG=C.UTF-8", EmptyLayer: true}},
+		mutate.Addendum{Layer: app, History: v1.History{CreatedBy: "/bin/sh -c apt-get update", Author: "team@example.com", Comment: "buildkit.dockerfile.v0"}},
+	)
+	if err != nil {
+		t.Fatalf("Unable to create image: %s", err)
+	}
+
+	history, err := getHistory(pkgutil.Image{Image: image})
+	if err != nil {
+		t.Fatalf("Error reading history: %s", err)
+	}
+	baseDigest, _ := base.Digest()
+	baseSize, _ := base.Size()
+	appDigest, _ := app.Digest()
+	appSize, _ := app.Size()
+	expected := []util.HistoryStep{
+		{CreatedBy: "/bin/sh -c #(nop) ADD file:abc in /", Created: "2024-01-15T09:30:00Z", Layer: baseDigest.String(), Size: baseSize},
+		{CreatedBy: "/bin/sh -c #(nop)  ENV LANG=C.UTF-8", EmptyLayer: true},
+		{CreatedBy: "/bin/sh -c apt-get update", Author: "team@example.com", Comment: "buildkit.dockerfile.v0", Layer: appDigest.String(), Size: appSize},
+	}
+	if !reflect.DeepEqual(history, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, history)
+	}
+}
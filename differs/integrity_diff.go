@@ -0,0 +1,420 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/sirupsen/logrus"
+)
+
+// dpkg configuration files, which may exclude paths from installation
+var dpkgConfigs = []string{"/etc/dpkg/dpkg.cfg", "/etc/dpkg/dpkg.cfg.d/*"}
+
+// RPM command to list the digest, flags and state of every file of the installed packages
+var rpmDigestsCmd = []string{
+	"rpm", "--nodigest", "--nosignature",
+	"-qa", "--qf", "[%{NAME}\t%{FILENAMES}\t%{FILEDIGESTS}\t%{FILEFLAGS:fflags}\t%{FILESTATES:fstate}\t%{FILEDIGESTALGO}\n]",
+}
+
+// rpmDigestAlgos maps the values of the RPM FILEDIGESTALGO tag to digest algorithms
+var rpmDigestAlgos = map[string]string{
+	rpmNone: "md5",
+	"1":     "md5",
+	"2":     "sha1",
+	"8":     "sha256",
+	"9":     "sha384",
+	"10":    "sha512",
+}
+
+var digestHashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+type integrityKey struct{ manager, pkg, path string }
+
+// packageIntegrity stores the package files failing verification in an image,
+// along with all the package files verified.
+type packageIntegrity struct {
+	failures []util.FileIntegrity
+	checked  map[integrityKey]bool
+}
+
+type IntegrityAnalyzer struct {
+}
+
+func (a IntegrityAnalyzer) Name() string {
+	return "IntegrityAnalyzer"
+}
+
+// Diff reports the package files whose verification regressed or was resolved between two images.
+func (a IntegrityAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	integrity1, err := getPackageIntegrity(image1)
+	if err != nil {
+		return &util.IntegrityDiffResult{}, err
+	}
+	integrity2, err := getPackageIntegrity(image2)
+	if err != nil {
+		return &util.IntegrityDiffResult{}, err
+	}
+	return &util.IntegrityDiffResult{
+		Image1:   image1.Source,
+		Image2:   image2.Source,
+		DiffType: "Integrity",
+		Diff:     diffPackageIntegrity(integrity1, integrity2),
+	}, nil
+}
+
+func (a IntegrityAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	integrity, err := getPackageIntegrity(image)
+	if err != nil {
+		return &util.IntegrityAnalyzeResult{}, err
+	}
+	return &util.IntegrityAnalyzeResult{
+		Image:       image.Source,
+		AnalyzeType: "Integrity",
+		Analysis:    integrity.failures,
+	}, nil
+}
+
+func diffPackageIntegrity(integrity1, integrity2 packageIntegrity) util.IntegrityDiff {
+	diff := util.IntegrityDiff{Regressions: []util.FileIntegrity{}, Resolved: []util.FileIntegrity{}}
+	failures1 := make(map[integrityKey]util.IntegrityStatus)
+	for _, f := range integrity1.failures {
+		failures1[integrityKey{f.Manager, f.Package, f.Path}] = f.Status
+	}
+	failures2 := make(map[integrityKey]util.IntegrityStatus)
+	for _, f := range integrity2.failures {
+		failures2[integrityKey{f.Manager, f.Package, f.Path}] = f.Status
+	}
+
+	for _, f := range integrity2.failures {
+		if f.Status != util.ConfigModified && failures1[integrityKey{f.Manager, f.Package, f.Path}] != f.Status {
+			diff.Regressions = append(diff.Regressions, f)
+		}
+	}
+	for _, f := range integrity1.failures {
+		key := integrityKey{f.Manager, f.Package, f.Path}
+		if _, failing := failures2[key]; f.Status != util.ConfigModified && !failing && integrity2.checked[key] {
+			diff.Resolved = append(diff.Resolved, f)
+		}
+	}
+	util.SortFileIntegrity(diff.Regressions)
+	util.SortFileIntegrity(diff.Resolved)
+	return diff
+}
+
+// getPackageIntegrity verifies the files of the dpkg, rpm and pip packages of the
+// image against the digests recorded by their package manager.
+func getPackageIntegrity(image pkgutil.Image) (packageIntegrity, error) {
+	root := image.FSPath
+	integrity := packageIntegrity{
+		failures: []util.FileIntegrity{},
+		checked:  make(map[integrityKey]bool),
+	}
+	if _, err := os.Stat(root); err != nil {
+		// invalid image directory path
+		return integrity, err
+	}
+
+	resolver := newPathResolver(root)
+	verify := func(manager string, files []packageFile, excluded func(string) bool) {
+		for _, file := range files {
+			if file.digest == "" {
+				continue
+			}
+			path := resolver.resolve(file.path)
+			status, ok := verifyFile(filepath.Join(root, path), file.digest)
+			if !ok {
+				continue
+			}
+			if status == util.FileMissing && excluded != nil && excluded(file.path) {
+				// intentionally not installed
+				continue
+			}
+			integrity.checked[integrityKey{manager, file.pkg, file.path}] = true
+			if status == "" {
+				continue
+			}
+			if file.config {
+				status = util.ConfigModified
+			}
+			integrity.failures = append(integrity.failures, util.FileIntegrity{
+				Path:    file.path,
+				Package: file.pkg,
+				Manager: manager,
+				Status:  status,
+			})
+		}
+	}
+
+	dpkgFiles, err := readDpkgMd5sums(root)
+	if err != nil {
+		return integrity, err
+	}
+	conffiles, err := readDpkgConffiles(root)
+	if err != nil {
+		return integrity, err
+	}
+	verify("apt", append(dpkgFiles, conffiles...), readDpkgPathFilters(root))
+
+	if hasRPMBinary(root) {
+		output, err := rpmQuery(image, rpmDigestsCmd)
+		if err != nil {
+			return integrity, err
+		}
+		verify("rpm", parseRPMDigests(output), nil)
+	}
+
+	records, err := findPipRecords(root)
+	if err != nil {
+		return integrity, err
+	}
+	for _, record := range records {
+		pipFiles, err := readPipRecord(root, record)
+		if err != nil {
+			logrus.Warnf("Could not read %s: %s", record, err)
+			continue
+		}
+		verify("pip", pipFiles, nil)
+	}
+
+	util.SortFileIntegrity(integrity.failures)
+	return integrity, nil
+}
+
+// verifyFile checks the file against its digest, as algorithm:hex, and returns the
+// integrity status of the file, empty if it matches, or false if it can't be verified.
+func verifyFile(path, digest string) (util.IntegrityStatus, bool) {
+	parts := strings.SplitN(digest, ":", 2)
+	newHash, ok := digestHashes[parts[0]]
+	if !ok || len(parts) != 2 {
+		return "", false
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return util.FileMissing, true
+	}
+	if !info.Mode().IsRegular() {
+		return util.FileReplaced, true
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		logrus.Warnf("Could not verify %s: %s", path, err)
+		return "", false
+	}
+	defer file.Close()
+	h := newHash()
+	if _, err := io.Copy(h, file); err != nil {
+		logrus.Warnf("Could not verify %s: %s", path, err)
+		return "", false
+	}
+	if hex.EncodeToString(h.Sum(nil)) != strings.ToLower(parts[1]) {
+		return util.FileModified, true
+	}
+	return "", true
+}
+
+// readDpkgMd5sums returns the files listed in the <package>.md5sums files of the
+// dpkg database, along with their md5 digest.
+func readDpkgMd5sums(root string) ([]packageFile, error) {
+	var files []packageFile
+	sums, err := filepath.Glob(filepath.Join(root, dpkgInfoDir, "*.md5sums"))
+	if err != nil {
+		return files, err
+	}
+	for _, sum := range sums {
+		pkg := strings.SplitN(strings.TrimSuffix(filepath.Base(sum), ".md5sums"), ":", 2)[0]
+		contents, err := ioutil.ReadFile(sum)
+		if err != nil {
+			return files, err
+		}
+		for _, line := range strings.Split(string(contents), "\n") {
+			// md5sum output: the digest and the path relative to /, two spaces apart
+			fields := strings.SplitN(line, "  ", 2)
+			if len(fields) == 2 {
+				files = append(files, packageFile{pkg: pkg, path: "/" + fields[1], digest: "md5:" + fields[0]})
+			}
+		}
+	}
+	return files, nil
+}
+
+// readDpkgConffiles returns the configuration files listed in the Conffiles field
+// of each package in the dpkg status file, along with the md5 digest they were
+// installed with. Obsolete configuration files are ignored.
+func readDpkgConffiles(root string) ([]packageFile, error) {
+	var files []packageFile
+	file, err := os.Open(filepath.Join(root, dpkgStatusFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return files, nil
+		}
+		return files, err
+	}
+	defer file.Close()
+
+	var pkg string
+	inConffiles := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, " ") {
+			fields := strings.Fields(line)
+			if inConffiles && len(fields) == 2 && len(fields[1]) == md5.Size*2 {
+				files = append(files, packageFile{pkg: pkg, path: fields[0], digest: "md5:" + fields[1], config: true})
+			}
+			continue
+		}
+		inConffiles = strings.HasPrefix(line, "Conffiles:")
+		if strings.HasPrefix(line, "Package:") {
+			pkg = strings.TrimSpace(strings.TrimPrefix(line, "Package:"))
+		}
+	}
+	return files, scanner.Err()
+}
+
+type dpkgPathFilter struct {
+	include bool
+	pattern *regexp.Regexp
+}
+
+// readDpkgPathFilters reads the path-exclude and path-include options of the dpkg
+// configuration, used by minimized images to skip documentation, and returns
+// whether a path was excluded from installation. As with dpkg, the last
+// matching filter applies.
+func readDpkgPathFilters(root string) func(string) bool {
+	var filters []dpkgPathFilter
+	for _, config := range dpkgConfigs {
+		paths, _ := filepath.Glob(filepath.Join(root, config))
+		for _, path := range paths {
+			contents, err := ioutil.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			for _, line := range strings.Split(string(contents), "\n") {
+				fields := strings.Fields(strings.Replace(line, "=", " ", 1))
+				if len(fields) != 2 || (fields[0] != "path-exclude" && fields[0] != "path-include") {
+					continue
+				}
+				pattern, err := globRegexp(fields[1])
+				if err != nil {
+					logrus.Warnf("Invalid dpkg path filter %s: %s", fields[1], err)
+					continue
+				}
+				filters = append(filters, dpkgPathFilter{include: fields[0] == "path-include", pattern: pattern})
+			}
+		}
+	}
+	return func(path string) bool {
+		excluded := false
+		for _, filter := range filters {
+			if filter.pattern.MatchString(path) {
+				excluded = !filter.include
+			}
+		}
+		return excluded
+	}
+}
+
+// globRegexp converts a glob pattern to a regular expression matching it. Like
+// fnmatch without FNM_PATHNAME, as used by dpkg, wildcards match slashes too.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := strings.Replace(glob[i+1:i+end], `\`, `\\`, -1)
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// parseRPMDigests parses the output of rpmDigestsCmd. Files without digest, such
+// as directories and symbolic links, and files which weren't installed, e.g.
+// documentation excluded with --excludedocs, are skipped.
+func parseRPMDigests(rpmOutput []string) []packageFile {
+	var files []packageFile
+	for _, line := range rpmOutput {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 6 || fields[2] == "" || fields[4] != "normal" {
+			continue
+		}
+		algo, ok := rpmDigestAlgos[fields[5]]
+		if !ok {
+			continue
+		}
+		files = append(files, packageFile{
+			pkg:    fields[0],
+			path:   fields[1],
+			digest: algo + ":" + fields[2],
+			config: strings.Contains(fields[3], "c"),
+		})
+	}
+	return files
+}
+
+// findPipRecords returns the RECORD files of the dist-info directories of the image.
+func findPipRecords(root string) ([]string, error) {
+	var records []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if relPath := strings.TrimPrefix(path, root); isPipRecord(relPath) && info.Mode().IsRegular() {
+			records = append(records, filepath.Join("/", relPath))
+		}
+		return nil
+	})
+	return records, err
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+func md5Hex(content string) string {
+	sum := md5.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestGetPackageIntegrity(t *testing.T) {
+	root, err := ioutil.TempDir("", "integrity-analyzer")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	sum := sha256.Sum256([]byte("import"))
+	recordHash := "sha256=" + base64.RawURLEncoding.EncodeToString(sum[:])
+	writeTestFiles(t, root, map[string]string{
+		"var/lib/dpkg/status": "Package: bash\nVersion: 5.2\nConffiles:\n /etc/bash.bashrc " + md5Hex("# bashrc") +
+			"\n /etc/skel/.bashrc " + md5Hex("# skel") + "\n /etc/old.conf " + md5Hex("old") + " obsolete\n",
+		"var/lib/dpkg/info/bash.md5sums": md5Hex("bash") + "  usr/bin/bash\n" + md5Hex("clear") + "  usr/bin/clear_console\n" +
+			md5Hex("doc") + "  usr/share/doc/bash/README\n" + md5Hex("bashbug") + "  usr/bin/bashbug\n",
+		"var/lib/dpkg/info/coreutils.md5sums": md5Hex("ls") + "  bin/ls\n" + md5Hex("cat") + "  bin/cat\n",
+		"etc/dpkg/dpkg.cfg.d/excludes":        "# minimized image\npath-exclude=/usr/share/doc/*\npath-include=/usr/share/doc/*/copyright\n",
+		"usr/bin/bash":                        "bash",
+		"usr/bin/bashbug":                     "patched",
+		"usr/bin/ls":                          "ls",
+		"usr/bin/cat":                         "cat",
+		"etc/bash.bashrc":                     "# customized bashrc",
+		"etc/skel/.bashrc":                    "# skel",
+		"usr/lib/python3/dist-packages/requests/__init__.py":               "import",
+		"usr/lib/python3/dist-packages/requests-2.31.0.dist-info/METADATA": "Name: requests\n",
+		"usr/lib/python3/dist-packages/requests-2.31.0.dist-info/RECORD": "requests/__init__.py," + recordHash + ",6\n" +
+			"requests/api.py," + recordHash + ",6\nrequests-2.31.0.dist-info/RECORD,,\n",
+	})
+	// merged /usr: the dpkg file lists refer to /bin
+	if err := os.Symlink("usr/bin", filepath.Join(root, "bin")); err != nil {
+		t.Fatalf("Unable to link /bin: %s", err)
+	}
+	// clear_console replaced by a link to another binary
+	if err := os.Symlink("bash", filepath.Join(root, "usr/bin/clear_console")); err != nil {
+		t.Fatalf("Unable to link clear_console: %s", err)
+	}
+
+	expected := []util.FileIntegrity{
+		{Path: "/etc/bash.bashrc", Package: "bash", Manager: "apt", Status: util.ConfigModified},
+		{Path: "/usr/bin/bashbug", Package: "bash", Manager: "apt", Status: util.FileModified},
+		{Path: "/usr/bin/clear_console", Package: "bash", Manager: "apt", Status: util.FileReplaced},
+		{Path: "/usr/lib/python3/dist-packages/requests/api.py", Package: "requests", Manager: "pip", Status: util.FileMissing},
+	}
+	integrity, err := getPackageIntegrity(pkgutil.Image{FSPath: root})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(integrity.failures, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, integrity.failures)
+	}
+	if !integrity.checked[integrityKey{"apt", "coreutils", "/bin/ls"}] {
+		t.Errorf("Expected /bin/ls to be verified")
+	}
+	if integrity.checked[integrityKey{"apt", "bash", "/usr/share/doc/bash/README"}] {
+		t.Errorf("Expected excluded /usr/share/doc/bash/README not to be verified")
+	}
+
+	if _, err := getPackageIntegrity(pkgutil.Image{FSPath: "testDirs/notThere"}); err == nil {
+		t.Errorf("Expected error for missing path")
+	}
+}
+
+func TestDiffPackageIntegrity(t *testing.T) {
+	integrity1 := packageIntegrity{
+		failures: []util.FileIntegrity{
+			{Path: "/etc/bash.bashrc", Package: "bash", Manager: "apt", Status: util.ConfigModified},
+			{Path: "/usr/bin/curl", Package: "curl", Manager: "apt", Status: util.FileModified},
+			{Path: "/usr/bin/wget", Package: "wget", Manager: "apt", Status: util.FileMissing},
+			{Path: "/usr/bin/ls", Package: "coreutils", Manager: "apt", Status: util.FileModified},
+		},
+	}
+	integrity2 := packageIntegrity{
+		failures: []util.FileIntegrity{
+			{Path: "/etc/hosts.conf", Package: "base-files", Manager: "apt", Status: util.ConfigModified},
+			{Path: "/usr/bin/bash", Package: "bash", Manager: "apt", Status: util.FileModified},
+			{Path: "/usr/bin/ls", Package: "coreutils", Manager: "apt", Status: util.FileReplaced},
+		},
+		checked: map[integrityKey]bool{
+			{"apt", "curl", "/usr/bin/curl"}:    true,
+			{"apt", "bash", "/usr/bin/bash"}:    true,
+			{"apt", "coreutils", "/usr/bin/ls"}: true,
+		},
+	}
+	expected := util.IntegrityDiff{
+		Regressions: []util.FileIntegrity{
+			{Path: "/usr/bin/bash", Package: "bash", Manager: "apt", Status: util.FileModified},
+			{Path: "/usr/bin/ls", Package: "coreutils", Manager: "apt", Status: util.FileReplaced},
+		},
+		// wget was removed, so isn't resolved
+		Resolved: []util.FileIntegrity{
+			{Path: "/usr/bin/curl", Package: "curl", Manager: "apt", Status: util.FileModified},
+		},
+	}
+	if diff := diffPackageIntegrity(integrity1, integrity2); !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, diff)
+	}
+}
+
+func TestParseRPMDigests(t *testing.T) {
+	output := []string{
+		"bash\t/etc/skel/.bashrc\t" + md5Hex("# skel") + "\tc\tnormal\t1",
+		"bash\t/usr/bin/bash\tabc123\t\tnormal\t8",
+		"bash\t/usr/share/doc/bash\t\td\tnormal\t8",
+		"bash\t/usr/share/doc/bash/README\tdef456\td\tnot installed\t8",
+		"legacy\t/usr/bin/legacy\tfff\t\tnormal\t(none)",
+		"",
+	}
+	expected := []packageFile{
+		{pkg: "bash", path: "/etc/skel/.bashrc", digest: "md5:" + md5Hex("# skel"), config: true},
+		{pkg: "bash", path: "/usr/bin/bash", digest: "sha256:abc123"},
+		{pkg: "legacy", path: "/usr/bin/legacy", digest: "md5:fff"},
+	}
+	if files := parseRPMDigests(output); !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, files)
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	testCases := []struct {
+		glob    string
+		path    string
+		matches bool
+	}{
+		{"/usr/share/doc/*", "/usr/share/doc/bash/README", true},
+		{"/usr/share/doc/*/copyright", "/usr/share/doc/bash/copyright", true},
+		{"/usr/share/man/*", "/usr/share/manual", false},
+		{"/usr/share/locale/[!e]*", "/usr/share/locale/de/LC_MESSAGES/bash.mo", true},
+		{"/usr/share/locale/[!e]*", "/usr/share/locale/en/LC_MESSAGES/bash.mo", false},
+		{"/usr/lib/?.so", "/usr/lib/a.so", true},
+	}
+	for _, test := range testCases {
+		pattern, err := globRegexp(test.glob)
+		if err != nil {
+			t.Fatalf("Got unexpected error for %s: %s", test.glob, err)
+		}
+		if matches := pattern.MatchString(test.path); matches != test.matches {
+			t.Errorf("%s matching %s: expected %t but got %t", test.glob, test.path, test.matches, matches)
+		}
+	}
+}
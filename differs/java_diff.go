@@ -0,0 +1,316 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/sirupsen/logrus"
+)
+
+// jdkPackage is the package name under which JDK and JRE installations are reported
+const jdkPackage = "jdk"
+
+// maxJavaArchiveDepth is how deep archives nested in archives are read, e.g. 2
+// for the jars of a war packaged in an ear.
+const maxJavaArchiveDepth = 3
+
+// maxNestedJavaArchiveSize is the uncompressed size above which archives nested
+// in archives, which are read in memory, are skipped.
+var maxNestedJavaArchiveSize int64 = 128 << 20
+
+type JavaAnalyzer struct {
+}
+
+func (a JavaAnalyzer) Name() string {
+	return "JavaAnalyzer"
+}
+
+// JavaDiff compares the Java artifacts packaged in the archives of two images.
+func (a JavaAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := multiVersionDiff(image1, image2, a)
+	return diff, err
+}
+
+func (a JavaAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := multiVersionAnalysis(image, a)
+	return analysis, err
+}
+
+// getPackages returns the Java artifacts of the image keyed by groupId:artifactId and
+// archive location, along with the JDK installations keyed by their home directory.
+func (a JavaAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]util.PackageInfo, error) {
+	root := filepath.Clean(image.FSPath)
+	packages := make(map[string]map[string]util.PackageInfo)
+	if _, err := os.Stat(root); err != nil {
+		// path provided invalid
+		return packages, err
+	}
+
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			logrus.Debugf("Unable to read %s: %s", file, err)
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		mapPath := "/" + strings.TrimPrefix(strings.TrimPrefix(file, root), "/")
+		if info.Name() == "release" {
+			if jdk, ok := readJDKRelease(file); ok {
+				jdk.Size = pkgutil.GetSize(filepath.Dir(file))
+				addToMap(packages, jdkPackage, filepath.Dir(mapPath), jdk)
+			}
+			return nil
+		}
+		if !isJavaArchive(file) {
+			return nil
+		}
+		archive, err := zip.OpenReader(file)
+		if err != nil {
+			logrus.Warningf("Error opening Java archive %s: %s", file, err)
+			return nil
+		}
+		defer archive.Close()
+		for _, artifact := range readJavaArchive(&archive.Reader, mapPath, info.Size(), 0) {
+			addToMap(packages, artifact.name, artifact.location, artifact.info)
+		}
+		return nil
+	})
+	return packages, err
+}
+
+type javaArtifact struct {
+	name     string
+	location string
+	info     util.PackageInfo
+}
+
+func isJavaArchive(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jar", ".war", ".ear":
+		return true
+	}
+	return false
+}
+
+// readJavaArchive returns the artifacts packaged in the archive at location, and
+// in the archives nested in it, such as BOOT-INF/lib or WEB-INF/lib jars, up to
+// maxJavaArchiveDepth levels down. depth is the nesting level of the archive.
+// Nested archives are located as outer.jar!/BOOT-INF/lib/inner.jar.
+func readJavaArchive(archive *zip.Reader, location string, size int64, depth int) []javaArtifact {
+	var artifacts []javaArtifact
+	var manifest *zip.File
+	for _, f := range archive.File {
+		switch {
+		case path.Base(f.Name) == "pom.properties" && strings.HasPrefix(f.Name, "META-INF/maven/"):
+			if artifact, ok := readPomProperties(f); ok {
+				artifacts = append(artifacts, javaArtifact{
+					name:     artifact.name,
+					location: location,
+					info:     util.PackageInfo{Version: artifact.info.Version, Size: size},
+				})
+			}
+		case f.Name == "META-INF/MANIFEST.MF":
+			manifest = f
+		}
+	}
+
+	if len(artifacts) == 0 {
+		artifact := javaArtifactFromManifest(manifest, location)
+		artifact.info.Size = size
+		artifacts = append(artifacts, artifact)
+	}
+
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() || !isJavaArchive(f.Name) {
+			continue
+		}
+		if depth >= maxJavaArchiveDepth {
+			logrus.Debugf("Skipping nested archive %s in %s: nested more than %d levels deep", f.Name, location, maxJavaArchiveDepth)
+			continue
+		}
+		if f.UncompressedSize64 > uint64(maxNestedJavaArchiveSize) {
+			logrus.Warningf("Skipping nested archive %s in %s: larger than %d bytes", f.Name, location, maxNestedJavaArchiveSize)
+			continue
+		}
+		nested, err := readZipEntry(f)
+		if err != nil {
+			logrus.Warningf("Error reading nested archive %s in %s: %s", f.Name, location, err)
+			continue
+		}
+		nestedArchive, err := zip.NewReader(bytes.NewReader(nested), int64(len(nested)))
+		if err != nil {
+			logrus.Warningf("Error opening nested archive %s in %s: %s", f.Name, location, err)
+			continue
+		}
+		artifacts = append(artifacts, readJavaArchive(nestedArchive, location+"!/"+f.Name, int64(len(nested)), depth+1)...)
+	}
+	return artifacts
+}
+
+// readZipEntry reads a nested archive, up to maxNestedJavaArchiveSize bytes
+// whatever the size recorded in the zip headers.
+func readZipEntry(f *zip.File) ([]byte, error) {
+	reader, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(reader, maxNestedJavaArchiveSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxNestedJavaArchiveSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxNestedJavaArchiveSize)
+	}
+	return content, nil
+}
+
+// readPomProperties identifies an artifact from the pom.properties Maven packages into each jar.
+func readPomProperties(f *zip.File) (javaArtifact, bool) {
+	reader, err := f.Open()
+	if err != nil {
+		return javaArtifact{}, false
+	}
+	defer reader.Close()
+	props := readProperties(reader, "=")
+	if props["artifactId"] == "" || props["version"] == "" {
+		return javaArtifact{}, false
+	}
+	return javaArtifact{
+		name: props["groupId"] + ":" + props["artifactId"],
+		info: util.PackageInfo{Version: props["version"]},
+	}, true
+}
+
+var javaArchiveNameRegex = regexp.MustCompile(`^(.+?)-([0-9][^-]*(?:-[A-Za-z0-9.]+)*)$`)
+
+// javaArtifactFromManifest identifies an artifact without Maven metadata from its
+// manifest, falling back to the archive name, e.g. name-1.2.3.jar.
+func javaArtifactFromManifest(manifest *zip.File, location string) javaArtifact {
+	var attributes map[string]string
+	if manifest != nil {
+		if reader, err := manifest.Open(); err == nil {
+			attributes = readManifest(reader)
+			reader.Close()
+		}
+	}
+	name := firstNonEmpty(attributes["Bundle-SymbolicName"], attributes["Implementation-Title"], attributes["Specification-Title"])
+	version := firstNonEmpty(attributes["Bundle-Version"], attributes["Implementation-Version"], attributes["Specification-Version"])
+	// Bundle-SymbolicName may carry directives, e.g. name;singleton:=true
+	name = strings.TrimSpace(strings.Split(name, ";")[0])
+
+	archiveName := strings.TrimSuffix(path.Base(location), path.Ext(location))
+	if match := javaArchiveNameRegex.FindStringSubmatch(archiveName); match != nil {
+		name = firstNonEmpty(name, match[1])
+		version = firstNonEmpty(version, match[2])
+	}
+	name = firstNonEmpty(name, archiveName)
+	if group := attributes["Implementation-Vendor-Id"]; group != "" && !strings.Contains(name, ":") {
+		name = group + ":" + name
+	}
+	return javaArtifact{name: name, location: location, info: util.PackageInfo{Version: version}}
+}
+
+// readProperties reads the key/value pairs of a properties file.
+func readProperties(reader io.Reader, separator string) map[string]string {
+	props := make(map[string]string)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, separator, 2)
+		if len(parts) == 2 {
+			props[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return props
+}
+
+// readManifest reads the main attributes of a jar manifest. Lines are wrapped at
+// 72 bytes, the following lines starting with a single space.
+func readManifest(reader io.Reader) map[string]string {
+	attributes := make(map[string]string)
+	var key string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.HasPrefix(line, " ") {
+			if key != "" {
+				attributes[key] += line[1:]
+			}
+			continue
+		}
+		if line == "" {
+			// the main section ends at the first blank line, before the per-entry sections
+			break
+		}
+		key = ""
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 {
+			key = strings.TrimSpace(parts[0])
+			attributes[key] = strings.TrimPrefix(parts[1], " ")
+		}
+	}
+	for key, value := range attributes {
+		attributes[key] = strings.TrimSpace(value)
+	}
+	return attributes
+}
+
+// readJDKRelease reads the release file at the root of JDK and JRE installations.
+func readJDKRelease(file string) (util.PackageInfo, bool) {
+	releaseFile, err := os.Open(file)
+	if err != nil {
+		return util.PackageInfo{}, false
+	}
+	defer releaseFile.Close()
+	props := readProperties(releaseFile, "=")
+	version := strings.Trim(props["JAVA_VERSION"], `"`)
+	if version == "" {
+		return util.PackageInfo{}, false
+	}
+	return util.PackageInfo{
+		Version:    version,
+		Arch:       strings.Trim(props["OS_ARCH"], `"`),
+		Repository: strings.Trim(props["IMPLEMENTOR"], `"`),
+	}, true
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
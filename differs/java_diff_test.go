@@ -0,0 +1,153 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+// buildJar returns a zip archive holding the given files.
+func buildJar(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Unable to create %s: %s", name, err)
+		}
+		f.Write(content)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unable to build jar: %s", err)
+	}
+	return buf.Bytes()
+}
+
+func TestGetJavaPackages(t *testing.T) {
+	root, err := ioutil.TempDir("", "java-analyzer")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	springCore := buildJar(t, map[string][]byte{
+		"META-INF/maven/org.springframework/spring-core/pom.properties": []byte("groupId=org.springframework\nartifactId=spring-core\nversion=5.3.23\n"),
+	})
+	guava := buildJar(t, map[string][]byte{
+		"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\nBundle-SymbolicName: com.google.guava\nBundle-Version: 31.1.0.jre\n"),
+	})
+	app := buildJar(t, map[string][]byte{
+		"META-INF/maven/com.example/app/pom.properties": []byte("#Generated by Maven\ngroupId=com.example\nartifactId=app\nversion=1.0.0\n"),
+		"BOOT-INF/lib/spring-core-5.3.23.jar":           springCore,
+	})
+	files := map[string][]byte{
+		"app/app.jar":                                 app,
+		"opt/lib/guava-31.1-jre.jar":                  guava,
+		"opt/lib/commons-lang3-3.12.0.jar":            buildJar(t, map[string][]byte{}),
+		"opt/java/openjdk/release":                    []byte("IMPLEMENTOR=\"Eclipse Adoptium\"\nJAVA_VERSION=\"17.0.5\"\nOS_ARCH=\"x86_64\"\n"),
+		"opt/java/openjdk/bin/java":                   []byte("java"),
+		"usr/share/doc/release":                       []byte("not a JDK"),
+		"app/config/application.properties.jar.notes": []byte("notes"),
+	}
+	for name, content := range files {
+		file := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := ioutil.WriteFile(file, content, 0644); err != nil {
+			t.Fatalf("Unable to write %s: %s", file, err)
+		}
+	}
+
+	expected := map[string]map[string]util.PackageInfo{
+		"com.example:app": {"/app/app.jar": {Version: "1.0.0", Size: int64(len(app))}},
+		"org.springframework:spring-core": {
+			"/app/app.jar!/BOOT-INF/lib/spring-core-5.3.23.jar": {Version: "5.3.23", Size: int64(len(springCore))},
+		},
+		"com.google.guava": {"/opt/lib/guava-31.1-jre.jar": {Version: "31.1.0.jre", Size: int64(len(guava))}},
+		"commons-lang3":    {"/opt/lib/commons-lang3-3.12.0.jar": {Version: "3.12.0", Size: int64(len(files["opt/lib/commons-lang3-3.12.0.jar"]))}},
+		"jdk": {"/opt/java/openjdk": {Version: "17.0.5", Size: int64(len(files["opt/java/openjdk/release"]) + len(files["opt/java/openjdk/bin/java"])),
+			Arch: "x86_64", Repository: "Eclipse Adoptium"}},
+	}
+
+	packages, err := JavaAnalyzer{}.getPackages(pkgutil.Image{FSPath: root})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	manifest := "Manifest-Version: 1.0\r\n" +
+		"Implementation-Title: a-library-with-a-long-name-wrapped-at-seventy-two-b\r\n" +
+		" ytes\r\n" +
+		"Implementation-Version: 2.4.1\r\n" +
+		"Bundle-Description: two words\r\n" +
+		"  wrapped on a space\r\n" +
+		"\r\n" +
+		"Name: org/example/\r\n" +
+		"Implementation-Version: 9.9.9\r\n"
+	expected := map[string]string{
+		"Manifest-Version":       "1.0",
+		"Implementation-Title":   "a-library-with-a-long-name-wrapped-at-seventy-two-bytes",
+		"Implementation-Version": "2.4.1",
+		"Bundle-Description":     "two words wrapped on a space",
+	}
+	if attributes := readManifest(strings.NewReader(manifest)); !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, attributes)
+	}
+}
+
+func TestReadNestedJavaArchives(t *testing.T) {
+	archive := func(name string, nested map[string][]byte) []byte {
+		nested["META-INF/maven/com.example/"+name+"/pom.properties"] = []byte("groupId=com.example\nartifactId=" + name + "\nversion=1.0\n")
+		return buildJar(t, nested)
+	}
+	level3 := archive("level3", map[string][]byte{})
+	level2 := archive("level2", map[string][]byte{"lib/level3.jar": level3})
+	level1 := archive("level1", map[string][]byte{"lib/level2.jar": level2})
+	// random data, which doesn't compress, makes large.jar larger than level1.jar
+	data := make([]byte, 2*len(level1))
+	rand.New(rand.NewSource(1)).Read(data)
+	level0 := archive("level0", map[string][]byte{"lib/level1.jar": level1, "lib/large.jar": archive("large", map[string][]byte{"data": data})})
+
+	defer func(size int64) { maxNestedJavaArchiveSize = size }(maxNestedJavaArchiveSize)
+	maxNestedJavaArchiveSize = int64(len(level1))
+	reader, err := zip.NewReader(bytes.NewReader(level0), int64(len(level0)))
+	if err != nil {
+		t.Fatalf("Unable to open archive: %s", err)
+	}
+	var names []string
+	for _, artifact := range readJavaArchive(reader, "/app.ear", int64(len(level0)), maxJavaArchiveDepth-2) {
+		names = append(names, artifact.name)
+	}
+	expected := []string{"com.example:level0", "com.example:level1", "com.example:level2"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, names)
+	}
+}
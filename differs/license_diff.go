@@ -0,0 +1,185 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+// Debian package documentation location, holding the copyright file of each package
+const dpkgDocDir string = "/usr/share/doc"
+
+// commonLicenseRef matches references to the licenses shipped by Debian in
+// /usr/share/common-licenses, used by copyright files predating DEP-5.
+var commonLicenseRef = regexp.MustCompile(`/usr/share/common-licenses/([A-Za-z0-9.+-]*[A-Za-z0-9+])`)
+
+type LicenseAnalyzer struct {
+}
+
+func (a LicenseAnalyzer) Name() string {
+	return "LicenseAnalyzer"
+}
+
+// LicenseDiff reports the packages whose license was added, removed or changed between two images.
+func (a LicenseAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	licenses1, err := getLicenses(image1)
+	if err != nil {
+		return &util.LicenseDiffResult{}, err
+	}
+	licenses2, err := getLicenses(image2)
+	if err != nil {
+		return &util.LicenseDiffResult{}, err
+	}
+	return &util.LicenseDiffResult{
+		Image1:   image1.Source,
+		Image2:   image2.Source,
+		DiffType: "License",
+		Diff:     util.GetLicenseDiff(licenses1, licenses2),
+	}, nil
+}
+
+func (a LicenseAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	licenses, err := getLicenses(image)
+	if err != nil {
+		return &util.LicenseAnalyzeResult{}, err
+	}
+	return &util.LicenseAnalyzeResult{
+		Image:       image.Source,
+		AnalyzeType: "License",
+		Analysis:    licenses,
+	}, nil
+}
+
+// getLicenses returns the normalized license of every apt, rpm, pip and node package of the image.
+func getLicenses(image pkgutil.Image) ([]util.PackageLicense, error) {
+	licenses := []util.PackageLicense{}
+	if _, err := os.Stat(image.FSPath); err != nil {
+		// invalid image directory path
+		return licenses, err
+	}
+
+	aptPackages, err := AptAnalyzer{}.getPackages(image)
+	if err != nil {
+		return licenses, err
+	}
+	for name, info := range aptPackages {
+		licenses = append(licenses, util.PackageLicense{
+			Package: name,
+			Manager: "apt",
+			// restore the '+' replaced by parseLine
+			Version: strings.Replace(info.Version, " ", "+", 1),
+			License: info.License,
+		})
+	}
+
+	rpmPackages := make(map[string]util.PackageInfo)
+	if hasRPMBinary(image.FSPath) {
+		if rpmPackages, err = (RPMAnalyzer{}).getPackages(image); err != nil {
+			return licenses, err
+		}
+	}
+	for name, info := range rpmPackages {
+		licenses = append(licenses, util.PackageLicense{
+			Package: name,
+			Manager: "rpm",
+			Version: info.Version,
+			License: util.NormalizeLicense(info.License),
+		})
+	}
+
+	pipPackages, err := PipAnalyzer{}.getPackages(image)
+	if err != nil {
+		return licenses, err
+	}
+	licenses = appendLicenses(licenses, "pip", pipPackages)
+
+	nodePackages, err := NodeAnalyzer{}.getPackages(image)
+	if err != nil {
+		return licenses, err
+	}
+	return appendLicenses(licenses, "node", nodePackages), nil
+}
+
+// appendLicenses adds the normalized licenses declared by packages keyed by name and
+// installation path.
+func appendLicenses(licenses []util.PackageLicense, manager string, packages map[string]map[string]util.PackageInfo) []util.PackageLicense {
+	for name, paths := range packages {
+		for path, info := range paths {
+			licenses = append(licenses, util.PackageLicense{
+				Package: name,
+				Manager: manager,
+				Path:    path,
+				Version: info.Version,
+				License: util.NormalizeLicense(info.License),
+			})
+		}
+	}
+	return licenses
+}
+
+// getDpkgLicense returns the license of a Debian package from its copyright file.
+// Machine-readable copyright files (DEP-5) list a License field per group of files;
+// older ones are searched for references to /usr/share/common-licenses.
+// All the distinct licenses found apply to the package.
+func getDpkgLicense(root, pkg string) string {
+	copyright := filepath.Join(resolveInRoot(root, filepath.Join(dpkgDocDir, pkg)), "copyright")
+	file, err := os.Open(copyright)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	var dep5, referenced []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "License:") {
+			if name := strings.TrimSpace(strings.TrimPrefix(line, "License:")); name != "" {
+				dep5 = append(dep5, util.NormalizeLicense(name))
+			}
+			continue
+		}
+		for _, m := range commonLicenseRef.FindAllStringSubmatch(line, -1) {
+			referenced = append(referenced, util.NormalizeLicense(m[1]))
+		}
+	}
+	if len(dep5) > 0 {
+		return util.CombineLicenses(dep5)
+	}
+	return util.CombineLicenses(referenced)
+}
+
+// resolveInRoot follows path, relative to the image root, if it is a symbolic
+// link, keeping absolute link targets within the image root.
+func resolveInRoot(root, path string) string {
+	target, err := os.Readlink(filepath.Join(root, path))
+	if err != nil {
+		// not a link
+		return filepath.Join(root, path)
+	}
+	if filepath.IsAbs(target) {
+		return filepath.Join(root, target)
+	}
+	return filepath.Join(root, filepath.Dir(path), target)
+}
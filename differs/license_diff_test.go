@@ -0,0 +1,87 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestGetLicenses(t *testing.T) {
+	root, err := ioutil.TempDir("", "license-analyzer")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"var/lib/dpkg/status": "Package: bash\nVersion: 5.2.15-2+b2\n\nPackage: zlib1g\nVersion: 1:1.2.13.dfsg-1\n\n" +
+			"Package: libc6-dev\nVersion: 2.36-9\n\nPackage: nodoc\nVersion: 1.0\n",
+		"usr/share/doc/bash/copyright": "Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/\n\n" +
+			"Files: *\nCopyright: 1987-2022 Free Software Foundation, Inc.\nLicense: GPL-3+\n\n" +
+			"Files: lib/readline/*\nLicense: GPL-3+\n\nFiles: examples/loadables/*\nLicense: BSD-3-clause or Expat\n\n" +
+			"License: GPL-3+\n This program is free software...\n",
+		"usr/share/doc/zlib1g/copyright": "This is the Debian prepackaged version of zlib.\n\n" +
+			"The license is in /usr/share/common-licenses/Zlib.\n",
+		"usr/share/doc/libc6/copyright": "License: LGPL-2.1+\n",
+		"usr/lib/python3/dist-packages/requests-2.31.0.dist-info/METADATA": "Metadata-Version: 2.1\nName: requests\nVersion: 2.31.0\n" +
+			"Classifier: License :: OSI Approved :: Apache Software License\n",
+		"usr/lib/python3/dist-packages/requests-2.31.0.dist-info/top_level.txt": "requests\n",
+		"usr/lib/python3/dist-packages/requests/__init__.py":                    "",
+		"app/node_modules/left-pad/package.json":                                `{"name": "left-pad", "version": "1.3.0", "license": "WTFPL"}`,
+	}
+	for name, content := range files {
+		file := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write %s: %s", file, err)
+		}
+	}
+	// development packages often link their documentation to that of the library
+	if err := os.Symlink("libc6", filepath.Join(root, "usr/share/doc/libc6-dev")); err != nil {
+		t.Fatalf("Unable to link documentation: %s", err)
+	}
+
+	expected := []util.PackageLicense{
+		{Package: "bash", Manager: "apt", Version: "5.2.15-2+b2", License: "GPL-3.0-or-later AND (BSD-3-Clause OR MIT)"},
+		{Package: "libc6-dev", Manager: "apt", Version: "2.36-9", License: "LGPL-2.1-or-later"},
+		{Package: "nodoc", Manager: "apt", Version: "1.0"},
+		{Package: "zlib1g", Manager: "apt", Version: "1:1.2.13.dfsg-1", License: "Zlib"},
+		{Package: "left-pad", Manager: "node", Path: "/app/node_modules/left-pad/", Version: "1.3.0", License: "WTFPL"},
+		{Package: "requests", Manager: "pip", Path: "/usr/lib/python3/dist-packages", Version: "2.31.0", License: "Apache-2.0"},
+	}
+
+	licenses, err := getLicenses(pkgutil.Image{FSPath: root, Image: &pkgutil.TestImage{Config: &v1.ConfigFile{}}})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	util.SortPackageLicenses(licenses)
+	if !reflect.DeepEqual(licenses, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, licenses)
+	}
+
+	if _, err := getLicenses(pkgutil.Image{FSPath: "testDirs/notThere"}); err == nil {
+		t.Errorf("Expected error for missing path")
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+type ManifestAnalyzer struct {
+}
+
+func (a ManifestAnalyzer) Name() string {
+	return "ManifestAnalyzer"
+}
+
+// Diff compares the manifests of two images field by field and their layers by index.
+func (a ManifestAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	m1, err := getManifest(image1)
+	if err != nil {
+		return &util.ManifestDiffResult{}, err
+	}
+	m2, err := getManifest(image2)
+	if err != nil {
+		return &util.ManifestDiffResult{}, err
+	}
+	return &util.ManifestDiffResult{
+		Image1:   image1.Source,
+		Image2:   image2.Source,
+		DiffType: "Manifest",
+		Diff:     util.GetManifestDiff(m1, m2),
+	}, nil
+}
+
+func (a ManifestAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := getManifest(image)
+	if err != nil {
+		return &util.ManifestAnalyzeResult{}, err
+	}
+	return &util.ManifestAnalyzeResult{
+		Image:       image.Source,
+		AnalyzeType: "Manifest",
+		Analysis:    analysis,
+	}, nil
+}
+
+// getManifest returns the manifest of the image, with the diff ID of each
+// layer from the image config.
+func getManifest(image pkgutil.Image) (util.ImageManifest, error) {
+	manifest, err := image.Image.Manifest()
+	if err != nil {
+		return util.ImageManifest{}, err
+	}
+	digest, err := image.Image.Digest()
+	if err != nil {
+		return util.ImageManifest{}, err
+	}
+	// the media type is optional in OCI manifests
+	mediaType, err := image.Image.MediaType()
+	if err != nil {
+		return util.ImageManifest{}, err
+	}
+	configFile, err := image.Image.ConfigFile()
+	if err != nil {
+		return util.ImageManifest{}, err
+	}
+	diffIDs := configFile.RootFS.DiffIDs
+
+	annotations := manifest.Annotations
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	result := util.ImageManifest{
+		Digest:        digest.String(),
+		MediaType:     string(mediaType),
+		SchemaVersion: manifest.SchemaVersion,
+		Config: util.ManifestDescriptor{
+			MediaType: string(manifest.Config.MediaType),
+			Digest:    manifest.Config.Digest.String(),
+			Size:      manifest.Config.Size,
+		},
+		Layers:      []util.LayerDescriptor{},
+		Annotations: annotations,
+	}
+	if manifest.Subject != nil {
+		result.Subject = manifest.Subject.Digest.String()
+	}
+	for i, layer := range manifest.Layers {
+		descriptor := util.LayerDescriptor{
+			MediaType:   string(layer.MediaType),
+			Compression: util.LayerCompression(string(layer.MediaType)),
+			Digest:      layer.Digest.String(),
+			Size:        layer.Size,
+			URLs:        layer.URLs,
+			Annotations: layer.Annotations,
+		}
+		if i < len(diffIDs) {
+			descriptor.DiffID = diffIDs[i].String()
+		}
+		result.Layers = append(result.Layers, descriptor)
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestGetManifest(t *testing.T) {
+	layer, err := random.Layer(1024, types.OCILayerZStd)
+	if err != nil {
+		t.Fatalf("Unable to create layer: %s", err)
+	}
+	image, err := mutate.AppendLayers(mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), types.OCIConfigJSON), layer)
+	if err != nil {
+		t.Fatalf("Unable to create image: %s", err)
+	}
+	image = mutate.Annotations(image, map[string]string{"org.opencontainers.image.version": "1.2.3"}).(v1.Image)
+
+	manifest, err := getManifest(pkgutil.Image{Image: image})
+	if err != nil {
+		t.Fatalf("Error reading manifest: %s", err)
+	}
+	digest, _ := layer.Digest()
+	diffID, _ := layer.DiffID()
+	size, _ := layer.Size()
+	expected := []util.LayerDescriptor{{
+		MediaType:   string(types.OCILayerZStd),
+		Compression: "zstd",
+		Digest:      digest.String(),
+		DiffID:      diffID.String(),
+		Size:        size,
+	}}
+	if !reflect.DeepEqual(manifest.Layers, expected) {
+		t.Errorf("Expected layers: %v but got: %v", expected, manifest.Layers)
+	}
+	if manifest.MediaType != string(types.OCIManifestSchema1) || manifest.SchemaVersion != 2 {
+		t.Errorf("Expected an OCI manifest but got %s version %d", manifest.MediaType, manifest.SchemaVersion)
+	}
+	if !reflect.DeepEqual(manifest.Annotations, map[string]string{"org.opencontainers.image.version": "1.2.3"}) {
+		t.Errorf("Unexpected annotations: %v", manifest.Annotations)
+	}
+	if manifest.Config.MediaType != string(types.OCIConfigJSON) {
+		t.Errorf("Expected an OCI config but got %s", manifest.Config.MediaType)
+	}
+}
+
+func TestGetMetadata(t *testing.T) {
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	image, err := mutate.ConfigFile(empty.Image, &v1.ConfigFile{
+		Architecture: "arm",
+		OS:           "linux",
+		Variant:      "v7",
+		Created:      v1.Time{Time: created},
+		Author:       "team@example.com",
+		RootFS:       v1.RootFS{Type: "layers", DiffIDs: []v1.Hash{{Algorithm: "sha256", Hex: "aa"}}},
+		Config: v1.Config{
+			Env:          []string{"PATH=/usr/bin", "EMPTY"},
+			ExposedPorts: map[string]struct{}{"8443/tcp": {}, "8080/tcp": {}},
+			Cmd:          []string{"python", "app.py"},
+			Healthcheck:  &v1.HealthConfig{Test: []string{"CMD-SHELL", "curl -f http://localhost/"}, Interval: 30 * time.Second, Retries: 3},
+			MacAddress:   "02:42:ac:11:00:02",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unable to create image: %s", err)
+	}
+	configDigest, _ := image.ConfigName()
+
+	metadata, err := getMetadata(pkgutil.Image{Image: image})
+	if err != nil {
+		t.Fatalf("Error reading metadata: %s", err)
+	}
+	expected := util.ImageMetadata{
+		Architecture: "arm",
+		OS:           "linux",
+		Variant:      "v7",
+		Created:      "2024-01-15T10:30:00Z",
+		Author:       "team@example.com",
+		ConfigDigest: configDigest.String(),
+		ExposedPorts: []string{"8080/tcp", "8443/tcp"},
+		Env:          map[string]string{"PATH": "/usr/bin", "EMPTY": ""},
+		Cmd:          []string{"python", "app.py"},
+		Healthcheck:  &util.Healthcheck{Test: []string{"CMD-SHELL", "curl -f http://localhost/"}, Interval: 30 * time.Second, Retries: 3},
+		Volumes:      []string{},
+		Labels:       map[string]string{},
+		DiffIDs:      []string{"sha256:aa"},
+	}
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, metadata)
+	}
+	if healthcheck := metadata.Healthcheck.String(); healthcheck != "CMD-SHELL curl -f http://localhost/ (interval 30s, retries 3)" {
+		t.Errorf("Unexpected healthcheck: %s", healthcheck)
+	}
+}
@@ -17,8 +17,9 @@ limitations under the License.
 package differs
 
 import (
-	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
 	"github.com/GoogleContainerTools/container-diff/util"
@@ -27,15 +28,11 @@ import (
 type MetadataAnalyzer struct {
 }
 
-type MetadataDiff struct {
-	Adds []string
-	Dels []string
-}
-
 func (a MetadataAnalyzer) Name() string {
 	return "MetadataAnalyzer"
 }
 
+// Diff compares the metadata of two images field by field.
 func (a MetadataAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
 	diff, err := getMetadataDiff(image1, image2)
 	return &util.MetadataDiffResult{
@@ -47,70 +44,104 @@ func (a MetadataAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error
 }
 
 func (a MetadataAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
-	analysis, err := getMetadataList(image)
+	analysis, err := getMetadata(image)
 	if err != nil {
-		return &util.ListAnalyzeResult{}, err
+		return &util.MetadataAnalyzeResult{}, err
 	}
-	return &util.ListAnalyzeResult{
+	return &util.MetadataAnalyzeResult{
 		Image:       image.Source,
 		AnalyzeType: "Metadata",
 		Analysis:    analysis,
 	}, nil
 }
 
-func getMetadataDiff(image1, image2 pkgutil.Image) (MetadataDiff, error) {
-	m1, err := getMetadataList(image1)
+func getMetadataDiff(image1, image2 pkgutil.Image) (util.MetadataDiff, error) {
+	m1, err := getMetadata(image1)
 	if err != nil {
-		return MetadataDiff{}, err
+		return util.MetadataDiff{}, err
 	}
-	m2, err := getMetadataList(image2)
+	m2, err := getMetadata(image2)
 	if err != nil {
-		return MetadataDiff{}, err
+		return util.MetadataDiff{}, err
 	}
-
-	adds := util.GetAdditions(m1, m2)
-	dels := util.GetDeletions(m1, m2)
-	return MetadataDiff{adds, dels}, nil
+	return util.GetMetadataDiff(m1, m2), nil
 }
 
-func getMetadataList(image pkgutil.Image) ([]string, error) {
+func getMetadata(image pkgutil.Image) (util.ImageMetadata, error) {
 	configFile, err := image.Image.ConfigFile()
 	if err != nil {
-		return nil, err
+		return util.ImageMetadata{}, err
+	}
+	configDigest, err := image.Image.ConfigName()
+	if err != nil {
+		return util.ImageMetadata{}, err
 	}
 	c := configFile.Config
+	labels := c.Labels
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	var created string
+	if !configFile.Created.IsZero() {
+		created = configFile.Created.UTC().Format(time.RFC3339)
+	}
+	var healthcheck *util.Healthcheck
+	if h := c.Healthcheck; h != nil {
+		healthcheck = &util.Healthcheck{Test: h.Test, Interval: h.Interval, Timeout: h.Timeout, StartPeriod: h.StartPeriod, Retries: h.Retries}
+	}
+	diffIDs := []string{}
+	for _, diffID := range configFile.RootFS.DiffIDs {
+		diffIDs = append(diffIDs, diffID.String())
+	}
 
-	return []string{
-		fmt.Sprintf("Domainname: %s", c.Domainname),
-		fmt.Sprintf("User: %s", c.User),
-		fmt.Sprintf("AttachStdin: %t", c.AttachStdin),
-		fmt.Sprintf("AttachStdout: %t", c.AttachStdout),
-		fmt.Sprintf("AttachStderr: %t", c.AttachStderr),
-		fmt.Sprintf("ExposedPorts: %v", pkgutil.SortMap(StructMapToStringMap(c.ExposedPorts))),
-		fmt.Sprintf("Tty: %t", c.Tty),
-		fmt.Sprintf("OpenStdin: %t", c.OpenStdin),
-		fmt.Sprintf("StdinOnce: %t", c.StdinOnce),
-		fmt.Sprintf("Env: %s", strings.Join(c.Env, ",")),
-		fmt.Sprintf("Cmd: %s", strings.Join(c.Cmd, ",")),
-		fmt.Sprintf("ArgsEscaped: %t", c.ArgsEscaped),
-		fmt.Sprintf("Volumes: %v", pkgutil.SortMap(StructMapToStringMap(c.Volumes))),
-		fmt.Sprintf("Workdir: %s", c.WorkingDir),
-		fmt.Sprintf("Entrypoint: %s", strings.Join(c.Entrypoint, ",")),
-		fmt.Sprintf("NetworkDisabled: %t", c.NetworkDisabled),
-		fmt.Sprintf("MacAddress: %s", c.MacAddress),
-		fmt.Sprintf("OnBuild: %s", strings.Join(c.OnBuild, ",")),
-		fmt.Sprintf("Labels: %v", pkgutil.SortMap(c.Labels)),
-		fmt.Sprintf("StopSignal: %s", c.StopSignal),
-		fmt.Sprintf("Shell: %s", strings.Join(c.Shell, ",")),
+	return util.ImageMetadata{
+		Architecture:  configFile.Architecture,
+		OS:            configFile.OS,
+		OSVersion:     configFile.OSVersion,
+		OSFeatures:    configFile.OSFeatures,
+		Variant:       configFile.Variant,
+		Created:       created,
+		Author:        configFile.Author,
+		DockerVersion: configFile.DockerVersion,
+		ConfigDigest:  configDigest.String(),
+		User:          c.User,
+		ExposedPorts:  sortedSetKeys(c.ExposedPorts),
+		Env:           envMap(c.Env),
+		Entrypoint:    c.Entrypoint,
+		Cmd:           c.Cmd,
+		Healthcheck:   healthcheck,
+		ArgsEscaped:   c.ArgsEscaped,
+		Volumes:       sortedSetKeys(c.Volumes),
+		WorkingDir:    c.WorkingDir,
+		Labels:        labels,
+		StopSignal:    c.StopSignal,
+		Shell:         c.Shell,
+		OnBuild:       c.OnBuild,
+		DiffIDs:       diffIDs,
 	}, nil
 }
 
-// StructMapToStringMap converts map[string]struct{} to map[string]string knowing that the
-// struct in the value is always empty
-func StructMapToStringMap(m map[string]struct{}) map[string]string {
-	newMap := make(map[string]string)
+// sortedSetKeys returns the sorted keys of a set such as the exposed ports of
+// an image config.
+func sortedSetKeys(m map[string]struct{}) []string {
+	keys := []string{}
 	for k := range m {
-		newMap[k] = fmt.Sprintf("%s", m[k])
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// envMap maps environment variables, given as KEY=VALUE, to their value. Later
+// definitions of a variable override earlier ones, as for the container process.
+func envMap(env []string) map[string]string {
+	vars := make(map[string]string)
+	for _, v := range env {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) == 1 {
+			parts = append(parts, "")
+		}
+		vars[parts[0]] = parts[1]
 	}
-	return newMap
+	return vars
 }
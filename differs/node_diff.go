@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
@@ -47,60 +48,233 @@ func (a NodeAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
 }
 
 func (a NodeAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]util.PackageInfo, error) {
-	path := image.FSPath
 	packages := make(map[string]map[string]util.PackageInfo)
+	pkgs, err := readNodePackages(image)
+	if err != nil {
+		return packages, err
+	}
+	for _, pkg := range pkgs {
+		addToMap(packages, pkg.name, pkg.path, pkg.info)
+	}
+	return packages, nil
+}
+
+type NodeLayerAnalyzer struct {
+}
+
+func (a NodeLayerAnalyzer) Name() string {
+	return "NodeLayerAnalyzer"
+}
+
+// NodeLayerDiff compares the node packages added, removed or updated by each layer of two images.
+func (a NodeLayerAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := multiVersionLayerDiff(image1, image2, a)
+	return diff, err
+}
+
+func (a NodeLayerAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := multiVersionLayerAnalysis(image, a)
+	return analysis, err
+}
+
+func (a NodeLayerAnalyzer) getPackages(image pkgutil.Image) ([]map[string]map[string]util.PackageInfo, error) {
+	return getLayerPackageStates(image, readNodePackages)
+}
+
+// NodeRoots are the directories, relative to the image root, searched for node_modules trees.
+var NodeRoots = []string{"/"}
+
+// NodeDepth is the maximum directory depth below each of NodeRoots at which a
+// node_modules tree is searched for.
+var NodeDepth = 6
+
+// readNodePackages returns the node packages found in the node_modules trees of the
+// image filesystem, marked by their package directory.
+func readNodePackages(image pkgutil.Image) ([]layerPackage, error) {
+	path := image.FSPath
+	var packages []layerPackage
 	if _, err := os.Stat(path); err != nil {
 		// path provided invalid
 		return packages, err
 	}
-	layerStems, err := buildNodePaths(path)
+	modulesDirs, err := findNodeModules(path, NodeRoots, NodeDepth)
 	if err != nil {
-		logrus.Warningf("Error building JSON paths at %s: %s\n", path, err)
+		logrus.Warningf("Error searching for node_modules at %s: %s\n", path, err)
 		return packages, err
 	}
 
-	for _, modulesDir := range layerStems {
-		packageJSONs, _ := util.BuildLayerTargets(modulesDir, "package.json")
-		for _, currPackage := range packageJSONs {
-			if _, err := os.Stat(currPackage); err != nil {
-				// package.json file does not exist at this target path
-				continue
-			}
-			packageJSON, err := readPackageJSON(currPackage)
+	for _, modulesDir := range modulesDirs {
+		project := "/" + strings.TrimPrefix(filepath.Dir(strings.TrimPrefix(modulesDir, path)), "/")
+		packages = append(packages, readNodeModules(path, modulesDir, project)...)
+	}
+	return packages, nil
+}
+
+// findNodeModules returns the top-level node_modules directories found within depth
+// directories below each of roots in the filesystem at path. Nested node_modules
+// directories are left to readNodeModules.
+func findNodeModules(path string, roots []string, depth int) ([]string, error) {
+	var modulesDirs []string
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		rootPath := filepath.Join(path, root)
+		if _, err := os.Stat(rootPath); err != nil {
+			continue
+		}
+		err := filepath.Walk(rootPath, func(dir string, info os.FileInfo, err error) error {
 			if err != nil {
-				logrus.Warningf("Error reading package JSON at %s: %s\n", currPackage, err)
-				return packages, err
+				logrus.Debugf("Unable to read %s: %s", dir, err)
+				return nil
+			}
+			if !info.IsDir() {
+				return nil
 			}
-			// Build PackageInfo for this package occurence
-			var currInfo util.PackageInfo
-			currInfo.Version = packageJSON.Version
-			packagePath := strings.TrimSuffix(currPackage, "package.json")
-			currInfo.Size = pkgutil.GetSize(packagePath)
-			mapPath := strings.Replace(packagePath, path, "", 1)
-			// Check if other package version already recorded
-			if _, ok := packages[packageJSON.Name]; !ok {
-				// package not yet seen
-				infoMap := make(map[string]util.PackageInfo)
-				infoMap[mapPath] = currInfo
-				packages[packageJSON.Name] = infoMap
+			if info.Name() == "node_modules" {
+				if !seen[dir] {
+					seen[dir] = true
+					modulesDirs = append(modulesDirs, dir)
+				}
+				return filepath.SkipDir
+			}
+			rel, _ := filepath.Rel(rootPath, dir)
+			if rel != "." && len(strings.Split(rel, string(filepath.Separator))) >= depth {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			return modulesDirs, err
+		}
+	}
+	return modulesDirs, nil
+}
+
+// readNodeModules reads the packages installed in modulesDir, including scoped
+// @org/pkg packages and the dependencies nested in their own node_modules.
+func readNodeModules(path, modulesDir, project string) []layerPackage {
+	var packages []layerPackage
+	entries, err := ioutil.ReadDir(modulesDir)
+	if err != nil {
+		logrus.Debugf("Unable to read %s: %s", modulesDir, err)
+		return packages
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") {
+			// .bin, lock files and whiteouts
+			continue
+		}
+		entryPath := filepath.Join(modulesDir, name)
+		if strings.HasPrefix(name, "@") {
+			scoped, err := ioutil.ReadDir(entryPath)
+			if err != nil {
+				logrus.Debugf("Unable to read %s: %s", entryPath, err)
 				continue
 			}
-			packages[packageJSON.Name][mapPath] = currInfo
+			for _, scopedEntry := range scoped {
+				if scopedEntry.IsDir() && !strings.HasPrefix(scopedEntry.Name(), ".") {
+					packages = append(packages, readNodePackage(path, filepath.Join(entryPath, scopedEntry.Name()), project)...)
+				}
+			}
+			continue
+		}
+		packages = append(packages, readNodePackage(path, entryPath, project)...)
+	}
+	return packages
+}
 
+// readNodePackage reads the package installed at packageDir along with its nested dependencies.
+func readNodePackage(path, packageDir, project string) []layerPackage {
+	var packages []layerPackage
+	nestedModules := filepath.Join(packageDir, "node_modules")
+	packageJSONPath := filepath.Join(packageDir, "package.json")
+	if _, err := os.Stat(packageJSONPath); err == nil {
+		packageJSON, err := readPackageJSON(packageJSONPath)
+		if err != nil {
+			logrus.Warningf("Error reading package JSON at %s: %s\n", packageJSONPath, err)
+		} else {
+			// Build PackageInfo for this package occurence, excluding its nested dependencies
+			size := pkgutil.GetSize(packageDir)
+			if _, err := os.Stat(nestedModules); err == nil {
+				size -= pkgutil.GetSize(nestedModules)
+			}
+			mapPath := strings.Replace(packageDir, path, "", 1) + "/"
+			packages = append(packages, layerPackage{
+				name:   packageJSON.Name,
+				path:   mapPath,
+				marker: mapPath,
+				info: util.PackageInfo{
+					Version:      packageJSON.Version,
+					Size:         size,
+					Project:      project,
+					License:      packageJSON.license(),
+					Maintainer:   packageJSON.author(),
+					Homepage:     packageJSON.Homepage,
+					Dependencies: packageJSON.dependencies(),
+				},
+			})
 		}
 	}
-	return packages, nil
+	return append(packages, readNodeModules(path, nestedModules, project)...)
 }
 
 type nodePackage struct {
-	Name    string `json:"name"`
-	Version string `json:"version"`
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Homepage     string            `json:"homepage"`
+	License      json.RawMessage   `json:"license"`
+	Licenses     []json.RawMessage `json:"licenses"`
+	Author       json.RawMessage   `json:"author"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// license returns the declared license, given either as an SPDX expression or, in
+// older packages, as a {"type": ...} object or a list of those.
+func (p nodePackage) license() string {
+	var licenses []string
+	for _, raw := range append([]json.RawMessage{p.License}, p.Licenses...) {
+		if license := stringOrField(raw, "type"); license != "" {
+			licenses = append(licenses, license)
+		}
+	}
+	if len(licenses) > 1 {
+		return "(" + strings.Join(licenses, " OR ") + ")"
+	}
+	return strings.Join(licenses, "")
+}
+
+// author returns the author, given either as "Name <email> (url)" or as a
+// {"name": ...} object.
+func (p nodePackage) author() string {
+	return stringOrField(p.Author, "name")
 }
 
-func buildNodePaths(path string) ([]string, error) {
-	globalPaths := filepath.Join(path, "node_modules")
-	localPath := filepath.Join(path, "usr/local/lib/node_modules")
-	return []string{globalPaths, localPath}, nil
+// dependencies returns the declared dependencies as name@range, sorted by name.
+func (p nodePackage) dependencies() []string {
+	var deps []string
+	for name, versionRange := range p.Dependencies {
+		deps = append(deps, name+"@"+versionRange)
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// stringOrField decodes raw as a string, or as an object holding the string in field.
+func stringOrField(raw json.RawMessage, field string) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		if value, ok := object[field].(string); ok {
+			return value
+		}
+	}
+	return ""
 }
 
 func readPackageJSON(path string) (nodePackage, error) {
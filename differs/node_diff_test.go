@@ -17,6 +17,7 @@ limitations under the License.
 package differs
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
 
@@ -46,17 +47,17 @@ func TestGetNodePackages(t *testing.T) {
 			descrip: "all packages in one layer",
 			path:    "testDirs/packageOne",
 			expected: map[string]map[string]util.PackageInfo{
-				"pac1": {"/node_modules/pac1/": {Version: "1.0", Size: 41}},
-				"pac2": {"/usr/local/lib/node_modules/pac2/": {Version: "2.0", Size: 41}},
-				"pac3": {"/node_modules/pac3/": {Version: "3.0", Size: 41}}},
+				"pac1": {"/node_modules/pac1/": {Version: "1.0", Size: 41, Project: "/"}},
+				"pac2": {"/usr/local/lib/node_modules/pac2/": {Version: "2.0", Size: 41, Project: "/usr/local/lib"}},
+				"pac3": {"/node_modules/pac3/": {Version: "3.0", Size: 41, Project: "/"}}},
 		},
 		{
 			descrip: "Multi version packages",
 			path:    "testDirs/packageMulti",
 			expected: map[string]map[string]util.PackageInfo{
-				"pac1": {"/node_modules/pac1/": {Version: "1.0", Size: 41}},
-				"pac2": {"/node_modules/pac2/": {Version: "2.0", Size: 41},
-					"/usr/local/lib/node_modules/pac2/": {Version: "3.0", Size: 41}}},
+				"pac1": {"/node_modules/pac1/": {Version: "1.0", Size: 41, Project: "/"}},
+				"pac2": {"/node_modules/pac2/": {Version: "2.0", Size: 41, Project: "/"},
+					"/usr/local/lib/node_modules/pac2/": {Version: "3.0", Size: 41, Project: "/usr/local/lib"}}},
 		},
 	}
 
@@ -75,11 +76,72 @@ func TestGetNodePackages(t *testing.T) {
 		}
 	}
 }
+
+func TestGetNodeNestedPackages(t *testing.T) {
+	image := pkgutil.Image{FSPath: "testDirs/nodeNested"}
+	expected := map[string]map[string]util.PackageInfo{
+		"@org/util": {"/app/node_modules/@org/util/": {Version: "1.2.0", Size: 48, Project: "/app"}},
+		"express":   {"/app/node_modules/express/": {Version: "4.17.1", Size: 47, Project: "/app"}},
+		"debug":     {"/app/node_modules/express/node_modules/debug/": {Version: "2.6.9", Size: 44, Project: "/app"}},
+	}
+	packages, err := NodeAnalyzer{}.getPackages(image)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+
+	// node_modules trees deeper than NodeDepth are not searched
+	defer func(depth int) { NodeDepth = depth }(NodeDepth)
+	NodeDepth = 1
+	packages, err = NodeAnalyzer{}.getPackages(image)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if len(packages) != 0 {
+		t.Errorf("Expected no packages within depth 1 but got: %v", packages)
+	}
+}
+
+func TestGetNodeLayerPackages(t *testing.T) {
+	image := pkgutil.Image{
+		FSPath: "testDirs/nodeLayers",
+		Layers: []pkgutil.Layer{
+			{FSPath: "testDirs/nodeLayers/layer1"},
+			{FSPath: "testDirs/nodeLayers/layer2"},
+			{FSPath: "testDirs/nodeLayers/layer3"},
+		},
+	}
+	expected := []map[string]map[string]util.PackageInfo{
+		{
+			"pac1": {"/node_modules/pac1/": {Version: "1.0", Size: 41, Project: "/"}},
+			"pac2": {"/node_modules/pac2/": {Version: "2.0", Size: 41, Project: "/"}},
+		},
+		// pac1 is deleted through a whiteout
+		{
+			"pac2": {"/node_modules/pac2/": {Version: "2.1", Size: 41, Project: "/"}},
+		},
+		// node_modules is replaced by an opaque directory
+		{
+			"pac3": {"/usr/local/lib/node_modules/pac3/": {Version: "3.0", Size: 41, Project: "/usr/local/lib"}},
+		},
+	}
+
+	packages, err := NodeLayerAnalyzer{}.getPackages(image)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+}
+
 func TestReadPackageJSON(t *testing.T) {
 	testCases := []struct {
 		descrip  string
 		path     string
-		expected nodePackage
+		expected util.PackageInfo
 		err      bool
 	}{
 		{
@@ -90,12 +152,13 @@ func TestReadPackageJSON(t *testing.T) {
 		{
 			descrip:  "Parse JSON with exact fields",
 			path:     "testDirs/exact.json",
-			expected: nodePackage{"La-croix", "Lime"},
+			expected: util.PackageInfo{Version: "Lime"},
 		},
 		{
-			descrip:  "Parse JSON with additional fields",
-			path:     "testDirs/extra.json",
-			expected: nodePackage{"La-croix", "Lime"},
+			descrip: "Parse JSON with additional fields",
+			path:    "testDirs/extra.json",
+			expected: util.PackageInfo{Version: "Lime", License: "ISC", Maintainer: "Isaac Z. Schlueter",
+				Homepage: "https://github.com/isaacs/sax-js#readme"},
 		},
 	}
 	for _, test := range testCases {
@@ -106,8 +169,36 @@ func TestReadPackageJSON(t *testing.T) {
 		if err == nil && test.err {
 			t.Error("Expected errorbut got none.")
 		}
-		if !reflect.DeepEqual(actual, test.expected) {
-			t.Errorf("Expected: %s but got: %s", test.expected, actual)
+		if test.err {
+			continue
+		}
+		if actual.Name != "La-croix" {
+			t.Errorf("%s: Expected name La-croix but got: %s", test.descrip, actual.Name)
 		}
+		info := util.PackageInfo{Version: actual.Version, License: actual.license(), Maintainer: actual.author(),
+			Homepage: actual.Homepage, Dependencies: actual.dependencies()}
+		if !reflect.DeepEqual(info, test.expected) {
+			t.Errorf("%s: Expected: %v but got: %v", test.descrip, test.expected, info)
+		}
+	}
+}
+
+func TestNodePackageMetadata(t *testing.T) {
+	var pkg nodePackage
+	packageJSON := `{"name": "legacy", "version": "0.1.0",
+		"licenses": [{"type": "MIT"}, {"type": "Apache-2.0"}],
+		"author": "Jane Doe <jane@example.com>",
+		"dependencies": {"ms": "^2.1.1", "debug": "~4.3.0"}}`
+	if err := json.Unmarshal([]byte(packageJSON), &pkg); err != nil {
+		t.Fatalf("Unable to parse package.json: %s", err)
+	}
+	if license := pkg.license(); license != "(MIT OR Apache-2.0)" {
+		t.Errorf("Expected license (MIT OR Apache-2.0) but got %s", license)
+	}
+	if author := pkg.author(); author != "Jane Doe <jane@example.com>" {
+		t.Errorf("Expected author Jane Doe <jane@example.com> but got %s", author)
+	}
+	if deps := pkg.dependencies(); !reflect.DeepEqual(deps, []string{"debug@~4.3.0", "ms@^2.1.1"}) {
+		t.Errorf("Expected sorted dependencies but got %v", deps)
 	}
 }
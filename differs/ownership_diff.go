@@ -0,0 +1,435 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/sirupsen/logrus"
+)
+
+// dpkg file lists location, holding a <package>.list file per installed package
+const dpkgInfoDir string = "/var/lib/dpkg/info"
+
+// apk package database locations, the latter used by apk-tools 3
+var apkInstalledDBs = []string{"/lib/apk/db/installed", "/usr/lib/apk/db/installed"}
+
+// RPM command to list the files of every installed package
+var rpmFilesCmd = []string{
+	"rpm", "--nodigest", "--nosignature",
+	"-qa", "--qf", "[%{NAME}\t%{FILENAMES}\n]",
+}
+
+// packageDatabases are the package manager databases, which no package owns
+var packageDatabases = []string{"/var/lib/dpkg", "/var/lib/rpm", "/usr/lib/sysimage/rpm", "/lib/apk/db", "/usr/lib/apk/db"}
+
+// packageFile is a file installed by a package, as listed by its package manager,
+// along with the digest recorded for it, if any, as algorithm:hex.
+type packageFile struct {
+	pkg    string
+	path   string
+	digest string
+	// config is set for configuration files, which users are expected to modify
+	config bool
+}
+
+// fileOwnership indexes the regular files of an image, with their size, and the
+// packages owning them, as manager:package.
+type fileOwnership struct {
+	files    map[string]int64
+	owners   map[string][]string
+	packages map[string]bool
+}
+
+type OwnershipAnalyzer struct {
+}
+
+func (a OwnershipAnalyzer) Name() string {
+	return "OwnershipAnalyzer"
+}
+
+// Diff reports the files of both images whose owning packages differ.
+func (a OwnershipAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	ownership1, err := getFileOwnership(image1)
+	if err != nil {
+		return &util.OwnershipDiffResult{}, err
+	}
+	ownership2, err := getFileOwnership(image2)
+	if err != nil {
+		return &util.OwnershipDiffResult{}, err
+	}
+	return &util.OwnershipDiffResult{
+		Image1:   image1.Source,
+		Image2:   image2.Source,
+		DiffType: "Ownership",
+		Diff:     diffFileOwnership(ownership1, ownership2),
+	}, nil
+}
+
+func (a OwnershipAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	ownership, err := getFileOwnership(image)
+	if err != nil {
+		return &util.OwnershipAnalyzeResult{}, err
+	}
+	return &util.OwnershipAnalyzeResult{
+		Image:       image.Source,
+		AnalyzeType: "Ownership",
+		Analysis:    ownership.analysis(),
+	}, nil
+}
+
+// analysis returns the files and bytes owned by each package along with the
+// unowned and multi-owned files.
+func (o fileOwnership) analysis() util.OwnershipAnalysis {
+	analysis := util.OwnershipAnalysis{
+		Packages:   []util.PackageFiles{},
+		Unowned:    []pkgutil.DirectoryEntry{},
+		MultiOwned: []util.FileOwners{},
+	}
+	packages := make(map[string]*util.PackageFiles)
+	for owner := range o.packages {
+		parts := strings.SplitN(owner, ":", 2)
+		packages[owner] = &util.PackageFiles{Package: parts[1], Manager: parts[0]}
+	}
+	for path, size := range o.files {
+		owners := o.owners[path]
+		switch {
+		case len(owners) == 0:
+			if !isPackageDatabase(path) {
+				analysis.Unowned = append(analysis.Unowned, pkgutil.DirectoryEntry{Name: path, Size: size})
+			}
+		case len(owners) > 1:
+			analysis.MultiOwned = append(analysis.MultiOwned, util.FileOwners{Path: path, Owners: owners})
+		}
+		for _, owner := range owners {
+			packages[owner].Files++
+			packages[owner].Size += size
+		}
+	}
+	for _, files := range packages {
+		analysis.Packages = append(analysis.Packages, *files)
+	}
+	sort.Slice(analysis.MultiOwned, func(i, j int) bool {
+		return analysis.MultiOwned[i].Path < analysis.MultiOwned[j].Path
+	})
+	return analysis
+}
+
+// diffFileOwnership returns the files present in both images whose owners differ,
+// including files which became owned or unowned.
+func diffFileOwnership(ownership1, ownership2 fileOwnership) []util.OwnershipChange {
+	changes := []util.OwnershipChange{}
+	for path := range ownership1.files {
+		if _, ok := ownership2.files[path]; !ok {
+			continue
+		}
+		owners1, owners2 := ownership1.owners[path], ownership2.owners[path]
+		if len(owners1) == 0 && len(owners2) == 0 || reflect.DeepEqual(owners1, owners2) {
+			continue
+		}
+		changes = append(changes, util.OwnershipChange{Path: path, Owners1: owners1, Owners2: owners2})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+func isPackageDatabase(path string) bool {
+	for _, db := range packageDatabases {
+		if pkgutil.HasFilepathPrefix(path, db) {
+			return true
+		}
+	}
+	return false
+}
+
+// getFileOwnership indexes the regular files of the image filesystem by the dpkg,
+// rpm, apk and pip packages listing them.
+func getFileOwnership(image pkgutil.Image) (fileOwnership, error) {
+	root := image.FSPath
+	ownership := fileOwnership{
+		files:    make(map[string]int64),
+		owners:   make(map[string][]string),
+		packages: make(map[string]bool),
+	}
+	if _, err := os.Stat(root); err != nil {
+		// invalid image directory path
+		return ownership, err
+	}
+
+	var records []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		relPath = "/" + relPath
+		ownership.files[relPath] = info.Size()
+		if isPipRecord(relPath) {
+			records = append(records, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return ownership, err
+	}
+
+	resolver := newPathResolver(root)
+	add := func(manager string, files []packageFile) {
+		for _, file := range files {
+			owner := manager + ":" + file.pkg
+			ownership.packages[owner] = true
+			path := resolver.resolve(file.path)
+			if !containsString(ownership.owners[path], owner) {
+				ownership.owners[path] = append(ownership.owners[path], owner)
+			}
+		}
+	}
+
+	dpkgFiles, err := readDpkgFileLists(root)
+	if err != nil {
+		return ownership, err
+	}
+	add("apt", dpkgFiles)
+
+	if hasRPMBinary(root) {
+		output, err := rpmQuery(image, rpmFilesCmd)
+		if err != nil {
+			return ownership, err
+		}
+		add("rpm", parseRPMFileList(output))
+	}
+
+	apkFiles, err := readApkFileLists(root)
+	if err != nil {
+		return ownership, err
+	}
+	add("apk", apkFiles)
+
+	for _, record := range records {
+		pipFiles, err := readPipRecord(root, record)
+		if err != nil {
+			logrus.Warnf("Could not read %s: %s", record, err)
+			continue
+		}
+		add("pip", pipFiles)
+	}
+
+	for _, owners := range ownership.owners {
+		sort.Strings(owners)
+	}
+	return ownership, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// readDpkgFileLists returns the files listed in the <package>.list files of the
+// dpkg database. Multi-arch packages are listed as <package>:<arch>.list.
+func readDpkgFileLists(root string) ([]packageFile, error) {
+	var files []packageFile
+	lists, err := filepath.Glob(filepath.Join(root, dpkgInfoDir, "*.list"))
+	if err != nil {
+		return files, err
+	}
+	for _, list := range lists {
+		pkg := strings.SplitN(strings.TrimSuffix(filepath.Base(list), ".list"), ":", 2)[0]
+		contents, err := ioutil.ReadFile(list)
+		if err != nil {
+			return files, err
+		}
+		for _, path := range strings.Split(string(contents), "\n") {
+			if path != "" && path != "/." {
+				files = append(files, packageFile{pkg: pkg, path: path})
+			}
+		}
+	}
+	return files, nil
+}
+
+// parseRPMFileList parses the output of rpmFilesCmd, a package name and file per line.
+func parseRPMFileList(rpmOutput []string) []packageFile {
+	var files []packageFile
+	for _, line := range rpmOutput {
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) == 2 && fields[1] != rpmNone {
+			files = append(files, packageFile{pkg: fields[0], path: fields[1]})
+		}
+	}
+	return files
+}
+
+// readApkFileLists returns the files listed in the apk database: the R: entries
+// of each package name the files of the preceding F: directory.
+func readApkFileLists(root string) ([]packageFile, error) {
+	var files []packageFile
+	for _, db := range apkInstalledDBs {
+		file, err := os.Open(filepath.Join(root, db))
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+
+		var pkg, dir string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if len(line) < 2 || line[1] != ':' {
+				// blank line between packages
+				pkg, dir = "", ""
+				continue
+			}
+			switch value := line[2:]; line[0] {
+			case 'P':
+				pkg = value
+			case 'F':
+				dir = value
+			case 'R':
+				files = append(files, packageFile{pkg: pkg, path: "/" + filepath.Join(dir, value)})
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// readPipRecord returns the files listed in the RECORD of a dist-info directory,
+// relative to the directory containing it.
+func readPipRecord(root, record string) ([]packageFile, error) {
+	var files []packageFile
+	distInfo := filepath.Dir(record)
+	pkg := strings.SplitN(filepath.Base(distInfo), "-", 2)[0]
+	if metadata, err := os.Open(filepath.Join(root, distInfo, "METADATA")); err == nil {
+		if name := readPythonMetadata(metadata)["Name"]; len(name) > 0 {
+			pkg = name[0]
+		}
+		metadata.Close()
+	}
+
+	file, err := os.Open(filepath.Join(root, record))
+	if err != nil {
+		return files, err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return files, err
+		}
+		path := fields[0]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(distInfo), path)
+		}
+		var digest string
+		if len(fields) > 1 {
+			digest = recordDigest(fields[1])
+		}
+		files = append(files, packageFile{pkg: pkg, path: path, digest: digest})
+	}
+	return files, nil
+}
+
+// isPipRecord checks whether path is the RECORD file of a dist-info directory,
+// listing the files installed by a Python package.
+func isPipRecord(path string) bool {
+	return filepath.Base(path) == "RECORD" && strings.HasSuffix(filepath.Dir(path), ".dist-info")
+}
+
+// recordDigest converts a RECORD hash, the algorithm and the urlsafe base64
+// encoded digest, e.g. sha256=47DEQpj8..., to algorithm:hex.
+func recordDigest(hash string) string {
+	parts := strings.SplitN(hash, "=", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	digest, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+	return parts[0] + ":" + hex.EncodeToString(digest)
+}
+
+// pathResolver resolves the directories of listed package files through the
+// symbolic links of the image filesystem, e.g. /bin to /usr/bin on merged /usr
+// systems, so that they match the paths found in the image.
+type pathResolver struct {
+	root string
+	dirs map[string]string
+}
+
+func newPathResolver(root string) pathResolver {
+	return pathResolver{root: root, dirs: map[string]string{"/": "/"}}
+}
+
+func (r pathResolver) resolve(path string) string {
+	path = filepath.Join("/", path)
+	if path == "/" {
+		return path
+	}
+	return filepath.Join(r.resolveDir(filepath.Dir(path)), filepath.Base(path))
+}
+
+func (r pathResolver) resolveDir(dir string) string {
+	if resolved, ok := r.dirs[dir]; ok {
+		return resolved
+	}
+	resolved := filepath.Join(r.resolveDir(filepath.Dir(dir)), filepath.Base(dir))
+	// recorded before following links to stop on link cycles
+	r.dirs[dir] = resolved
+	for i := 0; i < 16; i++ {
+		target, err := os.Readlink(filepath.Join(r.root, resolved))
+		if err != nil {
+			// not a link
+			break
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(resolved), target)
+		}
+		resolved = r.resolve(target)
+	}
+	r.dirs[dir] = resolved
+	return resolved
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+// writeTestFiles writes files, keyed by path relative to root, with their contents.
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		file := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write %s: %s", file, err)
+		}
+	}
+}
+
+func TestGetFileOwnership(t *testing.T) {
+	root, err := ioutil.TempDir("", "ownership-analyzer")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	metadata := "Name: requests\nVersion: 2.31.0\n"
+	record := "requests/__init__.py,sha256=abc,6\n\"requests-2.31.0.dist-info/METADATA\",,\n" +
+		"requests-2.31.0.dist-info/RECORD,,\n../../../bin/normalizer,sha256=def,2\n"
+	writeTestFiles(t, root, map[string]string{
+		"var/lib/dpkg/info/bash.list":        "/.\n/bin\n/bin/bash\n/etc/bash.bashrc\n",
+		"var/lib/dpkg/info/libc6:amd64.list": "/.\n/usr/lib/x86_64-linux-gnu/libc.so.6\n/etc/ld.so.conf\n",
+		"var/lib/dpkg/info/libc-bin.list":    "/.\n/etc/ld.so.conf\n",
+		"lib/apk/db/installed":               "C:Q1abc=\nP:musl\nV:1.2.4-r2\nF:lib\nR:ld-musl-x86_64.so.1\n\nP:busybox\nF:bin\nR:busybox\n",
+		"usr/bin/bash":                       "#!bash",
+		"etc/bash.bashrc":                    "# bashrc",
+		"usr/lib/x86_64-linux-gnu/libc.so.6": "libc",
+		"etc/ld.so.conf":                     "include",
+		"lib/ld-musl-x86_64.so.1":            "musl",
+		"app/run.sh":                         "#!/bin/sh\nexec app",
+		"app/venv/lib/python3.11/site-packages/requests/__init__.py":               "import",
+		"app/venv/lib/python3.11/site-packages/requests-2.31.0.dist-info/METADATA": metadata,
+		"app/venv/lib/python3.11/site-packages/requests-2.31.0.dist-info/RECORD":   record,
+		"app/venv/bin/normalizer": "py",
+	})
+	// merged /usr: the dpkg file lists refer to /bin
+	if err := os.Symlink("usr/bin", filepath.Join(root, "bin")); err != nil {
+		t.Fatalf("Unable to link /bin: %s", err)
+	}
+
+	ownership, err := getFileOwnership(pkgutil.Image{FSPath: root})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	analysis := ownership.analysis()
+
+	expected := map[string]util.PackageFiles{
+		"apk:busybox":  {Package: "busybox", Manager: "apk"},
+		"apk:musl":     {Package: "musl", Manager: "apk", Files: 1, Size: 4},
+		"apt:bash":     {Package: "bash", Manager: "apt", Files: 2, Size: 14},
+		"apt:libc-bin": {Package: "libc-bin", Manager: "apt", Files: 1, Size: 7},
+		"apt:libc6":    {Package: "libc6", Manager: "apt", Files: 2, Size: 11},
+		"pip:requests": {Package: "requests", Manager: "pip", Files: 4, Size: int64(len("import") + len(metadata) + len(record) + len("py"))},
+	}
+	for _, files := range analysis.Packages {
+		key := files.Manager + ":" + files.Package
+		if files != expected[key] {
+			t.Errorf("Expected %v but got %v", expected[key], files)
+		}
+		delete(expected, key)
+	}
+	if len(expected) != 0 {
+		t.Errorf("Expected packages not found: %v", expected)
+	}
+
+	expectedUnowned := []pkgutil.DirectoryEntry{{Name: "/app/run.sh", Size: 18}}
+	if !reflect.DeepEqual(analysis.Unowned, expectedUnowned) {
+		t.Errorf("Expected unowned files %v but got %v", expectedUnowned, analysis.Unowned)
+	}
+	expectedMultiOwned := []util.FileOwners{{Path: "/etc/ld.so.conf", Owners: []string{"apt:libc-bin", "apt:libc6"}}}
+	if !reflect.DeepEqual(analysis.MultiOwned, expectedMultiOwned) {
+		t.Errorf("Expected multi-owned files %v but got %v", expectedMultiOwned, analysis.MultiOwned)
+	}
+
+	if _, err := getFileOwnership(pkgutil.Image{FSPath: "testDirs/notThere"}); err == nil {
+		t.Errorf("Expected error for missing path")
+	}
+}
+
+func TestDiffFileOwnership(t *testing.T) {
+	ownership1 := fileOwnership{
+		files: map[string]int64{"/etc/hosts.conf": 10, "/usr/bin/tool": 100, "/usr/bin/curl": 50, "/app/run.sh": 5, "/usr/bin/old": 1},
+		owners: map[string][]string{
+			"/etc/hosts.conf": {"apt:base-files"},
+			"/usr/bin/tool":   {"apt:tool"},
+			"/usr/bin/curl":   {"apt:curl"},
+			"/usr/bin/old":    {"apt:old"},
+		},
+	}
+	ownership2 := fileOwnership{
+		files: map[string]int64{"/etc/hosts.conf": 10, "/usr/bin/tool": 120, "/usr/bin/curl": 50, "/app/run.sh": 5, "/usr/bin/new": 1},
+		owners: map[string][]string{
+			"/etc/hosts.conf": {"apt:base-files", "apt:netbase"},
+			"/usr/bin/curl":   {"apt:curl"},
+			"/usr/bin/new":    {"apt:new"},
+		},
+	}
+	expected := []util.OwnershipChange{
+		{Path: "/etc/hosts.conf", Owners1: []string{"apt:base-files"}, Owners2: []string{"apt:base-files", "apt:netbase"}},
+		{Path: "/usr/bin/tool", Owners1: []string{"apt:tool"}},
+	}
+	if changes := diffFileOwnership(ownership1, ownership2); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, changes)
+	}
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package differs
 
 import (
-	"errors"
 	"strings"
 
 	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
@@ -40,17 +39,52 @@ type SingleVersionPackageLayerAnalyzer interface {
 	Name() string
 }
 
+type MultiVersionPackageLayerAnalyzer interface {
+	getPackages(image pkgutil.Image) ([]map[string]map[string]util.PackageInfo, error)
+	Name() string
+}
+
+// versionComparators maps package analyzers to the version ordering used by
+// the package manager they read from.
+var versionComparators = map[string]util.VersionComparator{
+	AptAnalyzer{}.Name():         compareAptVersions,
+	AptLayerAnalyzer{}.Name():    compareAptVersions,
+	RPMAnalyzer{}.Name():         util.CompareRPMVersions,
+	RPMLayerAnalyzer{}.Name():    util.CompareRPMVersions,
+	PipAnalyzer{}.Name():         util.ComparePEP440Versions,
+	NodeAnalyzer{}.Name():        util.CompareSemverVersions,
+	EmergeAnalyzer{}.Name():      util.CompareGentooVersions,
+	PipLayerAnalyzer{}.Name():    util.ComparePEP440Versions,
+	NodeLayerAnalyzer{}.Name():   util.CompareSemverVersions,
+	EmergeLayerAnalyzer{}.Name(): util.CompareGentooVersions,
+	CondaAnalyzer{}.Name():       util.ComparePEP440Versions,
+	GemAnalyzer{}.Name():         util.CompareRubyGemsVersions,
+	JavaAnalyzer{}.Name():        util.CompareMavenVersions,
+	GoBinaryAnalyzer{}.Name():    util.CompareSemverVersions,
+	ComposerAnalyzer{}.Name():    util.CompareSemverVersions,
+	DotnetAnalyzer{}.Name():      util.CompareSemverVersions,
+	PacmanAnalyzer{}.Name():      util.CompareRPMVersions,
+	PacmanLayerAnalyzer{}.Name(): util.CompareRPMVersions,
+	RAnalyzer{}.Name():           util.CompareRVersions,
+}
+
 func multiVersionDiff(image1, image2 pkgutil.Image, differ MultiVersionPackageAnalyzer) (*util.MultiVersionPackageDiffResult, error) {
-	pack1, err := differ.getPackages(image1)
+	pack1, err := getMultiVersionPackages(image1, differ)
 	if err != nil {
 		return &util.MultiVersionPackageDiffResult{}, err
 	}
-	pack2, err := differ.getPackages(image2)
+	pack2, err := getMultiVersionPackages(image2, differ)
 	if err != nil {
 		return &util.MultiVersionPackageDiffResult{}, err
 	}
+	if image1.SBOMPath != "" || image2.SBOMPath != "" {
+		pack1, pack2 = alignSBOMPackages(pack1, pack2), alignSBOMPackages(pack2, pack1)
+	}
 
 	diff := util.GetMultiVersionMapDiff(pack1, pack2)
+	if compare, ok := versionComparators[differ.Name()]; ok {
+		util.ClassifyMultiVersionPackageDiff(&diff, compare)
+	}
 	return &util.MultiVersionPackageDiffResult{
 		Image1:   image1.Source,
 		Image2:   image2.Source,
@@ -60,16 +94,31 @@ func multiVersionDiff(image1, image2 pkgutil.Image, differ MultiVersionPackageAn
 }
 
 func singleVersionDiff(image1, image2 pkgutil.Image, differ SingleVersionPackageAnalyzer) (*util.SingleVersionPackageDiffResult, error) {
-	pack1, err := differ.getPackages(image1)
+	pack1, err := getSingleVersionPackages(image1, differ)
 	if err != nil {
 		return &util.SingleVersionPackageDiffResult{}, err
 	}
-	pack2, err := differ.getPackages(image2)
+	pack2, err := getSingleVersionPackages(image2, differ)
 	if err != nil {
 		return &util.SingleVersionPackageDiffResult{}, err
 	}
+	if image1.SBOMPath != "" || image2.SBOMPath != "" {
+		for _, pack := range []map[string]util.PackageInfo{pack1, pack2} {
+			for name, info := range pack {
+				pack[name] = sbomPackageInfo(info)
+			}
+		}
+	}
 
 	diff := util.GetMapDiff(pack1, pack2)
+	if compare, ok := versionComparators[differ.Name()]; ok {
+		util.ClassifyPackageDiff(&diff, compare)
+		if reader, ok := differ.(changelogReader); ok && Changelogs && image2.SBOMPath == "" {
+			if err := addChangelogs(&diff, image2, reader, compare); err != nil {
+				logrus.Warnf("Error reading changelogs from %s: %s", image2.Source, err)
+			}
+		}
+	}
 	return &util.SingleVersionPackageDiffResult{
 		Image1:   image1.Source,
 		Image2:   image2.Source,
@@ -78,15 +127,131 @@ func singleVersionDiff(image1, image2 pkgutil.Image, differ SingleVersionPackage
 	}, nil
 }
 
-// singleVersionLayerDiff returns an error as this diff is not supported as
-// it is far from obvious to define it in meaningful way
+// singleVersionLayerDiff aligns the layers of both images by index and compares the
+// packages each layer installed, removed or updated. Only layers whose package
+// changes differ between the images are reported.
 func singleVersionLayerDiff(image1, image2 pkgutil.Image, differ SingleVersionPackageLayerAnalyzer) (*util.SingleVersionPackageLayerDiffResult, error) {
-	logrus.Warning("'diff' command for packages on layers is not supported, consider using 'analyze' on each image instead")
-	return &util.SingleVersionPackageLayerDiffResult{}, errors.New("Diff for packages on layers is not supported, only analysis is supported")
+	pack1, err := getSingleVersionLayerPackages(image1, differ)
+	if err != nil {
+		return &util.SingleVersionPackageLayerDiffResult{}, err
+	}
+	pack2, err := getSingleVersionLayerPackages(image2, differ)
+	if err != nil {
+		return &util.SingleVersionPackageLayerDiffResult{}, err
+	}
+	changes1 := getLayerPackageChanges(pack1, differ.Name())
+	changes2 := getLayerPackageChanges(pack2, differ.Name())
+
+	maxLayer := len(image1.Layers)
+	if len(image2.Layers) > maxLayer {
+		maxLayer = len(image2.Layers)
+	}
+
+	var layerDiffs []util.PackageLayerChangeDiff
+	for index := 0; index < maxLayer; index++ {
+		// layers missing from one of the images did not change any package
+		var layerChanges1, layerChanges2 util.PackageDiff
+		if index < len(changes1) {
+			layerChanges1 = changes1[index]
+		}
+		if index < len(changes2) {
+			layerChanges2 = changes2[index]
+		}
+
+		layerDiff, differs := util.DiffLayerPackageChanges(layerChanges1, layerChanges2)
+		if !differs {
+			continue
+		}
+		if compare, ok := versionComparators[differ.Name()]; ok {
+			util.ClassifyPackageDiff(&layerDiff.Installed, compare)
+			util.ClassifyPackageDiff(&layerDiff.Updated, compare)
+		}
+		layerDiff.Layer = index
+		if index < len(image1.Layers) {
+			layerDiff.Digest1 = image1.Layers[index].Digest.String()
+		}
+		if index < len(image2.Layers) {
+			layerDiff.Digest2 = image2.Layers[index].Digest.String()
+		}
+		layerDiffs = append(layerDiffs, layerDiff)
+	}
+
+	return &util.SingleVersionPackageLayerDiffResult{
+		Image1:   image1.Source,
+		Image2:   image2.Source,
+		DiffType: strings.TrimSuffix(differ.Name(), "Analyzer"),
+		Diff: util.PackageLayerChangesDiff{
+			LayerDiffs: layerDiffs,
+		},
+	}, nil
+}
+
+// multiVersionLayerDiff is the multi-version counterpart of singleVersionLayerDiff.
+func multiVersionLayerDiff(image1, image2 pkgutil.Image, differ MultiVersionPackageLayerAnalyzer) (*util.MultiVersionPackageLayerDiffResult, error) {
+	pack1, err := getMultiVersionLayerPackages(image1, differ)
+	if err != nil {
+		return &util.MultiVersionPackageLayerDiffResult{}, err
+	}
+	pack2, err := getMultiVersionLayerPackages(image2, differ)
+	if err != nil {
+		return &util.MultiVersionPackageLayerDiffResult{}, err
+	}
+
+	maxLayer := len(image1.Layers)
+	if len(image2.Layers) > maxLayer {
+		maxLayer = len(image2.Layers)
+	}
+
+	var layerDiffs []util.MultiVersionPackageLayerChangeDiff
+	for index := 0; index < maxLayer; index++ {
+		prev1, cur1 := getLayerPackageState(pack1, index)
+		prev2, cur2 := getLayerPackageState(pack2, index)
+		layerDiff, differs := util.DiffMultiVersionLayerPackageChanges(prev1, cur1, prev2, cur2)
+		if !differs {
+			continue
+		}
+		if compare, ok := versionComparators[differ.Name()]; ok {
+			util.ClassifyMultiVersionPackageDiff(&layerDiff.Installed, compare)
+			util.ClassifyMultiVersionPackageDiff(&layerDiff.Updated, compare)
+		}
+		layerDiff.Layer = index
+		if index < len(image1.Layers) {
+			layerDiff.Digest1 = image1.Layers[index].Digest.String()
+		}
+		if index < len(image2.Layers) {
+			layerDiff.Digest2 = image2.Layers[index].Digest.String()
+		}
+		layerDiffs = append(layerDiffs, layerDiff)
+	}
+
+	return &util.MultiVersionPackageLayerDiffResult{
+		Image1:   image1.Source,
+		Image2:   image2.Source,
+		DiffType: strings.TrimSuffix(differ.Name(), "Analyzer"),
+		Diff: util.MultiVersionPackageLayerChangesDiff{
+			LayerDiffs: layerDiffs,
+		},
+	}, nil
+}
+
+// getLayerPackageState returns the packages installed before and after the layer at
+// index. Layers past the end of the image leave the packages unchanged.
+func getLayerPackageState(pack []map[string]map[string]util.PackageInfo, index int) (prev, cur map[string]map[string]util.PackageInfo) {
+	prev = map[string]map[string]util.PackageInfo{}
+	if index > 0 && index-1 < len(pack) {
+		prev = pack[index-1]
+	} else if index > 0 && len(pack) > 0 {
+		prev = pack[len(pack)-1]
+	}
+	cur = prev
+	if index < len(pack) {
+		cur = pack[index]
+	}
+	return prev, cur
 }
 
 func multiVersionAnalysis(image pkgutil.Image, analyzer MultiVersionPackageAnalyzer) (*util.MultiVersionPackageAnalyzeResult, error) {
-	pack, err := analyzer.getPackages(image)
+	pack, err := getMultiVersionPackages(image, analyzer)
 	if err != nil {
 		return &util.MultiVersionPackageAnalyzeResult{}, err
 	}
@@ -100,7 +265,7 @@ func multiVersionAnalysis(image pkgutil.Image, analyzer MultiVersionPackageAnaly
 }
 
 func singleVersionAnalysis(image pkgutil.Image, analyzer SingleVersionPackageAnalyzer) (*util.SingleVersionPackageAnalyzeResult, error) {
-	pack, err := analyzer.getPackages(image)
+	pack, err := getSingleVersionPackages(image, analyzer)
 	if err != nil {
 		return &util.SingleVersionPackageAnalyzeResult{}, err
 	}
@@ -116,10 +281,24 @@ func singleVersionAnalysis(image pkgutil.Image, analyzer SingleVersionPackageAna
 // singleVersionLayerAnalysis returns the packages included, deleted or
 // updated in each layer
 func singleVersionLayerAnalysis(image pkgutil.Image, analyzer SingleVersionPackageLayerAnalyzer) (*util.SingleVersionPackageLayerAnalyzeResult, error) {
-	pack, err := analyzer.getPackages(image)
+	pack, err := getSingleVersionLayerPackages(image, analyzer)
 	if err != nil {
 		return &util.SingleVersionPackageLayerAnalyzeResult{}, err
 	}
+	pkgDiffs := getLayerPackageChanges(pack, analyzer.Name())
+
+	return &util.SingleVersionPackageLayerAnalyzeResult{
+		Image:       image.Source,
+		AnalyzeType: strings.TrimSuffix(analyzer.Name(), "Analyzer"),
+		Analysis: util.PackageLayerDiff{
+			PackageDiffs: pkgDiffs,
+		},
+	}, nil
+}
+
+// getLayerPackageChanges returns the packages included, deleted or updated in
+// each layer given the package database found in every layer.
+func getLayerPackageChanges(pack []map[string]util.PackageInfo, analyzerName string) []util.PackageDiff {
 	var pkgDiffs []util.PackageDiff
 
 	// Each layer with modified packages includes a complete list of packages
@@ -136,15 +315,164 @@ func singleVersionLayerAnalysis(image pkgutil.Image, analyzer SingleVersionPacka
 			pkgDiff = util.GetMapDiff(pack[preInd], pack[i])
 			preInd = i
 		}
+		if compare, ok := versionComparators[analyzerName]; ok {
+			util.ClassifyPackageDiff(&pkgDiff, compare)
+		}
 
 		pkgDiffs = append(pkgDiffs, pkgDiff)
 	}
+	return pkgDiffs
+}
 
-	return &util.SingleVersionPackageLayerAnalyzeResult{
+// multiVersionLayerAnalysis returns the packages included, deleted or
+// updated in each layer
+func multiVersionLayerAnalysis(image pkgutil.Image, analyzer MultiVersionPackageLayerAnalyzer) (*util.MultiVersionPackageLayerAnalyzeResult, error) {
+	pack, err := getMultiVersionLayerPackages(image, analyzer)
+	if err != nil {
+		return &util.MultiVersionPackageLayerAnalyzeResult{}, err
+	}
+
+	var pkgDiffs []util.MultiVersionPackageDiff
+	prev := make(map[string]map[string]util.PackageInfo)
+	for _, cur := range pack {
+		pkgDiff := util.GetMultiVersionMapDiff(prev, cur)
+		if compare, ok := versionComparators[analyzer.Name()]; ok {
+			util.ClassifyMultiVersionPackageDiff(&pkgDiff, compare)
+		}
+		pkgDiffs = append(pkgDiffs, pkgDiff)
+		prev = cur
+	}
+
+	return &util.MultiVersionPackageLayerAnalyzeResult{
 		Image:       image.Source,
 		AnalyzeType: strings.TrimSuffix(analyzer.Name(), "Analyzer"),
-		Analysis: util.PackageLayerDiff{
+		Analysis: util.MultiVersionPackageLayerDiff{
 			PackageDiffs: pkgDiffs,
 		},
 	}, nil
 }
+
+// layerPackage is a package installation found in a single layer.
+type layerPackage struct {
+	name string
+	// path is the installation path the package is keyed by in multi-version
+	// package maps; it is empty for single-version package managers.
+	path string
+	// marker is the file or directory, relative to the image root, whose
+	// deletion uninstalls the package.
+	marker string
+	info   util.PackageInfo
+}
+
+// getLayerPackageStates stacks the layers of image on top of each other and returns
+// the packages installed once each layer is applied. readLayer is called with the
+// unpacked layer as the image filesystem. An installation from a lower layer is
+// dropped once a later layer deletes its marker through a whiteout, either directly
+// or through one of its parent directories or an opaque directory.
+func getLayerPackageStates(image pkgutil.Image, readLayer func(layer pkgutil.Image) ([]layerPackage, error)) ([]map[string]map[string]util.PackageInfo, error) {
+	type installationKey struct{ name, path string }
+	installed := make(map[installationKey]layerPackage)
+
+	var states []map[string]map[string]util.PackageInfo
+	for _, layer := range image.Layers {
+		deleted, opaque, err := pkgutil.GetWhiteouts(layer.FSPath)
+		if err != nil {
+			return states, err
+		}
+		for key, pkg := range installed {
+			if isWhitedOut(pkg.marker, deleted, opaque) {
+				delete(installed, key)
+			}
+		}
+
+		layerImage := pkgutil.Image{
+			Image:  image.Image,
+			Source: image.Source,
+			FSPath: layer.FSPath,
+			Digest: layer.Digest,
+		}
+		pkgs, err := readLayer(layerImage)
+		if err != nil {
+			return states, err
+		}
+		for _, pkg := range pkgs {
+			installed[installationKey{pkg.name, pkg.path}] = pkg
+		}
+
+		state := make(map[string]map[string]util.PackageInfo)
+		for _, pkg := range installed {
+			addToMap(state, pkg.name, pkg.path, pkg.info)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func isWhitedOut(marker string, deleted, opaque []string) bool {
+	for _, path := range deleted {
+		if pkgutil.HasFilepathPrefix(marker, path) {
+			return true
+		}
+	}
+	for _, dir := range opaque {
+		if marker != dir && pkgutil.HasFilepathPrefix(marker, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// getSingleVersionLayerPackages returns the packages found by the analyzer in
+// each layer of the image, with their package URL.
+func getSingleVersionLayerPackages(image pkgutil.Image, analyzer SingleVersionPackageLayerAnalyzer) ([]map[string]util.PackageInfo, error) {
+	pack, err := analyzer.getPackages(image)
+	for _, packages := range pack {
+		setPackageURLs(image, analyzer.Name(), packages)
+	}
+	return pack, err
+}
+
+// getMultiVersionLayerPackages is the multi-version counterpart of getSingleVersionLayerPackages.
+func getMultiVersionLayerPackages(image pkgutil.Image, analyzer MultiVersionPackageLayerAnalyzer) ([]map[string]map[string]util.PackageInfo, error) {
+	pack, err := analyzer.getPackages(image)
+	for _, packages := range pack {
+		setMultiVersionPackageURLs(image, analyzer.Name(), packages)
+	}
+	return pack, err
+}
+
+// packageAnalyzeType returns the analyze type of the packages found by the
+// analyzer of the given name, the same for package and package layer analyzers.
+func packageAnalyzeType(analyzerName string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(analyzerName, "Analyzer"), "Layer")
+}
+
+// imageDistro returns the distribution of the image, unknown for SBOMs.
+func imageDistro(image pkgutil.Image) util.Distro {
+	if image.FSPath == "" {
+		return util.Distro{}
+	}
+	return util.GetDistro(image.FSPath)
+}
+
+// setPackageURLs sets the package URL of the packages found by the analyzer in the image.
+func setPackageURLs(image pkgutil.Image, analyzerName string, packages map[string]util.PackageInfo) {
+	analyzeType := packageAnalyzeType(analyzerName)
+	distro := imageDistro(image)
+	for name, info := range packages {
+		info.PURL = util.PackageURL(analyzeType, name, info, distro)
+		packages[name] = info
+	}
+}
+
+// setMultiVersionPackageURLs is the multi-version counterpart of setPackageURLs.
+func setMultiVersionPackageURLs(image pkgutil.Image, analyzerName string, packages map[string]map[string]util.PackageInfo) {
+	analyzeType := packageAnalyzeType(analyzerName)
+	distro := imageDistro(image)
+	for name, installations := range packages {
+		for path, info := range installations {
+			info.PURL = util.PackageURL(analyzeType, name, info, distro)
+			installations[path] = info
+		}
+	}
+}
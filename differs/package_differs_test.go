@@ -0,0 +1,137 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+type fakeLayerAnalyzer struct {
+	layers map[string][]map[string]util.PackageInfo
+}
+
+func (a fakeLayerAnalyzer) Name() string {
+	return "AptLayerAnalyzer"
+}
+
+func (a fakeLayerAnalyzer) getPackages(image pkgutil.Image) ([]map[string]util.PackageInfo, error) {
+	return a.layers[image.Source], nil
+}
+
+func TestSingleVersionLayerDiff(t *testing.T) {
+	base := map[string]util.PackageInfo{"libc6": {Version: "2.28-10"}}
+	analyzer := fakeLayerAnalyzer{
+		layers: map[string][]map[string]util.PackageInfo{
+			"image1": {
+				base,
+				{"libc6": {Version: "2.28-10"}, "curl": {Version: "7.64.0-4"}, "wget": {Version: "1.20.1-1.1"}},
+			},
+			"image2": {
+				base,
+				{"libc6": {Version: "2.28-10+deb10u1"}, "curl": {Version: "7.64.0-3"}},
+				{"libc6": {Version: "2.28-10+deb10u1"}},
+			},
+		},
+	}
+	image1 := pkgutil.Image{Source: "image1", Layers: make([]pkgutil.Layer, 2)}
+	image2 := pkgutil.Image{Source: "image2", Layers: make([]pkgutil.Layer, 3)}
+
+	result, err := singleVersionLayerDiff(image1, image2, analyzer)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	diff := result.Diff.(util.PackageLayerChangesDiff)
+	if len(diff.LayerDiffs) != 2 {
+		t.Fatalf("Expected 2 differing layers but got %d: %v", len(diff.LayerDiffs), diff.LayerDiffs)
+	}
+
+	layer1 := diff.LayerDiffs[0]
+	if layer1.Layer != 1 {
+		t.Errorf("Expected first differing layer to be 1 but got %d", layer1.Layer)
+	}
+	if !reflect.DeepEqual(layer1.Installed.Packages1, map[string]util.PackageInfo{"wget": {Version: "1.20.1-1.1", PURL: "pkg:deb/debian/wget@1.20.1-1.1"}}) {
+		t.Errorf("Expected wget to be installed only in image1 but got %v", layer1.Installed.Packages1)
+	}
+	expectedInfo := []util.Info{{
+		Package: "curl",
+		Info1:   util.PackageInfo{Version: "7.64.0-4", PURL: "pkg:deb/debian/curl@7.64.0-4"},
+		Info2:   util.PackageInfo{Version: "7.64.0-3", PURL: "pkg:deb/debian/curl@7.64.0-3"},
+		Change:  util.VersionDowngrade,
+	}}
+	if !reflect.DeepEqual(layer1.Installed.InfoDiff, expectedInfo) {
+		t.Errorf("Expected curl to be installed in different versions but got %v", layer1.Installed.InfoDiff)
+	}
+	if !reflect.DeepEqual(layer1.Updated.Packages2, map[string]util.PackageInfo{"libc6": {Version: "2.28-10+deb10u1", PURL: "pkg:deb/debian/libc6@2.28-10%2Bdeb10u1"}}) {
+		t.Errorf("Expected libc6 to be updated only in image2 but got %v", layer1.Updated.Packages2)
+	}
+
+	layer2 := diff.LayerDiffs[1]
+	if !reflect.DeepEqual(layer2.Removed.Packages2, map[string]util.PackageInfo{"curl": {Version: "7.64.0-3", PURL: "pkg:deb/debian/curl@7.64.0-3"}}) {
+		t.Errorf("Expected curl to be removed only in image2 but got %v", layer2.Removed.Packages2)
+	}
+}
+
+type fakeMultiVersionLayerAnalyzer struct {
+	layers map[string][]map[string]map[string]util.PackageInfo
+}
+
+func (a fakeMultiVersionLayerAnalyzer) Name() string {
+	return "PipLayerAnalyzer"
+}
+
+func (a fakeMultiVersionLayerAnalyzer) getPackages(image pkgutil.Image) ([]map[string]map[string]util.PackageInfo, error) {
+	return a.layers[image.Source], nil
+}
+
+func TestMultiVersionLayerDiff(t *testing.T) {
+	base := map[string]map[string]util.PackageInfo{"six": {"/usr/lib/python3/dist-packages": {Version: "1.12.0"}}}
+	analyzer := fakeMultiVersionLayerAnalyzer{
+		layers: map[string][]map[string]map[string]util.PackageInfo{
+			"image1": {
+				base,
+				{"six": {"/usr/lib/python3/dist-packages": {Version: "1.12.0"}}, "requests": {"/usr/local/lib/python3.7/site-packages": {Version: "2.22.0"}}},
+			},
+			"image2": {
+				base,
+				{"six": {"/usr/lib/python3/dist-packages": {Version: "1.12.0"}}},
+				{},
+			},
+		},
+	}
+	image1 := pkgutil.Image{Source: "image1", Layers: make([]pkgutil.Layer, 2)}
+	image2 := pkgutil.Image{Source: "image2", Layers: make([]pkgutil.Layer, 3)}
+
+	result, err := multiVersionLayerDiff(image1, image2, analyzer)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	diff := result.Diff.(util.MultiVersionPackageLayerChangesDiff)
+	if len(diff.LayerDiffs) != 2 {
+		t.Fatalf("Expected 2 differing layers but got %d: %v", len(diff.LayerDiffs), diff.LayerDiffs)
+	}
+	expectedInstalled := map[string]map[string]util.PackageInfo{"requests": {"/usr/local/lib/python3.7/site-packages": {Version: "2.22.0", PURL: "pkg:pypi/requests@2.22.0"}}}
+	if !reflect.DeepEqual(diff.LayerDiffs[0].Installed.Packages1, expectedInstalled) {
+		t.Errorf("Expected requests to be installed only in image1 but got %v", diff.LayerDiffs[0].Installed.Packages1)
+	}
+	if !reflect.DeepEqual(diff.LayerDiffs[1].Removed.Packages2, base) {
+		t.Errorf("Expected six to be removed only in image2 but got %v", diff.LayerDiffs[1].Removed.Packages2)
+	}
+}
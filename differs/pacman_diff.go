@@ -0,0 +1,198 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/sirupsen/logrus"
+)
+
+// pacman local package database location
+const pacmanLocalDB string = "/var/lib/pacman/local"
+
+// Install reasons recorded by pacman
+const (
+	pacmanReasonExplicit   = "explicit"
+	pacmanReasonDependency = "dependency"
+)
+
+type PacmanAnalyzer struct {
+}
+
+func (a PacmanAnalyzer) Name() string {
+	return "PacmanAnalyzer"
+}
+
+// PacmanDiff compares the packages installed by pacman.
+func (a PacmanAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := singleVersionDiff(image1, image2, a)
+	return diff, err
+}
+
+func (a PacmanAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := singleVersionAnalysis(image, a)
+	return analysis, err
+}
+
+func (a PacmanAnalyzer) getPackages(image pkgutil.Image) (map[string]util.PackageInfo, error) {
+	packages := make(map[string]util.PackageInfo)
+	if _, err := os.Stat(image.FSPath); err != nil {
+		// invalid image directory path
+		return packages, err
+	}
+	pkgs, err := readPacmanPackages(image)
+	if err != nil {
+		return packages, err
+	}
+	for _, pkg := range pkgs {
+		packages[pkg.name] = pkg.info
+	}
+	return packages, nil
+}
+
+type PacmanLayerAnalyzer struct {
+}
+
+func (a PacmanLayerAnalyzer) Name() string {
+	return "PacmanLayerAnalyzer"
+}
+
+// PacmanDiff compares the packages installed, removed or updated by pacman in each layer of two images.
+func (a PacmanLayerAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := singleVersionLayerDiff(image1, image2, a)
+	return diff, err
+}
+
+func (a PacmanLayerAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := singleVersionLayerAnalysis(image, a)
+	return analysis, err
+}
+
+func (a PacmanLayerAnalyzer) getPackages(image pkgutil.Image) ([]map[string]util.PackageInfo, error) {
+	var packages []map[string]util.PackageInfo
+	if _, err := os.Stat(image.FSPath); err != nil {
+		// invalid image directory path
+		return packages, err
+	}
+	if _, err := os.Stat(filepath.Join(image.FSPath, pacmanLocalDB)); err != nil {
+		// local database does not exist in this image
+		return packages, nil
+	}
+	states, err := getLayerPackageStates(image, readPacmanPackages)
+	if err != nil {
+		return packages, err
+	}
+	for _, state := range states {
+		layerPackages := make(map[string]util.PackageInfo)
+		for name, infos := range state {
+			layerPackages[name] = infos[""]
+		}
+		packages = append(packages, layerPackages)
+	}
+	return packages, nil
+}
+
+// readPacmanPackages returns the packages recorded in the local pacman database of
+// the image filesystem, marked by their database entry.
+func readPacmanPackages(image pkgutil.Image) ([]layerPackage, error) {
+	var packages []layerPackage
+	dbPath := filepath.Join(image.FSPath, pacmanLocalDB)
+	entries, err := ioutil.ReadDir(dbPath)
+	if err != nil {
+		// local database does not exist in this layer
+		return packages, nil
+	}
+	for _, entry := range entries {
+		// each package is a {name}-{version}-{release} directory, next to the ALPM_DB_VERSION file
+		if !entry.IsDir() || pkgutil.IsWhiteout(entry.Name()) {
+			continue
+		}
+		descPath := filepath.Join(dbPath, entry.Name(), "desc")
+		if _, err := os.Stat(descPath); err != nil {
+			continue
+		}
+		name, info, err := readPacmanDesc(descPath)
+		if err != nil {
+			logrus.Warningf("Error reading pacman package %s: %s", descPath, err)
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		packages = append(packages, layerPackage{
+			name:   name,
+			marker: filepath.Join(pacmanLocalDB, entry.Name()),
+			info:   info,
+		})
+	}
+	return packages, nil
+}
+
+// readPacmanDesc parses a desc file, made of %FIELD% headers each followed by
+// one value per line up to an empty line.
+func readPacmanDesc(path string) (string, util.PackageInfo, error) {
+	info := util.PackageInfo{Size: -1, Reason: pacmanReasonExplicit}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", info, err
+	}
+	defer file.Close()
+
+	var name, field string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			field = ""
+			continue
+		}
+		if strings.HasPrefix(line, "%") && strings.HasSuffix(line, "%") {
+			field = line
+			continue
+		}
+		switch field {
+		case "%NAME%":
+			name = line
+		case "%VERSION%":
+			info.Version = line
+		case "%ARCH%":
+			info.Arch = line
+		case "%SIZE%":
+			// installed size, in bytes
+			size, err := strconv.ParseInt(line, 10, 64)
+			if err != nil {
+				logrus.Errorf("Could not get size for %s: %s", path, err)
+				size = -1
+			}
+			info.Size = size
+		case "%REASON%":
+			// the field is only written for packages installed as dependencies
+			if line == "1" {
+				info.Reason = pacmanReasonDependency
+			}
+		}
+	}
+	return name, info, scanner.Err()
+}
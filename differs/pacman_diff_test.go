@@ -0,0 +1,98 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+func TestGetPacmanPackages(t *testing.T) {
+	testCases := []struct {
+		descrip  string
+		path     string
+		expected map[string]util.PackageInfo
+		err      bool
+	}{
+		{
+			descrip:  "no directory",
+			path:     "testDirs/notThere",
+			expected: map[string]util.PackageInfo{},
+			err:      true,
+		},
+		{
+			descrip:  "no packages",
+			path:     "testDirs/noPackages",
+			expected: map[string]util.PackageInfo{},
+		},
+		{
+			descrip: "packages in expected location",
+			path:    "testDirs/pacmanTests/layer1",
+			expected: map[string]util.PackageInfo{
+				"glibc": {Version: "2.38-7", Size: 48627452, Arch: "x86_64", Reason: "dependency"},
+				"curl":  {Version: "8.4.0-2", Size: 1861632, Arch: "x86_64", Reason: "explicit"}},
+		},
+	}
+	for _, test := range testCases {
+		d := PacmanAnalyzer{}
+		image := pkgutil.Image{FSPath: test.path}
+		packages, err := d.getPackages(image)
+		if err != nil && !test.err {
+			t.Errorf("Got unexpected error: %s", err)
+		}
+		if err == nil && test.err {
+			t.Errorf("Expected error but got none.")
+		}
+		if !reflect.DeepEqual(packages, test.expected) {
+			t.Errorf("%s: Expected: %v but got: %v", test.descrip, test.expected, packages)
+		}
+	}
+}
+
+func TestGetPacmanLayerPackages(t *testing.T) {
+	image := pkgutil.Image{
+		FSPath: "testDirs/pacmanTests/layer1",
+		Layers: []pkgutil.Layer{
+			{FSPath: "testDirs/pacmanTests/layer1"},
+			{FSPath: "testDirs/pacmanTests/layer2"},
+		},
+	}
+	glibc := util.PackageInfo{Version: "2.38-7", Size: 48627452, Arch: "x86_64", Reason: "dependency"}
+	expected := []map[string]util.PackageInfo{
+		{
+			"glibc": glibc,
+			"curl":  {Version: "8.4.0-2", Size: 1861632, Arch: "x86_64", Reason: "explicit"},
+		},
+		{
+			// the upgrade whites out the previous database entry of curl
+			"glibc": glibc,
+			"curl":  {Version: "8.5.0-1", Size: 1884160, Arch: "x86_64", Reason: "explicit"},
+			"bash":  {Version: "5.2.021-1", Size: 9220096, Arch: "x86_64", Reason: "dependency"},
+		},
+	}
+
+	packages, err := PacmanLayerAnalyzer{}.getPackages(image)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+}
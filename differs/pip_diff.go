@@ -18,6 +18,7 @@ package differs
 
 import (
 	"bufio"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -48,16 +49,54 @@ func (a PipAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
 }
 
 func (a PipAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]util.PackageInfo, error) {
-	path := image.FSPath
 	packages := make(map[string]map[string]util.PackageInfo)
+	pkgs, err := readPipPackages(image)
+	if err != nil {
+		return packages, err
+	}
+	for _, pkg := range pkgs {
+		addToMap(packages, pkg.name, pkg.path, pkg.info)
+	}
+	return packages, nil
+}
+
+type PipLayerAnalyzer struct {
+}
+
+func (a PipLayerAnalyzer) Name() string {
+	return "PipLayerAnalyzer"
+}
+
+// PipLayerDiff compares the pip-installed Python packages added, removed or updated by each layer of two images.
+func (a PipLayerAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := multiVersionLayerDiff(image1, image2, a)
+	return diff, err
+}
+
+func (a PipLayerAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := multiVersionLayerAnalysis(image, a)
+	return analysis, err
+}
+
+func (a PipLayerAnalyzer) getPackages(image pkgutil.Image) ([]map[string]map[string]util.PackageInfo, error) {
+	return getLayerPackageStates(image, readPipPackages)
+}
+
+// readPipPackages returns the Python packages found in the image filesystem, marked
+// by their egg-info or dist-info metadata.
+func readPipPackages(image pkgutil.Image) ([]layerPackage, error) {
+	path := image.FSPath
+	var packages []layerPackage
 	pythonPaths := []string{}
 	config, err := image.Image.ConfigFile()
 	if err != nil {
 		return packages, err
 	}
 	if config.Config.Env != nil {
-		paths := getPythonPaths(config.Config.Env)
-		pythonPaths = append(pythonPaths, paths...)
+		// PYTHONPATH entries refer to the image filesystem
+		for _, pythonPath := range getPythonPaths(config.Config.Env) {
+			pythonPaths = append(pythonPaths, filepath.Join(path, pythonPath))
+		}
 	}
 	pythonVersions, err := getPythonVersion(path)
 	if err != nil {
@@ -75,7 +114,16 @@ func (a PipAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]uti
 		pythonPaths = append(pythonPaths, filepath.Join(path, "usr/local/lib", pythonVersion, "site-packages"))
 	}
 
+	sitePackages, venvs := findPythonEnvironments(path)
+	pythonPaths = append(pythonPaths, sitePackages...)
+
+	seen := make(map[string]bool)
 	for _, pythonPath := range pythonPaths {
+		pythonPath = filepath.Clean(pythonPath)
+		if seen[pythonPath] {
+			continue
+		}
+		seen[pythonPath] = true
 		contents, err := ioutil.ReadDir(pythonPath)
 		if err != nil {
 			// python version folder doesn't have a site-packages folder
@@ -85,6 +133,9 @@ func (a PipAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]uti
 		for i := 0; i < len(contents); i++ {
 			c := contents[i]
 			fileName := c.Name()
+			if pkgutil.IsWhiteout(fileName) {
+				continue
+			}
 			var metadata *os.File
 			var err error
 			if strings.HasSuffix(fileName, "egg-info") {
@@ -104,7 +155,7 @@ func (a PipAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]uti
 				continue
 			}
 
-			var line, packageName, version string
+			var packageName, version string
 			if metadata == nil {
 				// unable to open metadata file: try reading the package itself
 				mPath := filepath.Join(pythonPath, fileName)
@@ -123,18 +174,15 @@ func (a PipAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]uti
 				}
 			}
 
+			var fields map[string][]string
 			if metadata != nil {
-				scanner := bufio.NewScanner(metadata)
-				scanner.Split(bufio.ScanLines)
-				for scanner.Scan() {
-					line = scanner.Text()
-					if strings.HasPrefix(line, "Name") {
-						packageName = strings.Split(line, ": ")[1]
-						// next line is always the version
-						scanner.Scan()
-						version = strings.Split(scanner.Text(), ": ")[1]
-						break
-					}
+				fields = readPythonMetadata(metadata)
+				metadata.Close()
+				if name := fields["Name"]; len(name) > 0 {
+					packageName = name[0]
+				}
+				if v := fields["Version"]; len(v) > 0 {
+					version = v[0]
 				}
 			}
 
@@ -173,15 +221,135 @@ func (a PipAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]uti
 				}
 			}
 
-			currPackage := util.PackageInfo{Version: version, Size: size}
-			mapPath := strings.Replace(pythonPath, path, "", 1)
-			addToMap(packages, packageName, mapPath, currPackage)
+			mapPath := strings.Replace(pythonPath, filepath.Clean(path), "", 1)
+			currPackage := util.PackageInfo{Version: version, Size: size, Environment: getPythonEnvironment(mapPath, venvs)}
+			setPythonMetadata(&currPackage, fields)
+			currPackage.Arch = getWheelPlatform(filepath.Join(pythonPath, fileName, "WHEEL"))
+			packages = append(packages, layerPackage{
+				name:   packageName,
+				path:   mapPath,
+				marker: filepath.Join(mapPath, fileName),
+				info:   currPackage,
+			})
 		}
 	}
 
 	return packages, nil
 }
 
+// readPythonMetadata parses the headers of a METADATA or PKG-INFO file, in email
+// header format, up to the blank line starting the description. Fields such as
+// Requires-Dist may occur several times.
+func readPythonMetadata(reader io.Reader) map[string][]string {
+	fields := make(map[string][]string)
+	var key string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		if key != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			// continuation of a folded header
+			values := fields[key]
+			values[len(values)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			key = ""
+			continue
+		}
+		key = parts[0]
+		fields[key] = append(fields[key], strings.TrimSpace(parts[1]))
+	}
+	return fields
+}
+
+// setPythonMetadata records the license, maintainer, homepage and requirements
+// declared by the metadata of a Python package.
+func setPythonMetadata(info *util.PackageInfo, fields map[string][]string) {
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			for _, value := range fields[key] {
+				if value != "" && value != "UNKNOWN" {
+					return value
+				}
+			}
+		}
+		return ""
+	}
+	info.License = first("License-Expression", "License")
+	if info.License == "" || len(info.License) > 100 {
+		// no license, or its full text, folded into a single line by
+		// readPythonMetadata: fall back to the license classifiers
+		if classifiers := getLicenseClassifiers(fields["Classifier"]); len(classifiers) > 0 {
+			info.License = strings.Join(classifiers, " AND ")
+		}
+	}
+	info.Maintainer = first("Maintainer", "Author", "Maintainer-email", "Author-email")
+	info.Homepage = first("Home-page")
+	if info.Homepage == "" {
+		for _, url := range fields["Project-URL"] {
+			// e.g. "Homepage, https://requests.readthedocs.io"
+			parts := strings.SplitN(url, ",", 2)
+			if len(parts) == 2 && strings.EqualFold(strings.TrimSpace(parts[0]), "homepage") {
+				info.Homepage = strings.TrimSpace(parts[1])
+				break
+			}
+		}
+	}
+	info.Dependencies = fields["Requires-Dist"]
+}
+
+// licenseAbbreviation matches a license name followed by its abbreviation, e.g.
+// "GNU General Public License v2 or later (GPLv2+)".
+var licenseAbbreviation = regexp.MustCompile(`^(.*?)\s*\(([^()]+)\)$`)
+
+// pythonVersionDir matches the library directory of a Python interpreter, e.g. python3.11.
+var pythonVersionDir = regexp.MustCompile(`^python[0-9]+\.[0-9]+$`)
+
+// getLicenseClassifiers returns the licenses named by the "License ::" trove
+// classifiers of a package, preferring their abbreviation when it is a known license.
+func getLicenseClassifiers(classifiers []string) []string {
+	var licenses []string
+	for _, classifier := range classifiers {
+		parts := strings.Split(classifier, "::")
+		if len(parts) < 2 || strings.TrimSpace(parts[0]) != "License" {
+			continue
+		}
+		license := strings.TrimSpace(parts[len(parts)-1])
+		if license == "OSI Approved" {
+			continue
+		}
+		if m := licenseAbbreviation.FindStringSubmatch(license); m != nil {
+			license = m[1]
+			if util.NormalizeLicense(m[2]) != m[2] {
+				license = m[2]
+			}
+		}
+		licenses = append(licenses, license)
+	}
+	return licenses
+}
+
+// getWheelPlatform returns the platform a wheel was built for from the tag recorded
+// in its WHEEL file, e.g. "manylinux_2_17_x86_64" from "cp311-cp311-manylinux_2_17_x86_64".
+// Pure Python wheels, tagged "any", have no platform.
+func getWheelPlatform(wheelPath string) string {
+	wheel, err := os.Open(wheelPath)
+	if err != nil {
+		return ""
+	}
+	defer wheel.Close()
+	for _, tag := range readPythonMetadata(wheel)["Tag"] {
+		if parts := strings.Split(tag, "-"); len(parts) == 3 && parts[2] != "any" {
+			return parts[2]
+		}
+	}
+	return ""
+}
+
 func addToMap(packages map[string]map[string]util.PackageInfo, pack string, path string, packInfo util.PackageInfo) {
 	if _, ok := packages[pack]; !ok {
 		// package not yet seen
@@ -195,8 +363,6 @@ func addToMap(packages map[string]map[string]util.PackageInfo, pack string, path
 
 func getPythonVersion(pathToLayer string) ([]string, error) {
 	matches := []string{}
-	pattern := regexp.MustCompile("^python[0-9]+\\.[0-9]+$")
-
 	libPaths := []string{"usr/local/lib", "usr/lib"}
 	for _, lp := range libPaths {
 		libPath := filepath.Join(pathToLayer, lp)
@@ -206,7 +372,7 @@ func getPythonVersion(pathToLayer string) ([]string, error) {
 			continue
 		}
 		for _, file := range libContents {
-			match := pattern.FindString(file.Name())
+			match := pythonVersionDir.FindString(file.Name())
 			if match != "" {
 				matches = append(matches, match)
 			}
@@ -215,6 +381,49 @@ func getPythonVersion(pathToLayer string) ([]string, error) {
 	return matches, nil
 }
 
+// findPythonEnvironments walks the image filesystem at path and returns the
+// site-packages and dist-packages directories found anywhere in it, along with the
+// virtualenvs, identified by their pyvenv.cfg, relative to the image root.
+func findPythonEnvironments(path string) (sitePackages []string, venvs []string) {
+	filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			logrus.Debugf("Unable to read %s: %s", file, err)
+			return nil
+		}
+		if !info.IsDir() {
+			if info.Name() == "pyvenv.cfg" {
+				venvs = append(venvs, "/"+strings.TrimPrefix(filepath.Dir(strings.TrimPrefix(file, filepath.Clean(path))), "/"))
+			}
+			return nil
+		}
+		switch info.Name() {
+		case "site-packages", "dist-packages":
+			sitePackages = append(sitePackages, file)
+			return filepath.SkipDir
+		case "node_modules", ".git":
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return sitePackages, venvs
+}
+
+// getPythonEnvironment returns the virtualenv the packages installed at pythonPath
+// belong to, or else the pythonX.Y interpreter whose library directory holds them.
+func getPythonEnvironment(pythonPath string, venvs []string) string {
+	for _, venv := range venvs {
+		if pkgutil.HasFilepathPrefix(pythonPath, venv) {
+			return venv
+		}
+	}
+	for _, dir := range strings.Split(pythonPath, "/") {
+		if pythonVersionDir.MatchString(dir) {
+			return dir
+		}
+	}
+	return ""
+}
+
 func getPythonPaths(vars []string) []string {
 	paths := []string{}
 	for _, envVar := range vars {
@@ -18,6 +18,7 @@ package differs
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
@@ -102,13 +103,13 @@ func TestGetPythonPackages(t *testing.T) {
 			},
 			expectedPackages: map[string]map[string]util.PackageInfo{
 				"packageone": {
-					"/usr/local/lib/python3.6/site-packages": {Version: "3.6.9", Size: 0},
-					"/usr/local/lib/python2.7/site-packages": {Version: "0.1.1", Size: 0},
+					"/usr/local/lib/python3.6/site-packages": {Version: "3.6.9", Size: 0, Environment: "python3.6"},
+					"/usr/local/lib/python2.7/site-packages": {Version: "0.1.1", Size: 0, Environment: "python2.7"},
 				},
-				"packagetwo": {"/usr/local/lib/python3.6/site-packages": {Version: "4.6.2", Size: 0}},
-				"script1":    {"/usr/local/lib/python3.6/site-packages": {Version: "1.0", Size: 0}},
-				"script2":    {"/usr/local/lib/python3.6/site-packages": {Version: "2.0", Size: 0}},
-				"script3":    {"/usr/local/lib/python2.7/site-packages": {Version: "3.0", Size: 0}},
+				"packagetwo": {"/usr/local/lib/python3.6/site-packages": {Version: "4.6.2", Size: 0, Environment: "python3.6"}},
+				"script1":    {"/usr/local/lib/python3.6/site-packages": {Version: "1.0", Size: 0, Environment: "python3.6"}},
+				"script2":    {"/usr/local/lib/python3.6/site-packages": {Version: "2.0", Size: 0, Environment: "python3.6"}},
+				"script3":    {"/usr/local/lib/python2.7/site-packages": {Version: "3.0", Size: 0, Environment: "python2.7"}},
 			},
 		},
 		{
@@ -120,10 +121,10 @@ func TestGetPythonPackages(t *testing.T) {
 				},
 			},
 			expectedPackages: map[string]map[string]util.PackageInfo{
-				"packageone": {"/usr/local/lib/python3.6/site-packages": {Version: "3.6.9", Size: 0}},
-				"packagetwo": {"/usr/local/lib/python3.6/site-packages": {Version: "4.6.2", Size: 0}},
-				"script1":    {"/usr/local/lib/python3.6/site-packages": {Version: "1.0", Size: 0}},
-				"script2":    {"/usr/local/lib/python3.6/site-packages": {Version: "2.0", Size: 0}},
+				"packageone": {"/usr/local/lib/python3.6/site-packages": {Version: "3.6.9", Size: 0, Environment: "python3.6"}},
+				"packagetwo": {"/usr/local/lib/python3.6/site-packages": {Version: "4.6.2", Size: 0, Environment: "python3.6"}},
+				"script1":    {"/usr/local/lib/python3.6/site-packages": {Version: "1.0", Size: 0, Environment: "python3.6"}},
+				"script2":    {"/usr/local/lib/python3.6/site-packages": {Version: "2.0", Size: 0, Environment: "python3.6"}},
 			},
 		},
 		{
@@ -133,14 +134,14 @@ func TestGetPythonPackages(t *testing.T) {
 				Image: &pkgutil.TestImage{
 					Config: &v1.ConfigFile{
 						Config: v1.Config{
-							Env: []string{"PYTHONPATH=testDirs/pipTests/pythonPathTests/pythonPath1:testDirs/pipTests/pythonPathTests/pythonPath2/subdir", "ENVVAR2=something"},
+							Env: []string{"PYTHONPATH=/pythonPath1:/pythonPath2/subdir", "ENVVAR2=something"},
 						},
 					},
 				},
 			},
 			expectedPackages: map[string]map[string]util.PackageInfo{
-				"packageone":   {"/usr/local/lib/python3.6/site-packages": {Version: "3.6.9", Size: 0}},
-				"packagetwo":   {"/usr/local/lib/python3.6/site-packages": {Version: "4.6.2", Size: 0}},
+				"packageone":   {"/usr/local/lib/python3.6/site-packages": {Version: "3.6.9", Size: 0, Environment: "python3.6"}},
+				"packagetwo":   {"/usr/local/lib/python3.6/site-packages": {Version: "4.6.2", Size: 0, Environment: "python3.6"}},
 				"packagefive":  {"/pythonPath2/subdir": {Version: "3.6.9", Size: 0}},
 				"packagesix":   {"/pythonPath1": {Version: "3.6.9", Size: 0}},
 				"packageseven": {"/pythonPath1": {Version: "4.6.2", Size: 0}},
@@ -159,8 +160,23 @@ func TestGetPythonPackages(t *testing.T) {
 				},
 			},
 			expectedPackages: map[string]map[string]util.PackageInfo{
-				"packageone": {"/usr/local/lib/python3.6/site-packages": {Version: "3.6.9", Size: 0}},
-				"packagetwo": {"/usr/local/lib/python3.6/site-packages": {Version: "4.6.2", Size: 0}},
+				"packageone": {"/usr/local/lib/python3.6/site-packages": {Version: "3.6.9", Size: 0, Environment: "python3.6"}},
+				"packagetwo": {"/usr/local/lib/python3.6/site-packages": {Version: "4.6.2", Size: 0, Environment: "python3.6"}},
+			},
+		},
+		{
+			descrip: "venvTests, virtualenvs outside the default paths",
+			image: pkgutil.Image{
+				FSPath: "testDirs/pipTests/venvTests",
+				Image: &pkgutil.TestImage{
+					Config: &v1.ConfigFile{},
+				},
+			},
+			expectedPackages: map[string]map[string]util.PackageInfo{
+				"requests": {
+					"/opt/venv/lib/python3.9/site-packages":   {Version: "2.22.0", Size: 10, Environment: "/opt/venv"},
+					"/app/.venv/lib/python3.11/site-packages": {Version: "2.31.0", Size: 10, Environment: "/app/.venv"},
+				},
 			},
 		},
 	}
@@ -172,3 +188,105 @@ func TestGetPythonPackages(t *testing.T) {
 		}
 	}
 }
+
+func TestGetPipLayerPackages(t *testing.T) {
+	image := pkgutil.Image{
+		FSPath: "testDirs/pipLayers",
+		Image:  &pkgutil.TestImage{Config: &v1.ConfigFile{}},
+		Layers: []pkgutil.Layer{
+			{FSPath: "testDirs/pipLayers/layer1"},
+			{FSPath: "testDirs/pipLayers/layer2"},
+			{FSPath: "testDirs/pipLayers/layer3"},
+		},
+	}
+	sitePackages := "/usr/local/lib/python3.11/site-packages"
+	expected := []map[string]map[string]util.PackageInfo{
+		{
+			"requests": {sitePackages: {Version: "2.31.0", Size: 11, Environment: "python3.11"}},
+			"six":      {sitePackages: {Version: "1.16.0", Size: 6, Environment: "python3.11"}},
+		},
+		// the dist-info of requests is deleted through a whiteout
+		{
+			"six": {sitePackages: {Version: "1.16.0", Size: 6, Environment: "python3.11"}},
+		},
+		// site-packages is replaced by an opaque directory
+		{
+			"urllib3": {sitePackages: {Version: "2.0.7", Size: 10, Environment: "python3.11"}},
+		},
+	}
+
+	packages, err := PipLayerAnalyzer{}.getPackages(image)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+}
+
+func TestSetPythonMetadata(t *testing.T) {
+	metadata := `Metadata-Version: 2.1
+Name: requests
+Version: 2.31.0
+Summary: Python HTTP for Humans.
+Home-page: UNKNOWN
+Author: Kenneth Reitz
+License: Apache 2.0
+Project-URL: Documentation, https://requests.readthedocs.io
+Project-URL: Homepage, https://requests.readthedocs.io/en/latest
+Requires-Dist: charset-normalizer (<4,>=2)
+Requires-Dist: idna (<4,>=2.5)
+Requires-Dist: PySocks (!=1.5.7,>=1.5.6) ; extra == 'socks'
+
+Requires-Dist: this is the description
+`
+	fields := readPythonMetadata(strings.NewReader(metadata))
+	if fields["Name"][0] != "requests" || fields["Version"][0] != "2.31.0" {
+		t.Errorf("Expected requests 2.31.0 but got %v %v", fields["Name"], fields["Version"])
+	}
+	info := util.PackageInfo{Version: "2.31.0"}
+	setPythonMetadata(&info, fields)
+	expected := util.PackageInfo{
+		Version:    "2.31.0",
+		License:    "Apache 2.0",
+		Maintainer: "Kenneth Reitz",
+		Homepage:   "https://requests.readthedocs.io/en/latest",
+		Dependencies: []string{"charset-normalizer (<4,>=2)", "idna (<4,>=2.5)",
+			"PySocks (!=1.5.7,>=1.5.6) ; extra == 'socks'"},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, info)
+	}
+}
+
+func TestSetPythonMetadataLicenseText(t *testing.T) {
+	metadata := `Metadata-Version: 2.1
+Name: attrs
+Version: 23.1.0
+License: The MIT License (MIT)
+        
+        Permission is hereby granted, free of charge, to any person obtaining a copy
+        of this software and associated documentation files (the "Software"), to deal
+Classifier: License :: OSI Approved :: MIT License
+Classifier: Programming Language :: Python :: 3
+`
+	info := util.PackageInfo{}
+	setPythonMetadata(&info, readPythonMetadata(strings.NewReader(metadata)))
+	if info.License != "MIT License" {
+		t.Errorf("Expected the license of the classifiers but got: %q", info.License)
+	}
+}
+
+func TestGetLicenseClassifiers(t *testing.T) {
+	classifiers := []string{
+		"Development Status :: 5 - Production/Stable",
+		"License :: OSI Approved",
+		"License :: OSI Approved :: GNU General Public License v2 or later (GPLv2+)",
+		"License :: OSI Approved :: ISC License (ISCL)",
+		"License :: Public Domain",
+	}
+	expected := []string{"GPLv2+", "ISC License", "Public Domain"}
+	if licenses := getLicenseClassifiers(classifiers); !reflect.DeepEqual(licenses, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, licenses)
+	}
+}
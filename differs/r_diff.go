@@ -0,0 +1,150 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/sirupsen/logrus"
+)
+
+type RAnalyzer struct {
+}
+
+func (a RAnalyzer) Name() string {
+	return "RAnalyzer"
+}
+
+// RDiff compares the R packages installed in each library of two images.
+func (a RAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	diff, err := multiVersionDiff(image1, image2, a)
+	return diff, err
+}
+
+func (a RAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	analysis, err := multiVersionAnalysis(image, a)
+	return analysis, err
+}
+
+// getPackages returns the R packages of the image keyed by name and library path.
+func (a RAnalyzer) getPackages(image pkgutil.Image) (map[string]map[string]util.PackageInfo, error) {
+	root := filepath.Clean(image.FSPath)
+	packages := make(map[string]map[string]util.PackageInfo)
+	if _, err := os.Stat(root); err != nil {
+		// path provided invalid
+		return packages, err
+	}
+
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			logrus.Debugf("Unable to read %s: %s", file, err)
+			return nil
+		}
+		if !info.IsDir() || !isRLibrary(file) {
+			return nil
+		}
+		library := "/" + strings.TrimPrefix(strings.TrimPrefix(file, root), "/")
+		for name, pkg := range readRLibrary(file) {
+			addToMap(packages, name, library, pkg)
+		}
+		// packages don't nest other libraries
+		return filepath.SkipDir
+	})
+	return packages, err
+}
+
+// isRLibrary reports whether dir is an R library, such as /usr/lib/R/library
+// or /usr/local/lib/R/site-library.
+func isRLibrary(dir string) bool {
+	name := filepath.Base(dir)
+	return (name == "library" || name == "site-library") && filepath.Base(filepath.Dir(dir)) == "R"
+}
+
+// readRLibrary returns the packages of a library, each installed in a directory
+// holding its DESCRIPTION.
+func readRLibrary(library string) map[string]util.PackageInfo {
+	packages := make(map[string]util.PackageInfo)
+	contents, err := ioutil.ReadDir(library)
+	if err != nil {
+		logrus.Warningf("Unable to read R library %s: %s", library, err)
+		return packages
+	}
+	for _, c := range contents {
+		pkgDir := filepath.Join(library, c.Name())
+		fields, err := readDescription(filepath.Join(pkgDir, "DESCRIPTION"))
+		if err != nil {
+			continue
+		}
+		name := firstNonEmpty(fields["Package"], c.Name())
+		rVersion, platform := getRBuild(fields["Built"])
+		packages[name] = util.PackageInfo{
+			Version:     fields["Version"],
+			Size:        pkgutil.GetSize(pkgDir),
+			Environment: rVersion,
+			Arch:        platform,
+			// Bioconductor and remotes installs don't record a Repository
+			Repository: firstNonEmpty(fields["Repository"], fields["RemoteType"]),
+		}
+	}
+	return packages
+}
+
+// readDescription parses a DESCRIPTION file, in Debian control format: fields
+// are "Key: value" lines, continued by lines starting with whitespace.
+func readDescription(path string) (map[string]string, error) {
+	fields := make(map[string]string)
+	file, err := os.Open(path)
+	if err != nil {
+		return fields, err
+	}
+	defer file.Close()
+
+	var key string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if key != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			fields[key] += " " + strings.TrimSpace(line)
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			key = ""
+			continue
+		}
+		key = parts[0]
+		fields[key] = strings.TrimSpace(parts[1])
+	}
+	return fields, scanner.Err()
+}
+
+// getRBuild returns the R version a package was built with and, for packages with
+// compiled code, the platform from its Built field, e.g. "R 4.3.1" and
+// "x86_64-pc-linux-gnu" from "R 4.3.1; x86_64-pc-linux-gnu; 2023-08-10 12:00:00 UTC; unix".
+func getRBuild(built string) (string, string) {
+	parts := strings.Split(built, ";")
+	if len(parts) < 2 {
+		return strings.TrimSpace(parts[0]), ""
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+func TestGetRPackages(t *testing.T) {
+	testCases := []struct {
+		descrip  string
+		path     string
+		expected map[string]map[string]util.PackageInfo
+		err      bool
+	}{
+		{
+			descrip:  "no directory",
+			path:     "testDirs/notThere",
+			expected: map[string]map[string]util.PackageInfo{},
+			err:      true,
+		},
+		{
+			descrip:  "no packages",
+			path:     "testDirs/noPackages",
+			expected: map[string]map[string]util.PackageInfo{},
+		},
+		{
+			descrip: "base and site libraries",
+			path:    "testDirs/rTests",
+			expected: map[string]map[string]util.PackageInfo{
+				"stats": {"/usr/lib/R/library": {Version: "4.3.1", Size: 139, Environment: "R 4.3.1", Arch: "x86_64-pc-linux-gnu"}},
+				"jsonlite": {"/usr/local/lib/R/site-library": {Version: "1.8.7", Size: 270, Environment: "R 4.3.1",
+					Arch: "x86_64-pc-linux-gnu", Repository: "CRAN"}},
+				"BiocGenerics": {"/usr/local/lib/R/site-library": {Version: "0.46.0", Size: 112, Environment: "R 4.3.1"}},
+			},
+		},
+	}
+	for _, test := range testCases {
+		d := RAnalyzer{}
+		image := pkgutil.Image{FSPath: test.path}
+		packages, err := d.getPackages(image)
+		if err != nil && !test.err {
+			t.Errorf("Got unexpected error: %s", err)
+		}
+		if err == nil && test.err {
+			t.Errorf("Expected error but got none.")
+		}
+		if !reflect.DeepEqual(packages, test.expected) {
+			t.Errorf("%s: Expected: %v but got: %v", test.descrip, test.expected, packages)
+		}
+	}
+}
+
+func TestReadDescription(t *testing.T) {
+	fields, err := readDescription("testDirs/rTests/usr/local/lib/R/site-library/jsonlite/DESCRIPTION")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	expected := "A reasonably fast JSON parser and generator, optimized for statistical data."
+	if fields["Description"] != expected {
+		t.Errorf("Expected continued field %q but got %q", expected, fields["Description"])
+	}
+}
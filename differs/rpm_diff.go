@@ -51,8 +51,12 @@ const rpmMacros string = "/usr/lib/rpm/macros"
 // RPM command to extract packages from the rpm database
 var rpmCmd = []string{
 	"rpm", "--nodigest", "--nosignature",
-	"-qa", "--qf", "%{NAME}\t%{VERSION}-%{RELEASE}\t%{SIZE}\n",
+	"-qa", "--qf", "%{NAME}\t%{VERSION}-%{RELEASE}\t%{SIZE}\t%{ARCH}\t%{SOURCERPM}\t%{LICENSE}\t%{PACKAGER}\t%{URL}\t[%{REQUIRENAME},]\t%{EPOCH}\n",
 }
+
+// rpmNone is printed by rpm queries for unset tags
+const rpmNone = "(none)"
+
 var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 
 // daemonMutex is required to protect against other go-routines, as
@@ -90,14 +94,9 @@ func (a RPMAnalyzer) getPackages(image pkgutil.Image) (map[string]util.PackageIn
 		return packages, err
 	}
 
-	// try to find the rpm binary in bin/ or usr/bin/
-	rpmBinary := filepath.Join(path, "bin/rpm")
-	if _, err := os.Stat(rpmBinary); err != nil {
-		rpmBinary = filepath.Join(path, "usr/bin/rpm")
-		if _, err = os.Stat(rpmBinary); err != nil {
-			logrus.Errorf("Could not detect RPM binary in unpacked image %s", image.Source)
-			return packages, nil
-		}
+	if !hasRPMBinary(path) {
+		logrus.Errorf("Could not detect RPM binary in unpacked image %s", image.Source)
+		return packages, nil
 	}
 
 	packages, err := rpmDataFromImageFS(image)
@@ -108,6 +107,16 @@ func (a RPMAnalyzer) getPackages(image pkgutil.Image) (map[string]util.PackageIn
 	return packages, err
 }
 
+// hasRPMBinary checks for the rpm binary in bin/ or usr/bin/ of the image filesystem.
+func hasRPMBinary(root string) bool {
+	for _, rpmBinary := range []string{"bin/rpm", "usr/bin/rpm"} {
+		if _, err := os.Stat(filepath.Join(root, rpmBinary)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
 // rpmDataFromImageFS runs a local rpm binary, if any, to query the image
 // rpmdb and returns a map of installed packages.
 func rpmDataFromImageFS(image pkgutil.Image) (map[string]util.PackageInfo, error) {
@@ -159,20 +168,30 @@ func rpmEnvCheck(rootFSPath string) (string, error) {
 // rpmDataFromContainer runs image in a container, queries the data of
 // installed rpm packages and returns a map of packages.
 func rpmDataFromContainer(image v1.Image) (map[string]util.PackageInfo, error) {
-	packages := make(map[string]util.PackageInfo)
+	output, err := rpmQueryFromContainer(image, rpmCmd)
+	if err != nil {
+		return make(map[string]util.PackageInfo), err
+	}
+	return parsePackageData(output)
+}
+
+// rpmQueryFromContainer runs image in a container with the rpm query as
+// entrypoint and returns the lines of its output.
+func rpmQueryFromContainer(image v1.Image, query []string) ([]string, error) {
+	var output []string
 
 	client, err := godocker.NewClientFromEnv()
 	if err != nil {
-		return packages, err
+		return output, err
 	}
 	if err := lock(); err != nil {
-		return packages, err
+		return output, err
 	}
 
 	imageName, err := loadImageToDaemon(image)
 
 	if err != nil {
-		return packages, fmt.Errorf("Error loading image: %s", err)
+		return output, fmt.Errorf("Error loading image: %s", err)
 	}
 	unlock()
 
@@ -180,7 +199,7 @@ func rpmDataFromContainer(image v1.Image) (map[string]util.PackageInfo, error) {
 	defer logrus.Infof("Removing image %s", imageName)
 
 	contConf := godocker.Config{
-		Entrypoint: rpmCmd,
+		Entrypoint: query,
 		Image:      imageName,
 	}
 
@@ -191,7 +210,7 @@ func rpmDataFromContainer(image v1.Image) (map[string]util.PackageInfo, error) {
 	contOpts := godocker.CreateContainerOptions{Config: &contConf}
 	container, err := client.CreateContainer(contOpts)
 	if err != nil {
-		return packages, err
+		return output, err
 	}
 	logrus.Infof("Created container %s", container.ID)
 
@@ -201,12 +220,12 @@ func rpmDataFromContainer(image v1.Image) (map[string]util.PackageInfo, error) {
 	defer client.RemoveContainer(removeOpts)
 
 	if err := client.StartContainer(container.ID, &hostConf); err != nil {
-		return packages, err
+		return output, err
 	}
 
 	exitCode, err := client.WaitContainer(container.ID)
 	if err != nil {
-		return packages, err
+		return output, err
 	}
 
 	outBuf := new(bytes.Buffer)
@@ -221,15 +240,14 @@ func rpmDataFromContainer(image v1.Image) (map[string]util.PackageInfo, error) {
 	}
 
 	if err := client.Logs(logOpts); err != nil {
-		return packages, err
+		return output, err
 	}
 
 	if exitCode != 0 {
-		return packages, fmt.Errorf("non-zero exit code %d: %s", exitCode, errBuf.String())
+		return output, fmt.Errorf("non-zero exit code %d: %s", exitCode, errBuf.String())
 	}
 
-	output := strings.Split(outBuf.String(), "\n")
-	return parsePackageData(output)
+	return strings.Split(outBuf.String(), "\n"), nil
 }
 
 // parsePackageData parses the package data of each line in rpmOutput and
@@ -239,13 +257,18 @@ func parsePackageData(rpmOutput []string) (map[string]util.PackageInfo, error) {
 
 	for _, output := range rpmOutput {
 		spl := strings.Split(output, "\t")
-		if len(spl) != 3 {
+		if len(spl) != 10 {
 			// ignore the empty (last) line
 			if output != "" {
 				logrus.Errorf("unexpected rpm-query output: '%s'", output)
 			}
 			continue
 		}
+		for i, field := range spl {
+			if field == rpmNone {
+				spl[i] = ""
+			}
+		}
 		pkg := util.PackageInfo{}
 
 		var err error
@@ -255,12 +278,47 @@ func parsePackageData(rpmOutput []string) (map[string]util.PackageInfo, error) {
 		}
 
 		pkg.Version = spl[1]
+		pkg.Arch = spl[3]
+		pkg.Source = getRPMSourceName(spl[4])
+		pkg.License = spl[5]
+		pkg.Maintainer = spl[6]
+		pkg.Homepage = spl[7]
+		pkg.Dependencies = getRPMRequires(spl[8])
+		pkg.Epoch = spl[9]
 		packages[spl[0]] = pkg
 	}
 
 	return packages, nil
 }
 
+// getRPMSourceName returns the name of the source package from its file name,
+// e.g. "glibc" from "glibc-2.34-60.el9.src.rpm".
+func getRPMSourceName(sourceRPM string) string {
+	name := strings.TrimSuffix(strings.TrimSuffix(sourceRPM, ".rpm"), ".src")
+	for i := 0; i < 2; i++ {
+		// strip the release, then the version
+		if idx := strings.LastIndex(name, "-"); idx > 0 {
+			name = name[:idx]
+		}
+	}
+	return name
+}
+
+// getRPMRequires returns the distinct requirements of a package from their comma
+// terminated list, leaving out the rpmlib() features required from rpm itself.
+func getRPMRequires(requires string) []string {
+	var deps []string
+	seen := make(map[string]bool)
+	for _, req := range strings.Split(requires, ",") {
+		if req == "" || strings.HasPrefix(req, "rpmlib(") || seen[req] {
+			continue
+		}
+		seen[req] = true
+		deps = append(deps, req)
+	}
+	return deps
+}
+
 // loadImageToDaemon loads the image specified to the docker daemon.
 func loadImageToDaemon(img v1.Image) (string, error) {
 	tag := generateValidImageTag()
@@ -388,14 +446,9 @@ func (a RPMLayerAnalyzer) getPackages(image pkgutil.Image) ([]map[string]util.Pa
 		return packages, err
 	}
 
-	// try to find the rpm binary in bin/ or usr/bin/
-	rpmBinary := filepath.Join(path, "bin/rpm")
-	if _, err := os.Stat(rpmBinary); err != nil {
-		rpmBinary = filepath.Join(path, "usr/bin/rpm")
-		if _, err = os.Stat(rpmBinary); err != nil {
-			logrus.Errorf("Could not detect RPM binary in unpacked image %s", image.Source)
-			return packages, nil
-		}
+	if !hasRPMBinary(path) {
+		logrus.Errorf("Could not detect RPM binary in unpacked image %s", image.Source)
+		return packages, nil
 	}
 
 	packages, err := rpmDataFromLayerFS(image)
@@ -429,21 +482,38 @@ func rpmDataFromLayerFS(image pkgutil.Image) ([]map[string]util.PackageInfo, err
 // rpmDataFromFS runs a local rpm binary to query the image
 // rpmdb and returns a map of installed packages.
 func rpmDataFromFS(fsPath string, dbPath string) (map[string]util.PackageInfo, error) {
-	packages := make(map[string]util.PackageInfo)
-	if _, err := os.Stat(filepath.Join(fsPath, dbPath)); err == nil {
-		cmdArgs := append([]string{"--root", fsPath, "--dbpath", dbPath}, rpmCmd[1:]...)
-		out, err := exec.Command(rpmCmd[0], cmdArgs...).Output()
-		if err != nil {
-			logrus.Warnf("RPM call failed: %s", err.Error())
-			return packages, err
-		}
-		output := strings.Split(string(out), "\n")
-		packages, err = parsePackageData(output)
-		if err != nil {
-			return packages, err
-		}
+	output, err := rpmQueryFromFS(fsPath, dbPath, rpmCmd)
+	if err != nil {
+		return make(map[string]util.PackageInfo), err
 	}
-	return packages, nil
+	return parsePackageData(output)
+}
+
+// rpmQueryFromFS runs the rpm query with a local rpm binary against the rpmdb
+// of fsPath, if any, and returns the lines of its output.
+func rpmQueryFromFS(fsPath string, dbPath string, query []string) ([]string, error) {
+	if _, err := os.Stat(filepath.Join(fsPath, dbPath)); err != nil {
+		return nil, nil
+	}
+	cmdArgs := append([]string{"--root", fsPath, "--dbpath", dbPath}, query[1:]...)
+	out, err := exec.Command(query[0], cmdArgs...).Output()
+	if err != nil {
+		logrus.Warnf("RPM call failed: %s", err.Error())
+		return nil, err
+	}
+	return strings.Split(string(out), "\n"), nil
+}
+
+// rpmQuery runs the rpm query against the rpmdb of image, with a local rpm
+// binary if possible and in a container otherwise, and returns the lines of
+// its output.
+func rpmQuery(image pkgutil.Image, query []string) ([]string, error) {
+	dbPath, err := rpmEnvCheck(image.FSPath)
+	if err != nil {
+		logrus.Info("Couldn't retrieve RPM data from extracted filesystem; running query in container")
+		return rpmQueryFromContainer(image.Image, query)
+	}
+	return rpmQueryFromFS(image.FSPath, dbPath, query)
 }
 
 // rpmDataFromLayeredContainers runs a tmp image in a container for each layer,
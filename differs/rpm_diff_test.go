@@ -17,7 +17,10 @@ limitations under the License.
 package differs
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/GoogleContainerTools/container-diff/util"
 )
 
 // TestLockUnlock runs some lock-unlock cycles to make sure that close,
@@ -66,3 +69,27 @@ func TestGoRoutineRace(t *testing.T) {
 		t.Errorf("Other goroutine didn't lock although lock was released")
 	}
 }
+
+func TestParsePackageData(t *testing.T) {
+	output := []string{
+		"bash\t5.1.8-6.el9\t7738634\tx86_64\tbash-5.1.8-6.el9.src.rpm\tGPLv3+\tRocky Linux Build System\thttps://www.gnu.org/software/bash\t/bin/sh,filesystem,libc.so.6()(64bit),libc.so.6()(64bit),rpmlib(CompressedFileNames),\t(none)",
+		"openssl-libs\t3.0.7-24.el9\t6354823\tx86_64\topenssl-3.0.7-24.el9.src.rpm\tASL 2.0\tRocky Linux Build System\thttp://www.openssl.org/\tlibc.so.6()(64bit),\t1",
+		"gpg-pubkey\t350d275d-6279464b\t0\t(none)\t(none)\tpubkey\t(none)\t(none)\t\t(none)",
+		"",
+	}
+	expected := map[string]util.PackageInfo{
+		"bash": {Version: "5.1.8-6.el9", Size: 7738634, Arch: "x86_64", Source: "bash", License: "GPLv3+",
+			Maintainer: "Rocky Linux Build System", Homepage: "https://www.gnu.org/software/bash",
+			Dependencies: []string{"/bin/sh", "filesystem", "libc.so.6()(64bit)"}},
+		"openssl-libs": {Version: "3.0.7-24.el9", Size: 6354823, Arch: "x86_64", Source: "openssl", License: "ASL 2.0",
+			Maintainer: "Rocky Linux Build System", Homepage: "http://www.openssl.org/", Dependencies: []string{"libc.so.6()(64bit)"}, Epoch: "1"},
+		"gpg-pubkey": {Version: "350d275d-6279464b", Size: 0, License: "pubkey"},
+	}
+	packages, err := parsePackageData(output)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+}
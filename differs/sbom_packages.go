@@ -0,0 +1,121 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+// SupportsSBOM reports whether the analyzer can diff the packages listed by an
+// SBOM given in place of an image. Only the package analyzers can, as SBOMs
+// don't record image files, layers or configuration.
+func SupportsSBOM(analyzer Analyzer) bool {
+	switch analyzer.(type) {
+	case SingleVersionPackageAnalyzer, MultiVersionPackageAnalyzer:
+		return true
+	}
+	return false
+}
+
+// getSBOMPackages returns the packages of the SBOM of image listed for the
+// analyzer of the given name.
+func getSBOMPackages(image pkgutil.Image, analyzerName string) ([]util.SBOMPackage, error) {
+	sbomPackages, err := util.ReadSBOM(image.SBOMPath)
+	if err != nil {
+		return nil, err
+	}
+	analyzeType := strings.TrimSuffix(analyzerName, "Analyzer")
+	packages := []util.SBOMPackage{}
+	for _, pkg := range sbomPackages {
+		if pkg.Type == analyzeType {
+			packages = append(packages, pkg)
+		}
+	}
+	return packages, nil
+}
+
+// getSingleVersionPackages returns the packages found by the analyzer in the
+// image, or listed for it by the SBOM given in place of the image.
+func getSingleVersionPackages(image pkgutil.Image, analyzer SingleVersionPackageAnalyzer) (map[string]util.PackageInfo, error) {
+	if image.SBOMPath == "" {
+		packages, err := analyzer.getPackages(image)
+		setPackageURLs(image, analyzer.Name(), packages)
+		return packages, err
+	}
+	sbomPackages, err := getSBOMPackages(image, analyzer.Name())
+	if err != nil {
+		return nil, err
+	}
+	packages := make(map[string]util.PackageInfo)
+	for _, pkg := range sbomPackages {
+		pkg.Info.PURL = pkg.PURL
+		packages[pkg.Name] = pkg.Info
+	}
+	return packages, nil
+}
+
+// getMultiVersionPackages is the multi-version counterpart of getSingleVersionPackages.
+func getMultiVersionPackages(image pkgutil.Image, analyzer MultiVersionPackageAnalyzer) (map[string]map[string]util.PackageInfo, error) {
+	if image.SBOMPath == "" {
+		packages, err := analyzer.getPackages(image)
+		setMultiVersionPackageURLs(image, analyzer.Name(), packages)
+		return packages, err
+	}
+	sbomPackages, err := getSBOMPackages(image, analyzer.Name())
+	if err != nil {
+		return nil, err
+	}
+	packages := make(map[string]map[string]util.PackageInfo)
+	for _, pkg := range sbomPackages {
+		pkg.Info.PURL = pkg.PURL
+		addToMap(packages, pkg.Name, pkg.Path, pkg.Info)
+	}
+	return packages, nil
+}
+
+// sbomPackageInfo keeps the version, size and package URL of a package, the
+// version being all SBOMs reliably record, so that comparing an image with an
+// SBOM doesn't report the metadata missing from the SBOM as changed.
+func sbomPackageInfo(info util.PackageInfo) util.PackageInfo {
+	return util.PackageInfo{Version: info.Version, Size: info.Size, PURL: info.PURL}
+}
+
+// alignSBOMPackages reduces the packages of either side of an SBOM diff to
+// their version. SBOMs not written by container-diff don't record where
+// multi-version packages are installed, so the installations of such packages
+// are keyed by version on both sides instead of by path.
+func alignSBOMPackages(packages, other map[string]map[string]util.PackageInfo) map[string]map[string]util.PackageInfo {
+	aligned := make(map[string]map[string]util.PackageInfo)
+	for name, installations := range packages {
+		byVersion := hasUnknownPath(installations) || hasUnknownPath(other[name])
+		for path, info := range installations {
+			if byVersion {
+				path = info.Version
+			}
+			addToMap(aligned, name, path, sbomPackageInfo(info))
+		}
+	}
+	return aligned
+}
+
+func hasUnknownPath(installations map[string]util.PackageInfo) bool {
+	_, ok := installations[""]
+	return ok
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+func TestSBOMPackageDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sbom-diff")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"old.spdx.json": `{"spdxVersion": "SPDX-2.3", "packages": [
+			{"SPDXID": "SPDXRef-Image", "name": "app", "primaryPackagePurpose": "CONTAINER"},
+			{"SPDXID": "SPDXRef-1", "name": "bash", "versionInfo": "5.1-6", "sourceInfo": "acquired package info from the apt analyzer"},
+			{"SPDXID": "SPDXRef-2", "name": "curl", "versionInfo": "7.88.1-10+deb12u4",
+				"externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:deb/debian/curl@7.88.1-10%2Bdeb12u4"}]},
+			{"SPDXID": "SPDXRef-3", "name": "requests", "versionInfo": "2.28.0", "sourceInfo": "acquired package info from the pip analyzer: /usr/lib/python3/dist-packages"}
+		]}`,
+		"new.cdx.json": `{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": [
+			{"type": "library", "name": "bash", "version": "5.2.15-2+b2", "properties": [{"name": "container-diff:analyzer", "value": "apt"}]},
+			{"type": "library", "name": "curl", "version": "7.88.1-10+deb12u4", "purl": "pkg:deb/debian/curl@7.88.1-10%2Bdeb12u4"},
+			{"type": "library", "name": "requests", "version": "2.31.0", "purl": "pkg:pypi/requests@2.31.0"}
+		]}`,
+	})
+	image1 := pkgutil.Image{Source: "old.spdx.json", SBOMPath: filepath.Join(dir, "old.spdx.json")}
+	image2 := pkgutil.Image{Source: "new.cdx.json", SBOMPath: filepath.Join(dir, "new.cdx.json")}
+
+	aptDiff, err := singleVersionDiff(image1, image2, AptAnalyzer{})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	expectedApt := []util.Info{
+		{Package: "bash", Info1: util.PackageInfo{Version: "5.1-6"}, Info2: util.PackageInfo{Version: "5.2.15-2 b2"}, Change: util.VersionUpgrade},
+	}
+	if diff := aptDiff.Diff.(util.PackageDiff); !reflect.DeepEqual(diff.InfoDiff, expectedApt) {
+		t.Errorf("Expected: %v but got: %v", expectedApt, diff.InfoDiff)
+	}
+
+	// the CycloneDX document doesn't record where requests is installed
+	pipDiff, err := multiVersionDiff(image1, image2, PipAnalyzer{})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	diff := pipDiff.Diff.(util.MultiVersionPackageDiff)
+	if len(diff.Packages1) != 0 || len(diff.Packages2) != 0 {
+		t.Errorf("Expected no packages unique to either SBOM but got: %v and %v", diff.Packages1, diff.Packages2)
+	}
+	if len(diff.InfoDiff) != 1 || diff.InfoDiff[0].Package != "requests" {
+		t.Errorf("Expected requests to differ but got: %v", diff.InfoDiff)
+	}
+}
+
+func TestAlignSBOMPackages(t *testing.T) {
+	image := map[string]map[string]util.PackageInfo{
+		"six":  {"/usr/lib/python3/dist-packages": {Version: "1.16.0", Size: 10, License: "MIT"}},
+		"idna": {"/usr/lib/python3/dist-packages": {Version: "3.4"}, "/venv/lib/python3.11/site-packages": {Version: "3.6"}},
+	}
+	sbom := map[string]map[string]util.PackageInfo{
+		"six":  {"/usr/lib/python3/dist-packages": {Version: "1.16.0"}},
+		"idna": {"": {Version: "3.6"}},
+	}
+	expected := map[string]map[string]util.PackageInfo{
+		"six":  {"/usr/lib/python3/dist-packages": {Version: "1.16.0", Size: 10}},
+		"idna": {"3.4": {Version: "3.4"}, "3.6": {Version: "3.6"}},
+	}
+	if aligned := alignSBOMPackages(image, sbom); !reflect.DeepEqual(aligned, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, aligned)
+	}
+}
+
+func TestSupportsSBOM(t *testing.T) {
+	for analyzer, expected := range map[Analyzer]bool{
+		AptAnalyzer{}:      true,
+		NodeAnalyzer{}:     true,
+		AptLayerAnalyzer{}: false,
+		FileAnalyzer{}:     false,
+		LicenseAnalyzer{}:  false,
+	} {
+		if supported := SupportsSBOM(analyzer); supported != expected {
+			t.Errorf("%s: expected %t but got %t", analyzer.Name(), expected, supported)
+		}
+	}
+}
@@ -0,0 +1 @@
+numpy2
@@ -0,0 +1 @@
+# requests
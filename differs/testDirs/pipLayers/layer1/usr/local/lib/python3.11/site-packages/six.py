@@ -0,0 +1 @@
+# six
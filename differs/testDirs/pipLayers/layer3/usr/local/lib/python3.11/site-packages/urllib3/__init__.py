@@ -0,0 +1 @@
+# urllib3
@@ -0,0 +1,261 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+)
+
+// VulnDB is the directory of the OSV vulnerability database the vuln analyzer
+// matches packages against.
+var VulnDB string
+
+// osvDistroEcosystems maps the os-release IDs of distributions to the OSV
+// ecosystem of their packages, per package manager
+var osvDistroEcosystems = map[string]map[string]string{
+	"apt": {"debian": "Debian", "ubuntu": "Ubuntu"},
+	"rpm": {
+		"rhel":                "Red Hat",
+		"rocky":               "Rocky Linux",
+		"almalinux":           "AlmaLinux",
+		"sles":                "SUSE",
+		"opensuse-leap":       "openSUSE",
+		"opensuse-tumbleweed": "openSUSE",
+		"mageia":              "Mageia",
+	},
+	"apk": {"alpine": "Alpine", "wolfi": "Wolfi", "chainguard": "Chainguard"},
+}
+
+// osvDefaultEcosystems are the ecosystems of the packages of other distributions
+var osvDefaultEcosystems = map[string]string{"apt": "Debian", "apk": "Alpine"}
+
+// osvEcosystem returns the OSV ecosystem of the packages of a package manager
+// on the distribution, or an empty string if unknown.
+func osvEcosystem(manager, distroID string) string {
+	if ecosystem, ok := osvDistroEcosystems[manager][distroID]; ok {
+		return ecosystem
+	}
+	return osvDefaultEcosystems[manager]
+}
+
+type VulnAnalyzer struct {
+}
+
+func (a VulnAnalyzer) Name() string {
+	return "VulnAnalyzer"
+}
+
+// VulnDiff reports the vulnerabilities introduced and resolved between two images.
+func (a VulnAnalyzer) Diff(image1, image2 pkgutil.Image) (util.Result, error) {
+	db, err := loadVulnDatabase()
+	if err != nil {
+		return &util.VulnDiffResult{}, err
+	}
+	vulns1, err := getVulnerabilities(image1, db)
+	if err != nil {
+		return &util.VulnDiffResult{}, err
+	}
+	vulns2, err := getVulnerabilities(image2, db)
+	if err != nil {
+		return &util.VulnDiffResult{}, err
+	}
+	return &util.VulnDiffResult{
+		Image1:   image1.Source,
+		Image2:   image2.Source,
+		DiffType: "Vuln",
+		Diff:     util.GetVulnerabilityDiff(vulns1, vulns2),
+	}, nil
+}
+
+func (a VulnAnalyzer) Analyze(image pkgutil.Image) (util.Result, error) {
+	db, err := loadVulnDatabase()
+	if err != nil {
+		return &util.VulnAnalyzeResult{}, err
+	}
+	vulns, err := getVulnerabilities(image, db)
+	if err != nil {
+		return &util.VulnAnalyzeResult{}, err
+	}
+	return &util.VulnAnalyzeResult{
+		Image:       image.Source,
+		AnalyzeType: "Vuln",
+		Analysis:    vulns,
+	}, nil
+}
+
+func loadVulnDatabase() (*util.VulnDatabase, error) {
+	if VulnDB == "" {
+		return nil, errors.New("no vulnerability database, set --vuln-db to a directory of OSV entries")
+	}
+	return util.LoadVulnDatabase(VulnDB)
+}
+
+// vulnPackage is a package to match against the vulnerability database, under
+// its binary and source package names for OS packages.
+type vulnPackage struct {
+	manager string
+	names   []string
+	path    string
+	version string
+}
+
+// getVulnerabilities matches the apt, rpm, apk, pip and node packages of the
+// image against the vulnerability database, in the ecosystem of their package
+// manager. OS packages are matched in the ecosystem of the image distribution.
+func getVulnerabilities(image pkgutil.Image, db *util.VulnDatabase) ([]util.Vulnerability, error) {
+	vulns := []util.Vulnerability{}
+	if _, err := os.Stat(image.FSPath); err != nil {
+		// invalid image directory path
+		return vulns, err
+	}
+	distro := util.GetDistro(image.FSPath)
+
+	match := func(ecosystem, versionID string, compare util.VersionComparator, packages []vulnPackage) {
+		for _, pkg := range packages {
+			seen := make(map[string]bool)
+			for _, name := range pkg.names {
+				for _, vuln := range db.Match(ecosystem, versionID, name, pkg.version, compare) {
+					if seen[vuln.ID] {
+						continue
+					}
+					seen[vuln.ID] = true
+					vuln.Package = pkg.names[0]
+					vuln.Path = pkg.path
+					vuln.Manager = pkg.manager
+					vulns = append(vulns, vuln)
+				}
+			}
+		}
+	}
+
+	aptPackages, err := AptAnalyzer{}.getPackages(image)
+	if err != nil {
+		return vulns, err
+	}
+	// restore the '+' replaced by parseLine
+	match(osvEcosystem("apt", distro.ID), distro.VersionID, util.CompareDebianVersions,
+		osPackages("apt", aptPackages, func(v string) string { return strings.Replace(v, " ", "+", 1) }))
+
+	if ecosystem := osvEcosystem("rpm", distro.ID); ecosystem != "" && hasRPMBinary(image.FSPath) {
+		rpmPackages, err := RPMAnalyzer{}.getPackages(image)
+		if err != nil {
+			return vulns, err
+		}
+		match(ecosystem, distro.VersionID, util.CompareRPMVersions, osPackages("rpm", rpmPackages, nil))
+	}
+
+	apkPackages, err := readApkPackages(image.FSPath)
+	if err != nil {
+		return vulns, err
+	}
+	match(osvEcosystem("apk", distro.ID), distro.VersionID, util.CompareApkVersions, osPackages("apk", apkPackages, nil))
+
+	pipPackages, err := PipAnalyzer{}.getPackages(image)
+	if err != nil {
+		return vulns, err
+	}
+	match("PyPI", "", util.ComparePEP440Versions, languagePackages("pip", pipPackages))
+
+	nodePackages, err := NodeAnalyzer{}.getPackages(image)
+	if err != nil {
+		return vulns, err
+	}
+	match("npm", "", util.CompareSemverVersions, languagePackages("node", nodePackages))
+	return vulns, nil
+}
+
+// osPackages lists OS packages under their name and the name of their source
+// package, which distributions usually publish vulnerabilities for, with their
+// epoch if recorded apart from the version.
+func osPackages(manager string, packages map[string]util.PackageInfo, version func(string) string) []vulnPackage {
+	var pkgs []vulnPackage
+	for name, info := range packages {
+		pkg := vulnPackage{manager: manager, names: []string{name}, version: info.Version}
+		if info.Epoch != "" {
+			pkg.version = info.Epoch + ":" + pkg.version
+		}
+		if info.Source != "" && info.Source != name {
+			pkg.names = append(pkg.names, info.Source)
+		}
+		if version != nil {
+			pkg.version = version(pkg.version)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs
+}
+
+func languagePackages(manager string, packages map[string]map[string]util.PackageInfo) []vulnPackage {
+	var pkgs []vulnPackage
+	for name, paths := range packages {
+		for path, info := range paths {
+			pkgs = append(pkgs, vulnPackage{manager: manager, names: []string{name}, path: path, version: info.Version})
+		}
+	}
+	return pkgs
+}
+
+// readApkPackages returns the packages of the apk database, with the origin
+// package they were built from as their source.
+func readApkPackages(root string) (map[string]util.PackageInfo, error) {
+	packages := make(map[string]util.PackageInfo)
+	for _, db := range apkInstalledDBs {
+		file, err := os.Open(filepath.Join(root, db))
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+
+		var name string
+		var info util.PackageInfo
+		add := func() {
+			if name != "" {
+				packages[name] = info
+			}
+			name, info = "", util.PackageInfo{}
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if len(line) < 2 || line[1] != ':' {
+				// blank line between packages
+				add()
+				continue
+			}
+			switch value := line[2:]; line[0] {
+			case 'P':
+				name = value
+			case 'V':
+				info.Version = value
+			case 'o':
+				info.Source = value
+			}
+		}
+		add()
+		if err := scanner.Err(); err != nil {
+			return packages, err
+		}
+	}
+	return packages, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
+	"github.com/GoogleContainerTools/container-diff/util"
+	"github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestGetVulnerabilities(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vuln-analyzer")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"osv/Debian/DSA-5532-1.json": `{"id": "DSA-5532-1", "aliases": ["CVE-2023-5363"], "affected": [
+			{"package": {"ecosystem": "Debian:12", "name": "openssl"}, "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "3.0.11-1~deb12u2"}]}]}]}`,
+		"osv/Debian/DSA-5000-1.json": `{"id": "DSA-5000-1", "affected": [
+			{"package": {"ecosystem": "Debian:12", "name": "bash"}, "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "5.2.15-2+b2"}]}]}]}`,
+		"osv/Alpine/ALPINE-CVE-2023-0464.json": `{"id": "ALPINE-CVE-2023-0464", "affected": [
+			{"package": {"ecosystem": "Alpine:v3.17", "name": "openssl"}, "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "3.0.8-r1"}]}]}]}`,
+		"osv/PyPI/GHSA-j8r2-6x86-q33q.json": `{"id": "GHSA-j8r2-6x86-q33q", "database_specific": {"severity": "MODERATE"}, "affected": [
+			{"package": {"ecosystem": "PyPI", "name": "requests"}, "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.3.0"}, {"fixed": "2.31.0"}]}]}]}`,
+		"image/etc/os-release": "ID=debian\nVERSION_ID=\"12\"\n",
+		"image/var/lib/dpkg/status": "Package: libssl3\nSource: openssl\nVersion: 3.0.9-1\n\n" +
+			"Package: bash\nVersion: 5.2.15-2+b2\n",
+		// left behind by a multi-stage build, the image isn't Alpine
+		"image/lib/apk/db/installed": "P:libssl3\nV:3.0.8-r0\no:openssl\n\n",
+		"image/usr/lib/python3/dist-packages/requests-2.28.0.dist-info/METADATA": "Metadata-Version: 2.1\nName: requests\nVersion: 2.28.0\n",
+		"image/usr/lib/python3/dist-packages/requests/__init__.py":               "",
+	})
+
+	db, err := util.LoadVulnDatabase(filepath.Join(dir, "osv"))
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	expected := []util.Vulnerability{
+		{ID: "GHSA-j8r2-6x86-q33q", Package: "requests", Path: "/usr/lib/python3/dist-packages", Version: "2.28.0", Manager: "pip",
+			Ecosystem: "PyPI", Severity: "MEDIUM", Fixed: "2.31.0"},
+		{ID: "DSA-5532-1", Aliases: []string{"CVE-2023-5363"}, Package: "libssl3", Version: "3.0.9-1", Manager: "apt",
+			Ecosystem: "Debian:12", Severity: "UNKNOWN", Fixed: "3.0.11-1~deb12u2"},
+	}
+	image := pkgutil.Image{FSPath: filepath.Join(dir, "image"), Image: &pkgutil.TestImage{Config: &v1.ConfigFile{}}}
+	vulns, err := getVulnerabilities(image, db)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	util.SortVulnerabilities(vulns)
+	if !reflect.DeepEqual(vulns, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, vulns)
+	}
+
+	if _, err := getVulnerabilities(pkgutil.Image{FSPath: "testDirs/notThere"}, db); err == nil {
+		t.Errorf("Expected error for missing path")
+	}
+}
+
+func TestReadApkPackages(t *testing.T) {
+	root, err := ioutil.TempDir("", "apk")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	writeTestFiles(t, root, map[string]string{
+		"lib/apk/db/installed": "C:Q1abc=\nP:libssl3\nV:3.1.4-r5\no:openssl\nF:usr/lib\nR:libssl.so.3\n\nP:musl\nV:1.2.4-r2\n",
+	})
+	expected := map[string]util.PackageInfo{
+		"libssl3": {Version: "3.1.4-r5", Source: "openssl"},
+		"musl":    {Version: "1.2.4-r2"},
+	}
+	packages, err := readApkPackages(root)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+}
@@ -27,6 +27,11 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	whiteoutPrefix = ".wh."
+	opaqueWhiteout = ".wh..wh..opq"
+)
+
 // Directory stores a representation of a file directory.
 type Directory struct {
 	Root    string
@@ -215,3 +220,31 @@ func CleanFilePath(dirtyPath string) string {
 	replacer := strings.NewReplacer(windowsReplacements...)
 	return filepath.Clean(replacer.Replace(dirtyPath))
 }
+
+// GetWhiteouts walks an unpacked layer and returns the paths, relative to the
+// layer root, which the layer deletes from the layers below it, as well as the
+// directories it marks as opaque.
+func GetWhiteouts(layerPath string) (deleted []string, opaque []string, err error) {
+	err = filepath.Walk(layerPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if !strings.HasPrefix(name, whiteoutPrefix) {
+			return nil
+		}
+		dir := "/" + strings.TrimPrefix(filepath.Dir(strings.TrimPrefix(path, layerPath)), "/")
+		if name == opaqueWhiteout {
+			opaque = append(opaque, dir)
+		} else {
+			deleted = append(deleted, filepath.Join(dir, strings.TrimPrefix(name, whiteoutPrefix)))
+		}
+		return nil
+	})
+	return deleted, opaque, err
+}
+
+// IsWhiteout checks if the given file name marks a deletion in a layer
+func IsWhiteout(name string) bool {
+	return strings.HasPrefix(name, whiteoutPrefix)
+}
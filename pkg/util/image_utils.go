@@ -59,6 +59,9 @@ type Image struct {
 	FSPath string
 	Digest v1.Hash
 	Layers []Layer
+	// SBOMPath is set when an SPDX or CycloneDX document was given in place of
+	// the image, whose packages are then read from the document.
+	SBOMPath string
 }
 
 type ImageHistoryItem struct {
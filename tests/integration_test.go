@@ -22,6 +22,7 @@ package tests
 import (
 	"bytes"
 	"context"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -64,6 +65,10 @@ const (
 	multiModifiedLocal = "daemon://gcr.io/gcp-runtimes/container-diff-tests/multi-modified"
 )
 
+// update rewrites the expected output files with the actual output, for changes
+// to the output format; review the regenerated files before committing them.
+var update = flag.Bool("update", false, "update the expected output files")
+
 type ContainerDiffRunner struct {
 	t          *testing.T
 	binaryPath string
@@ -241,6 +246,11 @@ func TestDiffAndAnalysis(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Error running command: %s. Stderr: %s", err, stderr)
 			}
+			if *update {
+				if err := ioutil.WriteFile(test.expectedFile, []byte(strings.TrimSpace(actual)+"\n"), 0644); err != nil {
+					t.Fatalf("Error updating expected output file: %s", err)
+				}
+			}
 			e, err := ioutil.ReadFile(test.expectedFile)
 			if err != nil {
 				t.Fatalf("Error reading expected file output file: %s", err)
@@ -53,6 +53,57 @@ func (r ListAnalyzeResult) OutputText(writer io.Writer, resultType string, forma
 
 }
 
+type MetadataAnalyzeResult AnalyzeResult
+
+func (r MetadataAnalyzeResult) OutputStruct() interface{} {
+	return r
+}
+
+func (r MetadataAnalyzeResult) OutputText(writer io.Writer, resultType string, format string) error {
+	analysis, valid := r.Analysis.(ImageMetadata)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type ImageMetadata")
+		return fmt.Errorf("Could not output %s analysis result", r.AnalyzeType)
+	}
+	r.Analysis = stringifyMetadata(analysis)
+	return TemplateOutputFromFormat(writer, r, "ListAnalyze", format)
+}
+
+type HistoryAnalyzeResult AnalyzeResult
+
+func (r HistoryAnalyzeResult) OutputStruct() interface{} {
+	return r
+}
+
+func (r HistoryAnalyzeResult) OutputText(writer io.Writer, resultType string, format string) error {
+	analysis, valid := r.Analysis.([]HistoryStep)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type []HistoryStep")
+		return fmt.Errorf("Could not output %s analysis result", r.AnalyzeType)
+	}
+	r.Analysis = stringifyHistory(analysis)
+	return TemplateOutputFromFormat(writer, r, "HistoryAnalyze", format)
+}
+
+type ManifestAnalyzeResult AnalyzeResult
+
+func (r ManifestAnalyzeResult) OutputStruct() interface{} {
+	return r
+}
+
+func (r ManifestAnalyzeResult) OutputText(writer io.Writer, resultType string, format string) error {
+	analysis, valid := r.Analysis.(ImageManifest)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type ImageManifest")
+		return fmt.Errorf("Could not output %s analysis result", r.AnalyzeType)
+	}
+	r.Analysis = struct {
+		ImageManifest
+		Layers []StrLayerDescriptor
+	}{analysis, stringifyLayerDescriptors(analysis.Layers)}
+	return TemplateOutputFromFormat(writer, r, "ManifestAnalyze", format)
+}
+
 type MultiVersionPackageAnalyzeResult AnalyzeResult
 
 func (r MultiVersionPackageAnalyzeResult) OutputStruct() interface{} {
@@ -209,17 +260,126 @@ func (r SingleVersionPackageLayerAnalyzeResult) OutputText(writer io.Writer, dif
 	return TemplateOutputFromFormat(writer, strResult, "SingleVersionPackageLayerAnalyze", format)
 }
 
+type MultiVersionPackageLayerAnalyzeResult AnalyzeResult
+
+func (r MultiVersionPackageLayerAnalyzeResult) OutputStruct() interface{} {
+	analysis, valid := r.Analysis.(MultiVersionPackageLayerDiff)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type MultiVersionPackageLayerDiff")
+		return fmt.Errorf("Could not output %s analysis result", r.AnalyzeType)
+	}
+
+	type PkgDiff struct {
+		Packages1 []PackageOutput
+		Packages2 []PackageOutput
+		InfoDiff  []MultiVersionInfo
+	}
+
+	var analysisOutput []PkgDiff
+	for _, d := range analysis.PackageDiffs {
+		diffOutput := PkgDiff{
+			Packages1: getMultiVersionPackageOutput(d.Packages1),
+			Packages2: getMultiVersionPackageOutput(d.Packages2),
+			InfoDiff:  getMultiVersionInfoDiffOutput(d.InfoDiff),
+		}
+		analysisOutput = append(analysisOutput, diffOutput)
+	}
+
+	output := struct {
+		Image       string
+		AnalyzeType string
+		Analysis    []PkgDiff
+	}{
+		Image:       r.Image,
+		AnalyzeType: r.AnalyzeType,
+		Analysis:    analysisOutput,
+	}
+	return output
+}
+
+func (r MultiVersionPackageLayerAnalyzeResult) OutputText(writer io.Writer, diffType string, format string) error {
+	analysis, valid := r.Analysis.(MultiVersionPackageLayerDiff)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type MultiVersionPackageLayerDiff")
+		return fmt.Errorf("Could not output %s analysis result", r.AnalyzeType)
+	}
+
+	type StrDiff struct {
+		Packages1 []StrPackageOutput
+		Packages2 []StrPackageOutput
+		InfoDiff  []StrMultiVersionInfo
+	}
+
+	var analysisOutput []StrDiff
+	for _, d := range analysis.PackageDiffs {
+		diffOutput := StrDiff{
+			Packages1: stringifyPackages(getMultiVersionPackageOutput(d.Packages1)),
+			Packages2: stringifyPackages(getMultiVersionPackageOutput(d.Packages2)),
+			InfoDiff:  stringifyMultiVersionPackageDiff(getMultiVersionInfoDiffOutput(d.InfoDiff)),
+		}
+		analysisOutput = append(analysisOutput, diffOutput)
+	}
+
+	strResult := struct {
+		Image       string
+		AnalyzeType string
+		Analysis    []StrDiff
+	}{
+		Image:       r.Image,
+		AnalyzeType: r.AnalyzeType,
+		Analysis:    analysisOutput,
+	}
+	return TemplateOutputFromFormat(writer, strResult, "MultiVersionPackageLayerAnalyze", format)
+}
+
 type PackageOutput struct {
-	Name    string
-	Path    string `json:",omitempty"`
-	Version string
-	Size    int64
+	Name         string
+	Path         string `json:",omitempty"`
+	Project      string `json:",omitempty"`
+	Environment  string `json:",omitempty"`
+	Version      string
+	Epoch        string   `json:",omitempty"`
+	Arch         string   `json:",omitempty"`
+	Build        string   `json:",omitempty"`
+	Repository   string   `json:",omitempty"`
+	Replacement  string   `json:",omitempty"`
+	Reason       string   `json:",omitempty"`
+	Source       string   `json:",omitempty"`
+	License      string   `json:",omitempty"`
+	Maintainer   string   `json:",omitempty"`
+	Homepage     string   `json:",omitempty"`
+	Dependencies []string `json:",omitempty"`
+	PURL         string   `json:",omitempty"`
+	Size         int64
+}
+
+func newPackageOutput(name, path string, info PackageInfo) PackageOutput {
+	return PackageOutput{
+		Name:         name,
+		Path:         path,
+		Project:      info.Project,
+		Environment:  info.Environment,
+		Version:      info.Version,
+		Epoch:        info.Epoch,
+		Arch:         info.Arch,
+		Build:        info.Build,
+		Repository:   info.Repository,
+		Replacement:  info.Replacement,
+		Reason:       info.Reason,
+		Source:       info.Source,
+		License:      info.License,
+		Maintainer:   info.Maintainer,
+		Homepage:     info.Homepage,
+		Dependencies: info.Dependencies,
+		PURL:         info.PURL,
+		Size:         info.Size,
+	}
 }
 
 func getSingleVersionPackageOutput(packageMap map[string]PackageInfo) []PackageOutput {
 	packages := []PackageOutput{}
 	for name, info := range packageMap {
-		packages = append(packages, PackageOutput{Name: name, Version: info.Version, Size: info.Size})
+		packages = append(packages, newPackageOutput(name, "", info))
 	}
 
 	if SortSize {
@@ -234,7 +394,7 @@ func getMultiVersionPackageOutput(packageMap map[string]map[string]PackageInfo)
 	packages := []PackageOutput{}
 	for name, versionMap := range packageMap {
 		for path, info := range versionMap {
-			packages = append(packages, PackageOutput{Name: name, Path: path, Version: info.Version, Size: info.Size})
+			packages = append(packages, newPackageOutput(name, path, info))
 		}
 	}
 
@@ -246,6 +406,137 @@ func getMultiVersionPackageOutput(packageMap map[string]map[string]PackageInfo)
 	return packages
 }
 
+type LicenseAnalyzeResult AnalyzeResult
+
+func (r LicenseAnalyzeResult) OutputStruct() interface{} {
+	analysis, valid := r.Analysis.([]PackageLicense)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type []PackageLicense")
+		return errors.New("Could not output LicenseAnalyzer analysis result")
+	}
+	SortPackageLicenses(analysis)
+	r.Analysis = analysis
+	return r
+}
+
+func (r LicenseAnalyzeResult) OutputText(writer io.Writer, analyzeType string, format string) error {
+	analysis, valid := r.Analysis.([]PackageLicense)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type []PackageLicense")
+		return errors.New("Could not output LicenseAnalyzer analysis result")
+	}
+	SortPackageLicenses(analysis)
+	r.Analysis = analysis
+	return TemplateOutputFromFormat(writer, r, "LicenseAnalyze", format)
+}
+
+type IntegrityAnalyzeResult AnalyzeResult
+
+func (r IntegrityAnalyzeResult) OutputStruct() interface{} {
+	analysis, valid := r.Analysis.([]FileIntegrity)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type []FileIntegrity")
+		return errors.New("Could not output IntegrityAnalyzer analysis result")
+	}
+	SortFileIntegrity(analysis)
+	r.Analysis = analysis
+	return r
+}
+
+func (r IntegrityAnalyzeResult) OutputText(writer io.Writer, analyzeType string, format string) error {
+	analysis, valid := r.Analysis.([]FileIntegrity)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type []FileIntegrity")
+		return errors.New("Could not output IntegrityAnalyzer analysis result")
+	}
+	SortFileIntegrity(analysis)
+	r.Analysis = analysis
+	return TemplateOutputFromFormat(writer, r, "IntegrityAnalyze", format)
+}
+
+type PURLAnalyzeResult AnalyzeResult
+
+func (r PURLAnalyzeResult) OutputStruct() interface{} {
+	return r
+}
+
+func (r PURLAnalyzeResult) OutputText(writer io.Writer, analyzeType string, format string) error {
+	return TemplateOutputFromFormat(writer, r, "PURLAnalyze", format)
+}
+
+type VulnAnalyzeResult AnalyzeResult
+
+func (r VulnAnalyzeResult) OutputStruct() interface{} {
+	analysis, valid := r.Analysis.([]Vulnerability)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type []Vulnerability")
+		return errors.New("Could not output VulnAnalyzer analysis result")
+	}
+	SortVulnerabilities(analysis)
+	r.Analysis = analysis
+	return r
+}
+
+func (r VulnAnalyzeResult) OutputText(writer io.Writer, analyzeType string, format string) error {
+	analysis, valid := r.Analysis.([]Vulnerability)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type []Vulnerability")
+		return errors.New("Could not output VulnAnalyzer analysis result")
+	}
+	SortVulnerabilities(analysis)
+	r.Analysis = analysis
+	return TemplateOutputFromFormat(writer, r, "VulnAnalyze", format)
+}
+
+type DependencyAnalyzeResult AnalyzeResult
+
+func (r DependencyAnalyzeResult) OutputStruct() interface{} {
+	return r
+}
+
+func (r DependencyAnalyzeResult) OutputText(writer io.Writer, analyzeType string, format string) error {
+	return TemplateOutputFromFormat(writer, r, "DependencyAnalyze", format)
+}
+
+type OwnershipAnalyzeResult AnalyzeResult
+
+func (r OwnershipAnalyzeResult) OutputStruct() interface{} {
+	analysis, valid := r.Analysis.(OwnershipAnalysis)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type OwnershipAnalysis")
+		return errors.New("Could not output OwnershipAnalyzer analysis result")
+	}
+	r.Analysis = sortOwnershipAnalysis(analysis)
+	return r
+}
+
+func (r OwnershipAnalyzeResult) OutputText(writer io.Writer, analyzeType string, format string) error {
+	analysis, valid := r.Analysis.(OwnershipAnalysis)
+	if !valid {
+		logrus.Error("Unexpected structure of Analysis.  Should be of type OwnershipAnalysis")
+		return errors.New("Could not output OwnershipAnalyzer analysis result")
+	}
+	analysis = sortOwnershipAnalysis(analysis)
+	strAnalysis := struct {
+		Packages   []StrPackageFiles
+		Unowned    []StrDirectoryEntry
+		MultiOwned []FileOwners
+	}{
+		Packages:   stringifyPackageFiles(analysis.Packages),
+		Unowned:    stringifyDirectoryEntries(analysis.Unowned),
+		MultiOwned: analysis.MultiOwned,
+	}
+	return TemplateOutputFromFormat(writer, struct {
+		Image       string
+		AnalyzeType string
+		Analysis    interface{}
+	}{
+		Image:       r.Image,
+		AnalyzeType: r.AnalyzeType,
+		Analysis:    strAnalysis,
+	}, "OwnershipAnalyze", format)
+}
+
 type FileAnalyzeResult AnalyzeResult
 
 func (r FileAnalyzeResult) OutputStruct() interface{} {
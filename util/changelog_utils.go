@@ -0,0 +1,96 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ChangelogEntry is one entry of the changelog of a package.
+type ChangelogEntry struct {
+	Version string
+	Author  string `json:",omitempty"`
+	Date    string `json:",omitempty"`
+	Text    string
+	// CVEs are the CVE identifiers referenced by the entry.
+	CVEs []string `json:",omitempty"`
+}
+
+var cveRegex = regexp.MustCompile(`CVE-\d{4}-\d{4,}`)
+
+// debianChangelogHeader matches the first line of a Debian changelog entry,
+// e.g. "openssl (3.0.2-0ubuntu1.12) jammy-security; urgency=medium".
+var debianChangelogHeader = regexp.MustCompile(`^\S+ \(([^)]+)\) [^;]*;`)
+
+// ReferencedCVEs returns the distinct CVE identifiers referenced in text, in
+// order of appearance.
+func ReferencedCVEs(text string) []string {
+	var cves []string
+	seen := make(map[string]bool)
+	for _, cve := range cveRegex.FindAllString(text, -1) {
+		if !seen[cve] {
+			seen[cve] = true
+			cves = append(cves, cve)
+		}
+	}
+	return cves
+}
+
+// NewChangelogEntry returns the changelog entry of the version with the given
+// text, and the CVEs it references.
+func NewChangelogEntry(version, author, date, text string) ChangelogEntry {
+	text = strings.Trim(text, "\n")
+	return ChangelogEntry{Version: version, Author: author, Date: date, Text: text, CVEs: ReferencedCVEs(text)}
+}
+
+// ParseDebianChangelog parses a changelog in the Debian format into its
+// entries, newest first. Entries are read until stop returns true for the
+// version of an entry, which is not included.
+func ParseDebianChangelog(r io.Reader, stop func(version string) bool) ([]ChangelogEntry, error) {
+	var entries []ChangelogEntry
+	var version string
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := debianChangelogHeader.FindStringSubmatch(line); match != nil {
+			version, lines = match[1], nil
+			if stop(version) {
+				break
+			}
+			continue
+		}
+		if version == "" {
+			continue
+		}
+		if strings.HasPrefix(line, " -- ") {
+			// the trailer separates the maintainer from the date by two spaces
+			author, date := strings.TrimPrefix(line, " -- "), ""
+			if i := strings.Index(author, ">  "); i >= 0 {
+				author, date = author[:i+1], strings.TrimSpace(author[i+3:])
+			}
+			entries = append(entries, NewChangelogEntry(version, author, date, strings.Join(lines, "\n")))
+			version, lines = "", nil
+			continue
+		}
+		lines = append(lines, strings.TrimPrefix(line, "  "))
+	}
+	return entries, scanner.Err()
+}
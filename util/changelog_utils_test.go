@@ -0,0 +1,94 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDebianChangelog = `openssl (3.0.2-0ubuntu1.12) jammy-security; urgency=medium
+
+  * SECURITY UPDATE: Excessive time spent checking DH keys
+    - debian/patches/CVE-2023-3446.patch: check the key modulus length.
+    - debian/patches/CVE-2023-3817.patch: check q value.
+    - CVE-2023-3446
+
+ -- Marc Deslauriers <marc.deslauriers@ubuntu.com>  Tue, 01 Aug 2023 12:21:09 -0400
+
+openssl (3.0.2-0ubuntu1.11) jammy-security; urgency=medium
+
+  * SECURITY UPDATE: AES-SIV ignores empty associated data
+    - CVE-2023-2975
+
+ -- Marc Deslauriers <marc.deslauriers@ubuntu.com>  Thu, 20 Jul 2023 07:47:47 -0400
+
+openssl (3.0.2-0ubuntu1.10) jammy-security; urgency=medium
+
+  * SECURITY UPDATE: Possible DoS translating ASN.1 object identifiers
+    - CVE-2023-2650
+
+ -- Marc Deslauriers <marc.deslauriers@ubuntu.com>  Tue, 30 May 2023 13:19:03 -0400
+`
+
+func TestParseDebianChangelog(t *testing.T) {
+	entries, err := ParseDebianChangelog(strings.NewReader(testDebianChangelog), func(version string) bool {
+		return CompareDebianVersions(version, "3.0.2-0ubuntu1.10") <= 0
+	})
+	if err != nil {
+		t.Fatalf("Error parsing changelog: %s", err)
+	}
+	expected := []ChangelogEntry{
+		{
+			Version: "3.0.2-0ubuntu1.12",
+			Author:  "Marc Deslauriers <marc.deslauriers@ubuntu.com>",
+			Date:    "Tue, 01 Aug 2023 12:21:09 -0400",
+			Text: "* SECURITY UPDATE: Excessive time spent checking DH keys\n" +
+				"  - debian/patches/CVE-2023-3446.patch: check the key modulus length.\n" +
+				"  - debian/patches/CVE-2023-3817.patch: check q value.\n" +
+				"  - CVE-2023-3446",
+			CVEs: []string{"CVE-2023-3446", "CVE-2023-3817"},
+		},
+		{
+			Version: "3.0.2-0ubuntu1.11",
+			Author:  "Marc Deslauriers <marc.deslauriers@ubuntu.com>",
+			Date:    "Thu, 20 Jul 2023 07:47:47 -0400",
+			Text:    "* SECURITY UPDATE: AES-SIV ignores empty associated data\n  - CVE-2023-2975",
+			CVEs:    []string{"CVE-2023-2975"},
+		},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, entries)
+	}
+}
+
+func TestReferencedCVEs(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected []string
+	}{
+		{"- Resolves: CVE-2023-5678 CVE-2024-0727, CVE-2023-5678", []string{"CVE-2023-5678", "CVE-2024-0727"}},
+		{"- Fix CVE-2021-44228 and CVE-2021-45046", []string{"CVE-2021-44228", "CVE-2021-45046"}},
+		{"- Rebuild for new toolchain", nil},
+	}
+	for _, test := range testCases {
+		if cves := ReferencedCVEs(test.text); !reflect.DeepEqual(cves, test.expected) {
+			t.Errorf("ReferencedCVEs(%q): expected %v but got %v", test.text, test.expected, cves)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+// DependencyPackage stores a package of the dependency graph of an image, with
+// the installed packages it depends on and those depending on it.
+type DependencyPackage struct {
+	Package string
+	// Manager is the package manager the package was installed with, e.g. apt or rpm.
+	Manager string
+	// TopLevel is set for packages installed explicitly or, when the package
+	// manager does not record why packages were installed, for packages no
+	// other package depends on.
+	TopLevel bool
+	// Orphan is set for packages installed as a dependency which no top-level
+	// package depends on anymore, directly or not.
+	Orphan       bool     `json:",omitempty"`
+	Dependencies []string `json:",omitempty"`
+	Dependents   []string `json:",omitempty"`
+}
+
+// DependencyChain stores a package along with the chain of dependencies that
+// pulled it in, from a top-level package down to the package itself. Orphans
+// have no chain.
+type DependencyChain struct {
+	Package string
+	Manager string
+	Chain   []string
+}
+
+// DependencyDiff stores the packages found only in Image1 and only in Image2,
+// with the chain that pulled them in their image.
+type DependencyDiff struct {
+	Packages1 []DependencyChain
+	Packages2 []DependencyChain
+}
@@ -89,6 +89,7 @@ func (r MultiVersionPackageDiffResult) OutputText(writer io.Writer, diffType str
 }
 
 func getMultiVersionInfoDiffOutput(infoDiff []MultiVersionInfo) []MultiVersionInfo {
+	infoDiff = filterMultiVersionInfoDiff(infoDiff)
 	if SortSize {
 		multiInfoBy(multiInfoSizeSort).Sort(infoDiff)
 	} else {
@@ -130,10 +131,18 @@ func (r SingleVersionPackageDiffResult) OutputText(writer io.Writer, diffType st
 	strPackages2 := stringifyPackages(getSingleVersionPackageOutput(diff.Packages2))
 	strInfoDiff := stringifyPackageDiff(getSingleVersionInfoDiffOutput(diff.InfoDiff))
 
+	strChangelogs := []StrInfo{}
+	for _, info := range strInfoDiff {
+		if len(info.Changelog) > 0 {
+			strChangelogs = append(strChangelogs, info)
+		}
+	}
+
 	type StrDiff struct {
-		Packages1 []StrPackageOutput
-		Packages2 []StrPackageOutput
-		InfoDiff  []StrInfo
+		Packages1  []StrPackageOutput
+		Packages2  []StrPackageOutput
+		InfoDiff   []StrInfo
+		Changelogs []StrInfo
 	}
 
 	strResult := struct {
@@ -146,15 +155,17 @@ func (r SingleVersionPackageDiffResult) OutputText(writer io.Writer, diffType st
 		Image2:   r.Image2,
 		DiffType: r.DiffType,
 		Diff: StrDiff{
-			Packages1: strPackages1,
-			Packages2: strPackages2,
-			InfoDiff:  strInfoDiff,
+			Packages1:  strPackages1,
+			Packages2:  strPackages2,
+			InfoDiff:   strInfoDiff,
+			Changelogs: strChangelogs,
 		},
 	}
 	return TemplateOutputFromFormat(writer, strResult, "SingleVersionPackageDiff", format)
 }
 
 func getSingleVersionInfoDiffOutput(infoDiff []Info) []Info {
+	infoDiff = filterInfoDiff(infoDiff)
 	if SortSize {
 		singleInfoBy(singleInfoSizeSort).Sort(infoDiff)
 	} else {
@@ -166,9 +177,9 @@ func getSingleVersionInfoDiffOutput(infoDiff []Info) []Info {
 type SingleVersionPackageLayerDiffResult DiffResult
 
 func (r SingleVersionPackageLayerDiffResult) OutputStruct() interface{} {
-	diff, valid := r.Diff.(PackageLayerDiff)
+	diff, valid := r.Diff.(PackageLayerChangesDiff)
 	if !valid {
-		logrus.Error("Unexpected structure of Diff.  Should follow the PackageLayerDiff struct")
+		logrus.Error("Unexpected structure of Diff.  Should follow the PackageLayerChangesDiff struct")
 		return fmt.Errorf("Could not output %s diff result", r.DiffType)
 	}
 
@@ -178,14 +189,33 @@ func (r SingleVersionPackageLayerDiffResult) OutputStruct() interface{} {
 		InfoDiff  []Info
 	}
 
-	var diffOutputs []PkgDiff
-	for _, d := range diff.PackageDiffs {
-		diffOutput := PkgDiff{
+	type LayerDiff struct {
+		Layer     int
+		Digest1   string `json:",omitempty"`
+		Digest2   string `json:",omitempty"`
+		Installed PkgDiff
+		Removed   PkgDiff
+		Updated   PkgDiff
+	}
+
+	getPkgDiff := func(d PackageDiff) PkgDiff {
+		return PkgDiff{
 			Packages1: getSingleVersionPackageOutput(d.Packages1),
 			Packages2: getSingleVersionPackageOutput(d.Packages2),
 			InfoDiff:  getSingleVersionInfoDiffOutput(d.InfoDiff),
 		}
-		diffOutputs = append(diffOutputs, diffOutput)
+	}
+
+	diffOutputs := []LayerDiff{}
+	for _, d := range diff.LayerDiffs {
+		diffOutputs = append(diffOutputs, LayerDiff{
+			Layer:     d.Layer,
+			Digest1:   d.Digest1,
+			Digest2:   d.Digest2,
+			Installed: getPkgDiff(d.Installed),
+			Removed:   getPkgDiff(d.Removed),
+			Updated:   getPkgDiff(d.Updated),
+		})
 	}
 
 	r.Diff = diffOutputs
@@ -193,9 +223,9 @@ func (r SingleVersionPackageLayerDiffResult) OutputStruct() interface{} {
 }
 
 func (r SingleVersionPackageLayerDiffResult) OutputText(writer io.Writer, diffType string, format string) error {
-	diff, valid := r.Diff.(PackageLayerDiff)
+	diff, valid := r.Diff.(PackageLayerChangesDiff)
 	if !valid {
-		logrus.Error("Unexpected structure of Diff.  Should follow the PackageLayerDiff struct")
+		logrus.Error("Unexpected structure of Diff.  Should follow the PackageLayerChangesDiff struct")
 		return fmt.Errorf("Could not output %s diff result", r.DiffType)
 	}
 
@@ -205,21 +235,40 @@ func (r SingleVersionPackageLayerDiffResult) OutputText(writer io.Writer, diffTy
 		InfoDiff  []StrInfo
 	}
 
-	var diffOutputs []StrDiff
-	for _, d := range diff.PackageDiffs {
-		diffOutput := StrDiff{
+	type StrLayerDiff struct {
+		Layer     int
+		Digest1   string
+		Digest2   string
+		Installed StrDiff
+		Removed   StrDiff
+		Updated   StrDiff
+	}
+
+	getStrDiff := func(d PackageDiff) StrDiff {
+		return StrDiff{
 			Packages1: stringifyPackages(getSingleVersionPackageOutput(d.Packages1)),
 			Packages2: stringifyPackages(getSingleVersionPackageOutput(d.Packages2)),
 			InfoDiff:  stringifyPackageDiff(getSingleVersionInfoDiffOutput(d.InfoDiff)),
 		}
-		diffOutputs = append(diffOutputs, diffOutput)
+	}
+
+	var diffOutputs []StrLayerDiff
+	for _, d := range diff.LayerDiffs {
+		diffOutputs = append(diffOutputs, StrLayerDiff{
+			Layer:     d.Layer,
+			Digest1:   d.Digest1,
+			Digest2:   d.Digest2,
+			Installed: getStrDiff(d.Installed),
+			Removed:   getStrDiff(d.Removed),
+			Updated:   getStrDiff(d.Updated),
+		})
 	}
 
 	strResult := struct {
 		Image1   string
 		Image2   string
 		DiffType string
-		Diff     []StrDiff
+		Diff     []StrLayerDiff
 	}{
 		Image1:   r.Image1,
 		Image2:   r.Image2,
@@ -229,6 +278,110 @@ func (r SingleVersionPackageLayerDiffResult) OutputText(writer io.Writer, diffTy
 	return TemplateOutputFromFormat(writer, strResult, "SingleVersionPackageLayerDiff", format)
 }
 
+type MultiVersionPackageLayerDiffResult DiffResult
+
+func (r MultiVersionPackageLayerDiffResult) OutputStruct() interface{} {
+	diff, valid := r.Diff.(MultiVersionPackageLayerChangesDiff)
+	if !valid {
+		logrus.Error("Unexpected structure of Diff.  Should follow the MultiVersionPackageLayerChangesDiff struct")
+		return fmt.Errorf("Could not output %s diff result", r.DiffType)
+	}
+
+	type PkgDiff struct {
+		Packages1 []PackageOutput
+		Packages2 []PackageOutput
+		InfoDiff  []MultiVersionInfo
+	}
+
+	type LayerDiff struct {
+		Layer     int
+		Digest1   string `json:",omitempty"`
+		Digest2   string `json:",omitempty"`
+		Installed PkgDiff
+		Removed   PkgDiff
+		Updated   PkgDiff
+	}
+
+	getPkgDiff := func(d MultiVersionPackageDiff) PkgDiff {
+		return PkgDiff{
+			Packages1: getMultiVersionPackageOutput(d.Packages1),
+			Packages2: getMultiVersionPackageOutput(d.Packages2),
+			InfoDiff:  getMultiVersionInfoDiffOutput(d.InfoDiff),
+		}
+	}
+
+	diffOutputs := []LayerDiff{}
+	for _, d := range diff.LayerDiffs {
+		diffOutputs = append(diffOutputs, LayerDiff{
+			Layer:     d.Layer,
+			Digest1:   d.Digest1,
+			Digest2:   d.Digest2,
+			Installed: getPkgDiff(d.Installed),
+			Removed:   getPkgDiff(d.Removed),
+			Updated:   getPkgDiff(d.Updated),
+		})
+	}
+
+	r.Diff = diffOutputs
+	return r
+}
+
+func (r MultiVersionPackageLayerDiffResult) OutputText(writer io.Writer, diffType string, format string) error {
+	diff, valid := r.Diff.(MultiVersionPackageLayerChangesDiff)
+	if !valid {
+		logrus.Error("Unexpected structure of Diff.  Should follow the MultiVersionPackageLayerChangesDiff struct")
+		return fmt.Errorf("Could not output %s diff result", r.DiffType)
+	}
+
+	type StrDiff struct {
+		Packages1 []StrPackageOutput
+		Packages2 []StrPackageOutput
+		InfoDiff  []StrMultiVersionInfo
+	}
+
+	type StrLayerDiff struct {
+		Layer     int
+		Digest1   string
+		Digest2   string
+		Installed StrDiff
+		Removed   StrDiff
+		Updated   StrDiff
+	}
+
+	getStrDiff := func(d MultiVersionPackageDiff) StrDiff {
+		return StrDiff{
+			Packages1: stringifyPackages(getMultiVersionPackageOutput(d.Packages1)),
+			Packages2: stringifyPackages(getMultiVersionPackageOutput(d.Packages2)),
+			InfoDiff:  stringifyMultiVersionPackageDiff(getMultiVersionInfoDiffOutput(d.InfoDiff)),
+		}
+	}
+
+	var diffOutputs []StrLayerDiff
+	for _, d := range diff.LayerDiffs {
+		diffOutputs = append(diffOutputs, StrLayerDiff{
+			Layer:     d.Layer,
+			Digest1:   d.Digest1,
+			Digest2:   d.Digest2,
+			Installed: getStrDiff(d.Installed),
+			Removed:   getStrDiff(d.Removed),
+			Updated:   getStrDiff(d.Updated),
+		})
+	}
+
+	strResult := struct {
+		Image1   string
+		Image2   string
+		DiffType string
+		Diff     []StrLayerDiff
+	}{
+		Image1:   r.Image1,
+		Image2:   r.Image2,
+		DiffType: r.DiffType,
+		Diff:     diffOutputs,
+	}
+	return TemplateOutputFromFormat(writer, strResult, "MultiVersionPackageLayerDiff", format)
+}
+
 type HistDiffResult DiffResult
 
 func (r HistDiffResult) OutputStruct() interface{} {
@@ -236,9 +389,65 @@ func (r HistDiffResult) OutputStruct() interface{} {
 }
 
 func (r HistDiffResult) OutputText(writer io.Writer, diffType string, format string) error {
+	diff, valid := r.Diff.(HistoryDiff)
+	if !valid {
+		logrus.Error("Unexpected structure of Diff.  Should follow the HistoryDiff struct")
+		return fmt.Errorf("Could not output %s diff result", r.DiffType)
+	}
+	r.Diff = stringifyHistoryDiff(diff)
 	return TemplateOutputFromFormat(writer, r, "HistDiff", format)
 }
 
+type LicenseDiffResult DiffResult
+
+func (r LicenseDiffResult) OutputStruct() interface{} {
+	return r
+}
+
+func (r LicenseDiffResult) OutputText(writer io.Writer, diffType string, format string) error {
+	return TemplateOutputFromFormat(writer, r, "LicenseDiff", format)
+}
+
+type IntegrityDiffResult DiffResult
+
+func (r IntegrityDiffResult) OutputStruct() interface{} {
+	return r
+}
+
+func (r IntegrityDiffResult) OutputText(writer io.Writer, diffType string, format string) error {
+	return TemplateOutputFromFormat(writer, r, "IntegrityDiff", format)
+}
+
+type VulnDiffResult DiffResult
+
+func (r VulnDiffResult) OutputStruct() interface{} {
+	return r
+}
+
+func (r VulnDiffResult) OutputText(writer io.Writer, diffType string, format string) error {
+	return TemplateOutputFromFormat(writer, r, "VulnDiff", format)
+}
+
+type DependencyDiffResult DiffResult
+
+func (r DependencyDiffResult) OutputStruct() interface{} {
+	return r
+}
+
+func (r DependencyDiffResult) OutputText(writer io.Writer, diffType string, format string) error {
+	return TemplateOutputFromFormat(writer, r, "DependencyDiff", format)
+}
+
+type OwnershipDiffResult DiffResult
+
+func (r OwnershipDiffResult) OutputStruct() interface{} {
+	return r
+}
+
+func (r OwnershipDiffResult) OutputText(writer io.Writer, diffType string, format string) error {
+	return TemplateOutputFromFormat(writer, r, "OwnershipDiff", format)
+}
+
 type MetadataDiffResult DiffResult
 
 func (r MetadataDiffResult) OutputStruct() interface{} {
@@ -246,9 +455,31 @@ func (r MetadataDiffResult) OutputStruct() interface{} {
 }
 
 func (r MetadataDiffResult) OutputText(writer io.Writer, diffType string, format string) error {
+	diff, valid := r.Diff.(MetadataDiff)
+	if !valid {
+		logrus.Error("Unexpected structure of Diff.  Should follow the MetadataDiff struct")
+		return fmt.Errorf("Could not output %s diff result", r.DiffType)
+	}
+	r.Diff = stringifyMetadataDiff(diff)
 	return TemplateOutputFromFormat(writer, r, "MetadataDiff", format)
 }
 
+type ManifestDiffResult DiffResult
+
+func (r ManifestDiffResult) OutputStruct() interface{} {
+	return r
+}
+
+func (r ManifestDiffResult) OutputText(writer io.Writer, diffType string, format string) error {
+	diff, valid := r.Diff.(ManifestDiff)
+	if !valid {
+		logrus.Error("Unexpected structure of Diff.  Should follow the ManifestDiff struct")
+		return fmt.Errorf("Could not output %s diff result", r.DiffType)
+	}
+	r.Diff = stringifyManifestDiff(diff)
+	return TemplateOutputFromFormat(writer, r, "ManifestDiff", format)
+}
+
 type DirDiffResult DiffResult
 
 func (r DirDiffResult) OutputStruct() interface{} {
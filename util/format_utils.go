@@ -31,8 +31,11 @@ import (
 var templates = map[string]string{
 	"SingleVersionPackageDiff":         SingleVersionDiffOutput,
 	"MultiVersionPackageDiff":          MultiVersionDiffOutput,
+	"HistoryAnalyze":                   HistoryAnalysisOutput,
 	"HistDiff":                         HistoryDiffOutput,
 	"MetadataDiff":                     MetadataDiffOutput,
+	"ManifestAnalyze":                  ManifestAnalysisOutput,
+	"ManifestDiff":                     ManifestDiffOutput,
 	"DirDiff":                          FSDiffOutput,
 	"MultipleDirDiff":                  FSLayerDiffOutput,
 	"FilenameDiff":                     FilenameDiffOutput,
@@ -46,6 +49,20 @@ var templates = map[string]string{
 	"MultiVersionPackageAnalyze":       MultiVersionPackageOutput,
 	"SingleVersionPackageAnalyze":      SingleVersionPackageOutput,
 	"SingleVersionPackageLayerAnalyze": SingleVersionPackageLayerOutput,
+	"SingleVersionPackageLayerDiff":    SingleVersionPackageLayerDiffOutput,
+	"MultiVersionPackageLayerAnalyze":  MultiVersionPackageLayerOutput,
+	"MultiVersionPackageLayerDiff":     MultiVersionPackageLayerDiffOutput,
+	"LicenseAnalyze":                   LicenseAnalysisOutput,
+	"LicenseDiff":                      LicenseDiffOutput,
+	"IntegrityAnalyze":                 IntegrityAnalysisOutput,
+	"IntegrityDiff":                    IntegrityDiffOutput,
+	"VulnAnalyze":                      VulnAnalysisOutput,
+	"PURLAnalyze":                      PURLAnalysisOutput,
+	"VulnDiff":                         VulnDiffOutput,
+	"DependencyAnalyze":                DependencyAnalysisOutput,
+	"DependencyDiff":                   DependencyDiffOutput,
+	"OwnershipAnalyze":                 OwnershipAnalysisOutput,
+	"OwnershipDiff":                    OwnershipDiffOutput,
 }
 
 func JSONify(writer io.Writer, diff interface{}) error {
@@ -0,0 +1,98 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// HistoryStep stores a step of the history of an image, with the layer it
+// created if any.
+type HistoryStep struct {
+	CreatedBy  string
+	Created    string `json:",omitempty"`
+	Author     string `json:",omitempty"`
+	Comment    string `json:",omitempty"`
+	EmptyLayer bool
+	// Layer is the digest of the layer created by the step and Size its
+	// compressed size, both left empty for steps not creating a layer.
+	Layer string `json:",omitempty"`
+	Size  int64  `json:",omitempty"`
+}
+
+// HistoryStepChange stores a step of the history of an image aligned with the
+// matching step of the other. Index1 and Index2 are the indexes of the steps
+// in their history, -1 for a step missing from an image.
+type HistoryStepChange struct {
+	Index1 int
+	Index2 int
+	Step1  *HistoryStep `json:",omitempty"`
+	Step2  *HistoryStep `json:",omitempty"`
+}
+
+// HistoryDiff stores the steps differing between the histories of two images.
+type HistoryDiff struct {
+	Steps []HistoryStepChange
+}
+
+// GetHistoryDiff aligns the histories of two images on the commands of their
+// steps. Steps with an unchanged command are reported when the layer they
+// created changed, and the other steps are paired by position within each
+// changed run of steps. Timestamps are not compared, as they change on every
+// build.
+func GetHistoryDiff(history1, history2 []HistoryStep) HistoryDiff {
+	diff := HistoryDiff{Steps: []HistoryStepChange{}}
+	matcher := difflib.NewMatcher(historyCommands(history1), historyCommands(history2))
+	for _, opCode := range matcher.GetOpCodes() {
+		if opCode.Tag == 'e' {
+			for i, j := opCode.I1, opCode.J1; i < opCode.I2; i, j = i+1, j+1 {
+				if history1[i].EmptyLayer != history2[j].EmptyLayer || history1[i].Layer != history2[j].Layer {
+					diff.Steps = append(diff.Steps, HistoryStepChange{i, j, &history1[i], &history2[j]})
+				}
+			}
+			continue
+		}
+		for k := 0; k < opCode.I2-opCode.I1 || k < opCode.J2-opCode.J1; k++ {
+			change := HistoryStepChange{Index1: -1, Index2: -1}
+			if i := opCode.I1 + k; i < opCode.I2 {
+				change.Index1, change.Step1 = i, &history1[i]
+			}
+			if j := opCode.J1 + k; j < opCode.J2 {
+				change.Index2, change.Step2 = j, &history2[j]
+			}
+			diff.Steps = append(diff.Steps, change)
+		}
+	}
+	return diff
+}
+
+func historyCommands(history []HistoryStep) []string {
+	commands := make([]string, len(history))
+	for i, step := range history {
+		commands[i] = step.CreatedBy
+	}
+	return commands
+}
+
+func (s HistoryStep) String() string {
+	if s.EmptyLayer || s.Layer == "" {
+		return fmt.Sprintf("%s (no layer)", s.CreatedBy)
+	}
+	return fmt.Sprintf("%s (%s, %s)", s.CreatedBy, s.Layer, stringifySize(s.Size))
+}
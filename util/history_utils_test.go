@@ -0,0 +1,87 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetHistoryDiff(t *testing.T) {
+	from := HistoryStep{CreatedBy: "/bin/sh -c #(nop) ADD file:abc in /", Layer: "sha256:aa", Size: 1024}
+	env := HistoryStep{CreatedBy: "/bin/sh -c #(nop)  ENV LANG=C.UTF-8", EmptyLayer: true}
+	update1 := HistoryStep{CreatedBy: "/bin/sh -c apt-get update", Created: "2024-01-15T09:30:00Z", Layer: "sha256:bb", Size: 2048}
+	update2 := HistoryStep{CreatedBy: "/bin/sh -c apt-get update", Created: "2024-02-15T09:30:00Z", Layer: "sha256:cc", Size: 3072}
+	install1 := HistoryStep{CreatedBy: "/bin/sh -c apt-get install -y curl", Layer: "sha256:dd", Size: 4096}
+	install2 := HistoryStep{CreatedBy: "/bin/sh -c apt-get install -y curl wget", Layer: "sha256:ee", Size: 5120}
+	user := HistoryStep{CreatedBy: "/bin/sh -c #(nop)  USER nobody", EmptyLayer: true}
+	rebuilt := from
+	rebuilt.Created = "2024-02-15T09:30:00Z"
+
+	testCases := []struct {
+		descrip  string
+		history1 []HistoryStep
+		history2 []HistoryStep
+		expected []HistoryStepChange
+	}{
+		{
+			descrip:  "Identical commands and layers",
+			history1: []HistoryStep{from, env},
+			history2: []HistoryStep{rebuilt, env},
+			expected: []HistoryStepChange{},
+		},
+		{
+			descrip:  "Unchanged command with a changed layer",
+			history1: []HistoryStep{from, env, update1},
+			history2: []HistoryStep{from, env, update2},
+			expected: []HistoryStepChange{{2, 2, &update1, &update2}},
+		},
+		{
+			descrip:  "Changed, inserted and removed steps",
+			history1: []HistoryStep{from, update1, install1, user},
+			history2: []HistoryStep{from, env, update1, install2},
+			expected: []HistoryStepChange{
+				{-1, 1, nil, &env},
+				{2, 3, &install1, &install2},
+				{3, -1, &user, nil},
+			},
+		},
+	}
+	for _, test := range testCases {
+		diff := GetHistoryDiff(test.history1, test.history2)
+		if !reflect.DeepEqual(diff.Steps, test.expected) {
+			t.Errorf("%s: Expected: %v but got: %v", test.descrip, test.expected, diff.Steps)
+		}
+	}
+}
+
+func TestStringifyHistoryDiff(t *testing.T) {
+	update1 := HistoryStep{CreatedBy: "/bin/sh -c apt-get update", Layer: "sha256:bb", Size: 2048}
+	update2 := HistoryStep{CreatedBy: "/bin/sh -c apt-get update", Layer: "sha256:cc", Size: 3072}
+	env := HistoryStep{CreatedBy: "/bin/sh -c #(nop)  ENV LANG=C.UTF-8", EmptyLayer: true}
+	diff := HistoryDiff{Steps: []HistoryStepChange{
+		{2, 2, &update1, &update2},
+		{-1, 3, nil, &env},
+	}}
+	expected := []StrHistoryStepChange{
+		{"2", "/bin/sh -c apt-get update (sha256:bb, 2K)", "/bin/sh -c apt-get update (sha256:cc, 3K)"},
+		{"none -> 3", "none", "/bin/sh -c #(nop)  ENV LANG=C.UTF-8 (no layer)"},
+	}
+	if changes := stringifyHistoryDiff(diff); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, changes)
+	}
+}
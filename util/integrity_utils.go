@@ -0,0 +1,67 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sort"
+)
+
+// IntegrityStatus classifies a package file which doesn't match its package manager checksum.
+type IntegrityStatus string
+
+const (
+	// FileModified files have a different content than the one installed.
+	FileModified IntegrityStatus = "modified"
+	// FileMissing files were removed from the image.
+	FileMissing IntegrityStatus = "missing"
+	// FileReplaced files are no longer regular files, e.g. were replaced by a symbolic link.
+	FileReplaced IntegrityStatus = "replaced"
+	// ConfigModified configuration files were modified, as users are expected to.
+	ConfigModified IntegrityStatus = "config"
+)
+
+// FileIntegrity stores the integrity status of a file installed by a package.
+type FileIntegrity struct {
+	Path    string
+	Package string
+	// Manager is the package manager the package was installed with, e.g. apt or pip.
+	Manager string
+	Status  IntegrityStatus
+}
+
+// IntegrityDiff stores the integrity differences between two images: Regressions
+// lists the package files which fail verification in Image2 but didn't in Image1,
+// or fail differently, and Resolved those which failed verification in Image1 and
+// pass it in Image2. Configuration changes are not reported.
+type IntegrityDiff struct {
+	Regressions []FileIntegrity
+	Resolved    []FileIntegrity
+}
+
+// SortFileIntegrity sorts files by path, package manager and package name.
+func SortFileIntegrity(files []FileIntegrity) {
+	sort.Slice(files, func(i, j int) bool {
+		f1, f2 := files[i], files[j]
+		if f1.Path != f2.Path {
+			return f1.Path < f2.Path
+		}
+		if f1.Manager != f2.Manager {
+			return f1.Manager < f2.Manager
+		}
+		return f1.Package < f2.Package
+	})
+}
@@ -0,0 +1,428 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PackageLicense stores the license of one installation of a package.
+type PackageLicense struct {
+	Package string
+	// Manager is the package manager the package was installed with, e.g. apt or pip.
+	Manager string
+	// Path is the installation path, for package managers installing a package
+	// in several places.
+	Path    string `json:",omitempty"`
+	Version string
+	// License is an SPDX license expression where the declared license could be
+	// normalized, the declared license otherwise, and empty if none was found.
+	License string
+}
+
+// LicenseChange stores the licenses of a package installed in two images.
+type LicenseChange struct {
+	Package  string
+	Manager  string
+	Path     string `json:",omitempty"`
+	Version1 string
+	Version2 string
+	License1 string
+	License2 string
+}
+
+// LicenseDiff stores the license differences between two images: Added lists the
+// packages which only have a license in Image2, e.g. newly installed packages,
+// Removed those which only have a license in Image1 and Changed those whose
+// license differs.
+type LicenseDiff struct {
+	Added   []PackageLicense
+	Removed []PackageLicense
+	Changed []LicenseChange
+}
+
+type licenseKey struct{ manager, pkg, path string }
+
+// GetLicenseDiff determines the license differences between the packages of two images.
+func GetLicenseDiff(licenses1, licenses2 []PackageLicense) LicenseDiff {
+	diff := LicenseDiff{Added: []PackageLicense{}, Removed: []PackageLicense{}, Changed: []LicenseChange{}}
+	byKey1 := make(map[licenseKey]PackageLicense)
+	for _, l := range licenses1 {
+		byKey1[licenseKey{l.Manager, l.Package, l.Path}] = l
+	}
+	byKey2 := make(map[licenseKey]PackageLicense)
+	for _, l := range licenses2 {
+		byKey2[licenseKey{l.Manager, l.Package, l.Path}] = l
+	}
+
+	for key, l1 := range byKey1 {
+		l2 := byKey2[key]
+		switch {
+		case l1.License == "":
+			continue
+		case l2.License == "":
+			diff.Removed = append(diff.Removed, l1)
+		case l1.License != l2.License:
+			diff.Changed = append(diff.Changed, LicenseChange{
+				Package:  l1.Package,
+				Manager:  l1.Manager,
+				Path:     l1.Path,
+				Version1: l1.Version,
+				Version2: l2.Version,
+				License1: l1.License,
+				License2: l2.License,
+			})
+		}
+	}
+	for key, l2 := range byKey2 {
+		if l2.License != "" && byKey1[key].License == "" {
+			diff.Added = append(diff.Added, l2)
+		}
+	}
+
+	SortPackageLicenses(diff.Added)
+	SortPackageLicenses(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		c1, c2 := diff.Changed[i], diff.Changed[j]
+		return licenseLess(licenseKey{c1.Manager, c1.Package, c1.Path}, licenseKey{c2.Manager, c2.Package, c2.Path})
+	})
+	return diff
+}
+
+// SortPackageLicenses sorts licenses by package manager, package name and path.
+func SortPackageLicenses(licenses []PackageLicense) {
+	sort.Slice(licenses, func(i, j int) bool {
+		l1, l2 := licenses[i], licenses[j]
+		return licenseLess(licenseKey{l1.Manager, l1.Package, l1.Path}, licenseKey{l2.Manager, l2.Package, l2.Path})
+	})
+}
+
+func licenseLess(k1, k2 licenseKey) bool {
+	if k1.manager != k2.manager {
+		return k1.manager < k2.manager
+	}
+	if k1.pkg != k2.pkg {
+		return k1.pkg < k2.pkg
+	}
+	return k1.path < k2.path
+}
+
+// spdxLicenses maps license names, reduced by licenseKeyOf, to their SPDX identifier.
+var spdxLicenses = map[string]string{
+	"mit":                      "MIT",
+	"expat":                    "MIT",
+	"x11":                      "X11",
+	"isc":                      "ISC",
+	"apache2":                  "Apache-2.0",
+	"apache20":                 "Apache-2.0",
+	"asl2":                     "Apache-2.0",
+	"asl20":                    "Apache-2.0",
+	"apachesoftware":           "Apache-2.0",
+	"apachesoftware20":         "Apache-2.0",
+	"apache11":                 "Apache-1.1",
+	"bsd2clause":               "BSD-2-Clause",
+	"2clausebsd":               "BSD-2-Clause",
+	"simplifiedbsd":            "BSD-2-Clause",
+	"freebsd":                  "BSD-2-Clause",
+	"bsd3clause":               "BSD-3-Clause",
+	"3clausebsd":               "BSD-3-Clause",
+	"newbsd":                   "BSD-3-Clause",
+	"modifiedbsd":              "BSD-3-Clause",
+	"revisedbsd":               "BSD-3-Clause",
+	"bsd4clause":               "BSD-4-Clause",
+	"0bsd":                     "0BSD",
+	"mpl11":                    "MPL-1.1",
+	"mpl2":                     "MPL-2.0",
+	"mpl20":                    "MPL-2.0",
+	"mozillapublic20":          "MPL-2.0",
+	"epl1":                     "EPL-1.0",
+	"epl10":                    "EPL-1.0",
+	"epl2":                     "EPL-2.0",
+	"epl20":                    "EPL-2.0",
+	"psf":                      "PSF-2.0",
+	"psf2":                     "PSF-2.0",
+	"python":                   "PSF-2.0",
+	"pythonsoftwarefoundation": "PSF-2.0",
+	"zlib":                     "Zlib",
+	"zlibpng":                  "Zlib",
+	"unlicense":                "Unlicense",
+	"cc0":                      "CC0-1.0",
+	"cc010":                    "CC0-1.0",
+	"artistic":                 "Artistic-1.0-Perl",
+	"artistic2":                "Artistic-2.0",
+	"artistic20":               "Artistic-2.0",
+	"boost":                    "BSL-1.0",
+	"bsl1":                     "BSL-1.0",
+	"bsl10":                    "BSL-1.0",
+	"ofl11":                    "OFL-1.1",
+	"zpl21":                    "ZPL-2.1",
+	"wtfpl":                    "WTFPL",
+	"openssl":                  "OpenSSL",
+	"curl":                     "curl",
+}
+
+var (
+	// GNU licenses reduced by licenseKeyOf, e.g. gpl2+ or lgpl21
+	gnuLicenseKey  = regexp.MustCompile(`^(a|l|)gpl(\d+)(\+?)$`)
+	gfdlLicenseKey = regexp.MustCompile(`^gfdl(\d+)(\+?)$`)
+	// a version prefixed by v, e.g. GPLv2
+	licenseVersionPrefix = regexp.MustCompile(`([a-z])v(\d)`)
+	licenseNoise         = regexp.MustCompile(`\b(the|license|licence|version|gnu|general|public|lesser|library|affero)\b`)
+	orLater              = regexp.MustCompile(`(?i)\s*(,\s*)?or (any )?later( version)?`)
+)
+
+// licenseKeyOf reduces a license name to a lookup key, ignoring case,
+// punctuation and filler words: "Apache License, Version 2.0" becomes "apache20".
+func licenseKeyOf(name string) string {
+	key := strings.ToLower(name)
+	key = strings.Replace(key, "-only", "", 1)
+	key = strings.Replace(key, "-or-later", "+", 1)
+	isGNU := strings.Contains(key, "general public")
+	isLesser := strings.Contains(key, "lesser") || strings.Contains(key, "library general")
+	isAffero := strings.Contains(key, "affero")
+	key = licenseNoise.ReplaceAllString(key, "")
+	key = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '+' {
+			return r
+		}
+		return -1
+	}, key)
+	key = licenseVersionPrefix.ReplaceAllString(key, "$1$2")
+	if isGNU && !strings.Contains(key, "gpl") {
+		// spelled out, e.g. "GNU Lesser General Public License v2.1"
+		prefix := "gpl"
+		if isLesser {
+			prefix = "lgpl"
+		} else if isAffero {
+			prefix = "agpl"
+		}
+		key = prefix + strings.TrimPrefix(key, "v")
+	}
+	return key
+}
+
+// normalizeLicenseName returns the SPDX identifier of a single license name, or
+// the name itself if it isn't known.
+func normalizeLicenseName(name string) string {
+	key := licenseKeyOf(name)
+	if id, ok := spdxLicenses[key]; ok {
+		return id
+	}
+	if m := gnuLicenseKey.FindStringSubmatch(key); m != nil {
+		return strings.ToUpper(m[1]) + "GPL-" + spdxVersion(m[2]) + spdxRange(m[3])
+	}
+	if m := gfdlLicenseKey.FindStringSubmatch(key); m != nil {
+		return "GFDL-" + spdxVersion(m[1]) + spdxRange(m[2])
+	}
+	return name
+}
+
+// spdxVersion turns the digits of a version into its SPDX form: 2 and 20 are 2.0, 21 is 2.1.
+func spdxVersion(digits string) string {
+	if len(digits) == 1 {
+		return digits + ".0"
+	}
+	return digits[:1] + "." + digits[1:]
+}
+
+func spdxRange(plus string) string {
+	if plus == "+" {
+		return "-or-later"
+	}
+	return "-only"
+}
+
+// NormalizeLicense normalizes a declared license to an SPDX license expression:
+// the known license names are replaced by their SPDX identifier and the and/or/with
+// operators are upper cased, e.g. "GPLv2+ and (LGPLv2+ or MIT)" becomes
+// "GPL-2.0-or-later AND (LGPL-2.0-or-later OR MIT)". Unknown names are kept as
+// declared, and only the first line of a full license text is considered.
+func NormalizeLicense(license string) string {
+	license = strings.TrimSpace(strings.SplitN(strings.TrimSpace(license), "\n", 2)[0])
+	if license == "" {
+		return ""
+	}
+	license = orLater.ReplaceAllString(license, "+")
+	license = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(license)
+
+	var tokens, term []string
+	flush := func() {
+		if len(term) > 0 {
+			tokens = append(tokens, normalizeLicenseName(strings.Join(term, " ")))
+			term = nil
+		}
+	}
+	for _, word := range strings.Fields(license) {
+		switch lower := strings.ToLower(word); lower {
+		case "and", "or", "with", "&":
+			flush()
+			if lower == "&" {
+				lower = "and"
+			}
+			tokens = append(tokens, strings.ToUpper(lower))
+		case "(", ")":
+			flush()
+			tokens = append(tokens, word)
+		default:
+			term = append(term, word)
+		}
+	}
+	flush()
+	expression := strings.Join(tokens, " ")
+	return strings.NewReplacer("( ", "(", " )", ")").Replace(expression)
+}
+
+// CombineLicenses joins the distinct licenses found for a package into a single
+// expression requiring all of them.
+func CombineLicenses(licenses []string) string {
+	var distinct []string
+	seen := make(map[string]bool)
+	for _, license := range licenses {
+		if license == "" || seen[license] {
+			continue
+		}
+		seen[license] = true
+		if strings.Contains(license, " OR ") && !strings.HasPrefix(license, "(") {
+			license = "(" + license + ")"
+		}
+		distinct = append(distinct, license)
+	}
+	return strings.Join(distinct, " AND ")
+}
+
+// spdxIdentifiers are SPDX license identifiers recognized besides those of spdxLicenses
+// and the GNU licenses.
+var spdxIdentifiers = []string{
+	"AFL-2.1", "AFL-3.0", "Apache-1.0", "Beerware", "BSD-1-Clause", "BSD-2-Clause-Patent",
+	"CC-BY-3.0", "CC-BY-4.0", "CC-BY-SA-3.0", "CC-BY-SA-4.0", "CDDL-1.0", "CDDL-1.1",
+	"ECL-2.0", "EUPL-1.1", "EUPL-1.2", "FTL", "HPND", "ICU", "IJG", "libpng", "libtiff",
+	"MIT-0", "MPL-1.0", "MS-PL", "NCSA", "OLDAP-2.8", "OpenLDAP", "PHP-3.0", "PHP-3.01",
+	"PostgreSQL", "Python-2.0", "Ruby", "SGI-B-2.0", "Sleepycat", "TCL", "UPL-1.0",
+	"Unicode-DFS-2016", "Vim", "W3C", "X11", "Zlib", "ZPL-2.0",
+}
+
+// spdxExceptions are the SPDX license exception identifiers recognized after WITH.
+var spdxExceptions = map[string]bool{
+	"Autoconf-exception-3.0": true, "Bison-exception-2.2": true, "Classpath-exception-2.0": true,
+	"GCC-exception-2.0": true, "GCC-exception-3.1": true, "LLVM-exception": true,
+	"openvpn-openssl-exception": true, "OCaml-LGPL-linking-exception": true,
+}
+
+var gnuSPDXIdentifier = regexp.MustCompile(`^(A|L)?GPL-\d\.\d-(only|or-later)$|^GFDL-1\.\d-(only|or-later)$`)
+
+// isSPDXIdentifier checks whether id is a known SPDX license identifier or a license reference.
+func isSPDXIdentifier(id string) bool {
+	if strings.HasPrefix(id, "LicenseRef-") || gnuSPDXIdentifier.MatchString(id) {
+		return true
+	}
+	for _, known := range spdxLicenses {
+		if id == known {
+			return true
+		}
+	}
+	for _, known := range spdxIdentifiers {
+		if id == known {
+			return true
+		}
+	}
+	return false
+}
+
+// licenseRefID returns the LicenseRef- identifier of a license name: the characters
+// SPDX doesn't allow in identifiers are replaced by dashes.
+func licenseRefID(name string) string {
+	return "LicenseRef-" + strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// SPDXExpression turns a license, as normalized by NormalizeLicense, into a valid
+// SPDX license expression: the license names which aren't SPDX identifiers are
+// replaced by LicenseRef- identifiers, returned along with the names they stand
+// for. License exceptions which aren't SPDX identifiers are kept as part of the
+// reference of the license they apply to.
+func SPDXExpression(license string) (string, map[string]string) {
+	refs := make(map[string]string)
+	ref := func(name string) string {
+		id := licenseRefID(name)
+		refs[id] = name
+		return id
+	}
+
+	var tokens, term []string
+	with := false
+	flush := func() {
+		if len(term) == 0 {
+			return
+		}
+		name := strings.Join(term, " ")
+		term = nil
+		switch {
+		case with && spdxExceptions[name]:
+			tokens = append(tokens, name)
+		case with && !isLicenseTerm(tokens, len(tokens)-2):
+			// unknown exception of a compound expression
+			tokens[len(tokens)-1] = "AND"
+			tokens = append(tokens, ref(name))
+		case with:
+			// unknown exception: reference the license with its exception as a whole
+			license := tokens[len(tokens)-2]
+			if refName, isRef := refs[license]; isRef {
+				delete(refs, license)
+				license = refName
+			}
+			tokens = append(tokens[:len(tokens)-2], ref(license+" WITH "+name))
+		case isSPDXIdentifier(name):
+			tokens = append(tokens, name)
+		default:
+			tokens = append(tokens, ref(name))
+		}
+		with = false
+	}
+	spaced := strings.NewReplacer("(", " ( ", ")", " ) ").Replace(license)
+	for _, word := range strings.Fields(spaced) {
+		switch word {
+		case "AND", "OR", "WITH", "(", ")":
+			flush()
+			tokens = append(tokens, word)
+			with = word == "WITH"
+		default:
+			term = append(term, word)
+		}
+	}
+	flush()
+	expression := strings.Join(tokens, " ")
+	return strings.NewReplacer("( ", "(", " )", ")").Replace(expression), refs
+}
+
+// isLicenseTerm checks whether the token at index i is a license rather than an
+// operator or a parenthesis.
+func isLicenseTerm(tokens []string, i int) bool {
+	if i < 0 || i >= len(tokens) {
+		return false
+	}
+	switch tokens[i] {
+	case "AND", "OR", "WITH", "(", ")":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeLicense(t *testing.T) {
+	testCases := []struct {
+		license  string
+		expected string
+	}{
+		{"", ""},
+		{"MIT", "MIT"},
+		{"MIT License", "MIT"},
+		{"Expat", "MIT"},
+		{"Apache License, Version 2.0", "Apache-2.0"},
+		{"ASL 2.0", "Apache-2.0"},
+		{"Apache-2.0", "Apache-2.0"},
+		{"BSD-3-clause", "BSD-3-Clause"},
+		{"GPL-2+", "GPL-2.0-or-later"},
+		{"GPLv2", "GPL-2.0-only"},
+		{"GPL-2.0-only", "GPL-2.0-only"},
+		{"LGPL-2.1+", "LGPL-2.1-or-later"},
+		{"GNU Lesser General Public License v3", "LGPL-3.0-only"},
+		{"GNU General Public License version 2 or later", "GPL-2.0-or-later"},
+		{"GPLv2+ and (LGPLv2+ or MIT)", "GPL-2.0-or-later AND (LGPL-2.0-or-later OR MIT)"},
+		{"GPL-2+ or Artistic", "GPL-2.0-or-later OR Artistic-1.0-Perl"},
+		{"GPL-2+ with OpenSSL exception", "GPL-2.0-or-later WITH OpenSSL exception"},
+		{"Python Software Foundation License", "PSF-2.0"},
+		{"public-domain", "public-domain"},
+		{"Copyright (c) 2010 Someone\nPermission is hereby granted", "Copyright (c) 2010 Someone"},
+	}
+	for _, test := range testCases {
+		if actual := NormalizeLicense(test.license); actual != test.expected {
+			t.Errorf("NormalizeLicense(%q): expected %q but got %q", test.license, test.expected, actual)
+		}
+	}
+}
+
+func TestCombineLicenses(t *testing.T) {
+	combined := CombineLicenses([]string{"GPL-2.0-or-later", "", "MIT OR Apache-2.0", "GPL-2.0-or-later"})
+	if expected := "GPL-2.0-or-later AND (MIT OR Apache-2.0)"; combined != expected {
+		t.Errorf("Expected %q but got %q", expected, combined)
+	}
+}
+
+func TestGetLicenseDiff(t *testing.T) {
+	licenses1 := []PackageLicense{
+		{Package: "bash", Manager: "apt", Version: "5.1", License: "GPL-3.0-or-later"},
+		{Package: "libssl", Manager: "apt", Version: "1.1", License: "OpenSSL"},
+		{Package: "requests", Manager: "pip", Path: "/usr/lib/python3/dist-packages", Version: "2.28.0", License: "Apache-2.0"},
+		{Package: "left-pad", Manager: "node", Path: "/app/node_modules/left-pad/", Version: "1.0.0", License: "WTFPL"},
+		{Package: "mystery", Manager: "node", Path: "/app/node_modules/mystery/", Version: "1.0.0"},
+	}
+	licenses2 := []PackageLicense{
+		{Package: "bash", Manager: "apt", Version: "5.2", License: "GPL-3.0-or-later"},
+		{Package: "libssl", Manager: "apt", Version: "3.0", License: "Apache-2.0"},
+		{Package: "requests", Manager: "pip", Path: "/usr/lib/python3/dist-packages", Version: "2.31.0"},
+		{Package: "left-pad", Manager: "node", Path: "/app/node_modules/left-pad/", Version: "1.3.0", License: "WTFPL"},
+		{Package: "mystery", Manager: "node", Path: "/app/node_modules/mystery/", Version: "1.1.0", License: "ISC"},
+		{Package: "zlib1g", Manager: "apt", Version: "1.2.13", License: "Zlib"},
+	}
+	expected := LicenseDiff{
+		Added: []PackageLicense{
+			{Package: "zlib1g", Manager: "apt", Version: "1.2.13", License: "Zlib"},
+			{Package: "mystery", Manager: "node", Path: "/app/node_modules/mystery/", Version: "1.1.0", License: "ISC"},
+		},
+		Removed: []PackageLicense{
+			{Package: "requests", Manager: "pip", Path: "/usr/lib/python3/dist-packages", Version: "2.28.0", License: "Apache-2.0"},
+		},
+		Changed: []LicenseChange{
+			{Package: "libssl", Manager: "apt", Version1: "1.1", Version2: "3.0", License1: "OpenSSL", License2: "Apache-2.0"},
+		},
+	}
+	if diff := GetLicenseDiff(licenses1, licenses2); !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, diff)
+	}
+}
+
+func TestSPDXExpression(t *testing.T) {
+	testCases := []struct {
+		license    string
+		expression string
+		refs       map[string]string
+	}{
+		{"MIT", "MIT", map[string]string{}},
+		{"GPL-2.0-or-later AND (BSD-3-Clause OR MIT)", "GPL-2.0-or-later AND (BSD-3-Clause OR MIT)", map[string]string{}},
+		{"Apache-2.0 WITH LLVM-exception", "Apache-2.0 WITH LLVM-exception", map[string]string{}},
+		{"public-domain OR MIT", "LicenseRef-public-domain OR MIT", map[string]string{"LicenseRef-public-domain": "public-domain"}},
+		{"Custom License v1", "LicenseRef-Custom-License-v1", map[string]string{"LicenseRef-Custom-License-v1": "Custom License v1"}},
+		{"GPL-2.0-or-later WITH OpenSSL exception", "LicenseRef-GPL-2.0-or-later-WITH-OpenSSL-exception",
+			map[string]string{"LicenseRef-GPL-2.0-or-later-WITH-OpenSSL-exception": "GPL-2.0-or-later WITH OpenSSL exception"}},
+	}
+	for _, test := range testCases {
+		expression, refs := SPDXExpression(test.license)
+		if expression != test.expression || !reflect.DeepEqual(refs, test.refs) {
+			t.Errorf("SPDXExpression(%q): expected %q %v but got %q %v", test.license, test.expression, test.refs, expression, refs)
+		}
+	}
+}
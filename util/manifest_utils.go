@@ -0,0 +1,123 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ImageManifest stores the manifest of an image.
+type ImageManifest struct {
+	Digest        string
+	MediaType     string
+	SchemaVersion int64
+	Config        ManifestDescriptor
+	Layers        []LayerDescriptor
+	Annotations   map[string]string
+	// Subject is the digest of the manifest the image refers to, e.g. for
+	// signatures and attestations.
+	Subject string
+}
+
+// ManifestDescriptor stores the descriptor of a blob referenced by a manifest.
+type ManifestDescriptor struct {
+	MediaType string
+	Digest    string
+	Size      int64
+}
+
+// LayerDescriptor stores the descriptor of a layer of an image manifest.
+type LayerDescriptor struct {
+	MediaType string
+	// Compression is the compression of the layer given by its media type:
+	// gzip, zstd or uncompressed.
+	Compression string
+	Digest      string
+	// DiffID is the digest of the uncompressed layer.
+	DiffID string
+	// Size is the size of the layer as stored, i.e. compressed.
+	Size        int64
+	URLs        []string          `json:",omitempty"`
+	Annotations map[string]string `json:",omitempty"`
+}
+
+// LayerChange stores the layers at the same index of two image manifests. A
+// layer missing from an image has no descriptor.
+type LayerChange struct {
+	Index  int
+	Layer1 *LayerDescriptor `json:",omitempty"`
+	Layer2 *LayerDescriptor `json:",omitempty"`
+}
+
+// ManifestDiff stores the differences between the manifests of two images.
+// Annotations are compared key by key and layers by index.
+type ManifestDiff struct {
+	Fields      []FieldChange
+	Annotations []KeyValueChange
+	Layers      []LayerChange
+}
+
+// keyedManifestFields are the fields of ImageManifest not compared as a whole
+var keyedManifestFields = map[string]bool{"Annotations": true, "Layers": true}
+
+// GetManifestDiff compares the manifests of two images field by field.
+func GetManifestDiff(m1, m2 ImageManifest) ManifestDiff {
+	diff := ManifestDiff{
+		Fields:      getFieldChanges(m1, m2, keyedManifestFields),
+		Annotations: getKeyValueChanges(m1.Annotations, m2.Annotations),
+		Layers:      []LayerChange{},
+	}
+	for i := 0; i < len(m1.Layers) || i < len(m2.Layers); i++ {
+		change := LayerChange{Index: i}
+		if i < len(m1.Layers) {
+			change.Layer1 = &m1.Layers[i]
+		}
+		if i < len(m2.Layers) {
+			change.Layer2 = &m2.Layers[i]
+		}
+		if change.Layer1 == nil || change.Layer2 == nil || !equalMetadataValues(*change.Layer1, *change.Layer2) {
+			diff.Layers = append(diff.Layers, change)
+		}
+	}
+	return diff
+}
+
+// LayerCompression returns the compression of a layer given by its media type,
+// or an empty string if unknown.
+func LayerCompression(mediaType string) string {
+	switch {
+	case strings.HasSuffix(mediaType, "+zstd"):
+		return "zstd"
+	case strings.HasSuffix(mediaType, "+gzip"), strings.HasSuffix(mediaType, ".tar.gzip"):
+		return "gzip"
+	case strings.HasSuffix(mediaType, ".tar"):
+		return "uncompressed"
+	}
+	return ""
+}
+
+func (d ManifestDescriptor) String() string {
+	return fmt.Sprintf("%s (%s, %s)", d.Digest, d.MediaType, stringifySize(d.Size))
+}
+
+func (l LayerDescriptor) String() string {
+	if l.Compression == "" {
+		return fmt.Sprintf("%s (%s)", l.Digest, stringifySize(l.Size))
+	}
+	return fmt.Sprintf("%s (%s, %s)", l.Digest, l.Compression, stringifySize(l.Size))
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLayerCompression(t *testing.T) {
+	testCases := []struct {
+		mediaType string
+		expected  string
+	}{
+		{"application/vnd.oci.image.layer.v1.tar+gzip", "gzip"},
+		{"application/vnd.oci.image.layer.v1.tar+zstd", "zstd"},
+		{"application/vnd.oci.image.layer.v1.tar", "uncompressed"},
+		{"application/vnd.oci.image.layer.nondistributable.v1.tar+gzip", "gzip"},
+		{"application/vnd.docker.image.rootfs.diff.tar.gzip", "gzip"},
+		{"application/vnd.docker.image.rootfs.foreign.diff.tar.gzip", "gzip"},
+		{"application/vnd.docker.image.rootfs.diff.tar", "uncompressed"},
+		{"application/vnd.in-toto+json", ""},
+	}
+	for _, test := range testCases {
+		if compression := LayerCompression(test.mediaType); compression != test.expected {
+			t.Errorf("LayerCompression(%s): expected %s but got %s", test.mediaType, test.expected, compression)
+		}
+	}
+}
+
+func TestGetManifestDiff(t *testing.T) {
+	base := LayerDescriptor{MediaType: "application/vnd.oci.image.layer.v1.tar+gzip", Compression: "gzip", Digest: "sha256:aa", DiffID: "sha256:a0", Size: 2048}
+	app1 := LayerDescriptor{MediaType: "application/vnd.oci.image.layer.v1.tar+gzip", Compression: "gzip", Digest: "sha256:bb", DiffID: "sha256:b0", Size: 4096}
+	app2 := LayerDescriptor{MediaType: "application/vnd.oci.image.layer.v1.tar+zstd", Compression: "zstd", Digest: "sha256:cc", DiffID: "sha256:b0", Size: 3072}
+	extra := LayerDescriptor{MediaType: "application/vnd.oci.image.layer.v1.tar", Compression: "uncompressed", Digest: "sha256:dd", DiffID: "sha256:dd", Size: 512}
+	config := ManifestDescriptor{MediaType: "application/vnd.oci.image.config.v1+json", Digest: "sha256:c0", Size: 1024}
+	m1 := ImageManifest{
+		Digest:        "sha256:01",
+		MediaType:     "application/vnd.oci.image.manifest.v1+json",
+		SchemaVersion: 2,
+		Config:        config,
+		Layers:        []LayerDescriptor{base, app1},
+		Annotations:   map[string]string{"org.opencontainers.image.version": "1.0"},
+	}
+	m2 := ImageManifest{
+		Digest:        "sha256:02",
+		MediaType:     "application/vnd.oci.image.manifest.v1+json",
+		SchemaVersion: 2,
+		Config:        config,
+		Layers:        []LayerDescriptor{base, app2, extra},
+		Annotations:   map[string]string{"org.opencontainers.image.version": "1.1"},
+	}
+	version1, version2 := "1.0", "1.1"
+	expected := ManifestDiff{
+		Fields:      []FieldChange{{Field: "Digest", Value1: "sha256:01", Value2: "sha256:02"}},
+		Annotations: []KeyValueChange{{Key: "org.opencontainers.image.version", Value1: &version1, Value2: &version2}},
+		Layers: []LayerChange{
+			{Index: 1, Layer1: &app1, Layer2: &app2},
+			{Index: 2, Layer2: &extra},
+		},
+	}
+	diff := GetManifestDiff(m1, m2)
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, diff)
+	}
+
+	expectedStr := StrManifestDiff{
+		Fields: []StrMetadataChange{
+			{"Digest", "sha256:01", "sha256:02"},
+			{"Annotations[org.opencontainers.image.version]", "1.0", "1.1"},
+		},
+		Layers: []StrLayerChange{
+			{1, "sha256:bb (gzip, 4K)", "sha256:cc (zstd, 3K)"},
+			{2, "none", "sha256:dd (uncompressed, 512B)"},
+		},
+	}
+	if strDiff := stringifyManifestDiff(diff); !reflect.DeepEqual(strDiff, expectedStr) {
+		t.Errorf("Expected: %v but got: %v", expectedStr, strDiff)
+	}
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ImageMetadata stores the configuration of an image: its platform and
+// provenance, the runtime configuration of its containers and the digests of
+// its uncompressed layers.
+type ImageMetadata struct {
+	Architecture string
+	OS           string
+	// OSVersion and OSFeatures are the version and features of the OS
+	// required by the image, for Windows images.
+	OSVersion     string
+	OSFeatures    []string
+	Variant       string
+	Created       string
+	Author        string
+	DockerVersion string
+	// ConfigDigest is the digest of the config blob of the image.
+	ConfigDigest string
+	User         string
+	// ExposedPorts are the exposed ports, as port/protocol.
+	ExposedPorts []string
+	// Env maps the environment variables to their value.
+	Env         map[string]string
+	Entrypoint  []string
+	Cmd         []string
+	Healthcheck *Healthcheck
+	ArgsEscaped bool
+	Volumes     []string
+	WorkingDir  string
+	Labels      map[string]string
+	StopSignal  string
+	Shell       []string
+	OnBuild     []string
+	// DiffIDs are the digests of the uncompressed layers, from rootfs.diff_ids.
+	DiffIDs []string
+}
+
+// Healthcheck stores the command checking the health of the containers of an
+// image. Zero durations and retries are inherited.
+type Healthcheck struct {
+	Test        []string
+	Interval    time.Duration `json:",omitempty"`
+	Timeout     time.Duration `json:",omitempty"`
+	StartPeriod time.Duration `json:",omitempty"`
+	Retries     int           `json:",omitempty"`
+}
+
+func (h Healthcheck) String() string {
+	var options []string
+	if h.Interval != 0 {
+		options = append(options, "interval "+h.Interval.String())
+	}
+	if h.Timeout != 0 {
+		options = append(options, "timeout "+h.Timeout.String())
+	}
+	if h.StartPeriod != 0 {
+		options = append(options, "start period "+h.StartPeriod.String())
+	}
+	if h.Retries != 0 {
+		options = append(options, fmt.Sprintf("retries %d", h.Retries))
+	}
+	test := strings.Join(h.Test, " ")
+	if len(options) == 0 {
+		return test
+	}
+	return fmt.Sprintf("%s (%s)", test, strings.Join(options, ", "))
+}
+
+// FieldChange stores the values of a metadata field in two images.
+type FieldChange struct {
+	Field  string
+	Value1 interface{}
+	Value2 interface{}
+}
+
+// KeyValueChange stores the values of a key of a metadata map in two images.
+// The value is nil in the image the key is missing from.
+type KeyValueChange struct {
+	Key    string
+	Value1 *string `json:",omitempty"`
+	Value2 *string `json:",omitempty"`
+}
+
+// KeyDiff stores the keys of a metadata set found only in Image2 (Adds) and
+// only in Image1 (Dels).
+type KeyDiff struct {
+	Adds []string
+	Dels []string
+}
+
+// MetadataDiff stores the differences between the metadata of two images. Env,
+// Labels, ExposedPorts and Volumes are compared key by key, the other fields
+// as a whole. Changes of the fields identifying the build of an image are
+// kept apart in Build, as they differ between any two builds.
+type MetadataDiff struct {
+	Fields       []FieldChange
+	Build        []FieldChange
+	Env          []KeyValueChange
+	Labels       []KeyValueChange
+	ExposedPorts KeyDiff
+	Volumes      KeyDiff
+}
+
+// buildMetadataFields are the fields of ImageMetadata identifying a build of
+// the image rather than configuring it.
+var buildMetadataFields = map[string]bool{
+	"Created": true, "DockerVersion": true, "ConfigDigest": true,
+}
+
+// skippedMetadataFields are the fields of ImageMetadata not compared as a
+// whole: those compared key by key, and the layer digests, as layer changes
+// are reported by the manifest and history differs.
+var skippedMetadataFields = map[string]bool{
+	"Env": true, "Labels": true, "ExposedPorts": true, "Volumes": true,
+	"DiffIDs": true,
+}
+
+// GetMetadataDiff compares the metadata of two images field by field.
+func GetMetadataDiff(m1, m2 ImageMetadata) MetadataDiff {
+	diff := MetadataDiff{
+		Fields:       []FieldChange{},
+		Build:        []FieldChange{},
+		Env:          getKeyValueChanges(m1.Env, m2.Env),
+		Labels:       getKeyValueChanges(m1.Labels, m2.Labels),
+		ExposedPorts: KeyDiff{Adds: GetAdditions(m1.ExposedPorts, m2.ExposedPorts), Dels: GetDeletions(m1.ExposedPorts, m2.ExposedPorts)},
+		Volumes:      KeyDiff{Adds: GetAdditions(m1.Volumes, m2.Volumes), Dels: GetDeletions(m1.Volumes, m2.Volumes)},
+	}
+	for _, change := range getFieldChanges(m1, m2, skippedMetadataFields) {
+		if buildMetadataFields[change.Field] {
+			diff.Build = append(diff.Build, change)
+		} else {
+			diff.Fields = append(diff.Fields, change)
+		}
+	}
+	return diff
+}
+
+// getFieldChanges compares the fields of two structs of the same type, but
+// those in skip, and returns the changed ones with their values.
+func getFieldChanges(s1, s2 interface{}, skip map[string]bool) []FieldChange {
+	changes := []FieldChange{}
+	v1, v2 := reflect.ValueOf(s1), reflect.ValueOf(s2)
+	for i := 0; i < v1.NumField(); i++ {
+		field := v1.Type().Field(i).Name
+		if skip[field] {
+			continue
+		}
+		value1, value2 := v1.Field(i).Interface(), v2.Field(i).Interface()
+		if !equalMetadataValues(value1, value2) {
+			changes = append(changes, FieldChange{Field: field, Value1: value1, Value2: value2})
+		}
+	}
+	return changes
+}
+
+// equalMetadataValues compares two values of a metadata field, considering
+// nil and empty arrays equal.
+func equalMetadataValues(value1, value2 interface{}) bool {
+	if a1, ok := value1.([]string); ok {
+		a2 := value2.([]string)
+		return len(a1) == 0 && len(a2) == 0 || reflect.DeepEqual(a1, a2)
+	}
+	return reflect.DeepEqual(value1, value2)
+}
+
+func getKeyValueChanges(map1, map2 map[string]string) []KeyValueChange {
+	changes := []KeyValueChange{}
+	for key, value1 := range map1 {
+		value1 := value1
+		if value2, ok := map2[key]; !ok {
+			changes = append(changes, KeyValueChange{Key: key, Value1: &value1})
+		} else if value1 != value2 {
+			changes = append(changes, KeyValueChange{Key: key, Value1: &value1, Value2: &value2})
+		}
+	}
+	for key, value2 := range map2 {
+		value2 := value2
+		if _, ok := map1[key]; !ok {
+			changes = append(changes, KeyValueChange{Key: key, Value2: &value2})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// formatMetadataValue formats the value of a metadata field for text output:
+// arrays as JSON arrays, like the exec form of Dockerfile instructions, and
+// maps as their sorted key=value pairs.
+func formatMetadataValue(value interface{}) string {
+	switch v := value.(type) {
+	case []string:
+		if len(v) == 0 {
+			return ""
+		}
+		array, _ := json.Marshal(v)
+		return string(array)
+	case *Healthcheck:
+		if v == nil {
+			return ""
+		}
+		return v.String()
+	case map[string]string:
+		var pairs []string
+		for key, val := range v {
+			pairs = append(pairs, key+"="+val)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ", ")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
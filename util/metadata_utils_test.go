@@ -0,0 +1,90 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetMetadataDiff(t *testing.T) {
+	m1 := ImageMetadata{
+		Created:      "2024-01-15T10:30:00Z",
+		ConfigDigest: "sha256:c1",
+		DiffIDs:      []string{"sha256:a1"},
+		User:         "root",
+		ExposedPorts: []string{"8080/tcp", "8443/tcp"},
+		Env:          map[string]string{"PATH": "/usr/bin", "LANG": "C.UTF-8", "DEBUG": "1"},
+		Cmd:          []string{"python", "app.py"},
+		Volumes:      []string{},
+		Entrypoint:   []string{},
+		Labels:       map[string]string{"maintainer": "team@example.com"},
+	}
+	m2 := ImageMetadata{
+		Created:       "2024-02-15T10:30:00Z",
+		ConfigDigest:  "sha256:c2",
+		DockerVersion: "24.0.7",
+		DiffIDs:       []string{"sha256:a1", "sha256:a2"},
+		User:          "root",
+		ExposedPorts:  []string{"8080/tcp", "9090/tcp"},
+		Env:           map[string]string{"PATH": "/usr/local/bin:/usr/bin", "LANG": "C.UTF-8", "EMPTY": ""},
+		Cmd:           []string{"python", "main.py"},
+		Volumes:       []string{"/data"},
+		Labels:        map[string]string{"maintainer": "team@example.com"},
+	}
+	debug, empty, path1, path2 := "1", "", "/usr/bin", "/usr/local/bin:/usr/bin"
+	expected := MetadataDiff{
+		Fields: []FieldChange{{Field: "Cmd", Value1: []string{"python", "app.py"}, Value2: []string{"python", "main.py"}}},
+		Build: []FieldChange{
+			{Field: "Created", Value1: "2024-01-15T10:30:00Z", Value2: "2024-02-15T10:30:00Z"},
+			{Field: "DockerVersion", Value1: "", Value2: "24.0.7"},
+			{Field: "ConfigDigest", Value1: "sha256:c1", Value2: "sha256:c2"},
+		},
+		Env: []KeyValueChange{
+			{Key: "DEBUG", Value1: &debug},
+			{Key: "EMPTY", Value2: &empty},
+			{Key: "PATH", Value1: &path1, Value2: &path2},
+		},
+		Labels:       []KeyValueChange{},
+		ExposedPorts: KeyDiff{Adds: []string{"9090/tcp"}, Dels: []string{"8443/tcp"}},
+		Volumes:      KeyDiff{Adds: []string{"/data"}, Dels: []string{}},
+	}
+	diff := GetMetadataDiff(m1, m2)
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, diff)
+	}
+
+	expectedRows := StrMetadataDiff{
+		Fields: []StrMetadataChange{
+			{"Cmd", `["python","app.py"]`, `["python","main.py"]`},
+			{"Env[DEBUG]", "1", "none"},
+			{"Env[EMPTY]", "none", ""},
+			{"Env[PATH]", "/usr/bin", "/usr/local/bin:/usr/bin"},
+			{"ExposedPorts", "8443/tcp", "none"},
+			{"ExposedPorts", "none", "9090/tcp"},
+			{"Volumes", "none", "/data"},
+		},
+		Build: []StrMetadataChange{
+			{"Created", "2024-01-15T10:30:00Z", "2024-02-15T10:30:00Z"},
+			{"DockerVersion", "none", "24.0.7"},
+			{"ConfigDigest", "sha256:c1", "sha256:c2"},
+		},
+	}
+	if rows := stringifyMetadataDiff(diff); !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("Expected: %v but got: %v", expectedRows, rows)
+	}
+}
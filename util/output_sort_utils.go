@@ -240,6 +240,28 @@ func sortDirDiff(diff DirDiff) DirDiff {
 	return DirDiff{adds, dels, mods}
 }
 
+// sortOwnershipAnalysis sorts packages and unowned files by size with SortSize,
+// and by package manager and name or by path otherwise.
+func sortOwnershipAnalysis(analysis OwnershipAnalysis) OwnershipAnalysis {
+	packages := analysis.Packages
+	sort.Slice(packages, func(i, j int) bool {
+		p1, p2 := packages[i], packages[j]
+		if SortSize && p1.Size != p2.Size {
+			return p1.Size > p2.Size
+		}
+		if p1.Manager != p2.Manager {
+			return p1.Manager < p2.Manager
+		}
+		return p1.Package < p2.Package
+	})
+	if SortSize {
+		directoryBy(directorySizeSort).Sort(analysis.Unowned)
+	} else {
+		directoryBy(directoryNameSort).Sort(analysis.Unowned)
+	}
+	return analysis
+}
+
 type entryDiffBy func(a, b *EntryDiff) bool
 
 func (by entryDiffBy) Sort(entryDiffs []EntryDiff) {
@@ -17,6 +17,9 @@ limitations under the License.
 package util
 
 import (
+	"reflect"
+	"strconv"
+
 	"code.cloudfoundry.org/bytefmt"
 	pkgutil "github.com/GoogleContainerTools/container-diff/pkg/util"
 )
@@ -49,6 +52,7 @@ type StrMultiVersionInfo struct {
 	Package string
 	Info1   []StrPackageInfo
 	Info2   []StrPackageInfo
+	Change  string
 }
 
 type StrPackageInfo struct {
@@ -61,9 +65,11 @@ func stringifyPackageInfo(info PackageInfo) StrPackageInfo {
 }
 
 type StrInfo struct {
-	Package string
-	Info1   StrPackageInfo
-	Info2   StrPackageInfo
+	Package   string
+	Info1     StrPackageInfo
+	Info2     StrPackageInfo
+	Change    string
+	Changelog []ChangelogEntry
 }
 
 func stringifyPackageDiff(infoDiff []Info) (strInfoDiff []StrInfo) {
@@ -71,7 +77,7 @@ func stringifyPackageDiff(infoDiff []Info) (strInfoDiff []StrInfo) {
 		strInfo1 := stringifyPackageInfo(diff.Info1)
 		strInfo2 := stringifyPackageInfo(diff.Info2)
 
-		strDiff := StrInfo{Package: diff.Package, Info1: strInfo1, Info2: strInfo2}
+		strDiff := StrInfo{Package: diff.Package, Info1: strInfo1, Info2: strInfo2, Change: string(diff.Change), Changelog: diff.Changelog}
 		strInfoDiff = append(strInfoDiff, strDiff)
 	}
 	return
@@ -89,7 +95,7 @@ func stringifyMultiVersionPackageDiff(infoDiff []MultiVersionInfo) (strInfoDiff
 			strInfos2 = append(strInfos2, stringifyPackageInfo(info))
 		}
 
-		strDiff := StrMultiVersionInfo{Package: diff.Package, Info1: strInfos1, Info2: strInfos2}
+		strDiff := StrMultiVersionInfo{Package: diff.Package, Info1: strInfos1, Info2: strInfos2, Change: string(diff.Change)}
 		strInfoDiff = append(strInfoDiff, strDiff)
 	}
 	return
@@ -149,3 +155,195 @@ func stringifySizeDiffs(entries []SizeDiff) (strEntries []StrSizeDiff) {
 	}
 	return
 }
+
+type StrPackageFiles struct {
+	Package string
+	Manager string
+	Files   int
+	Size    string
+}
+
+func stringifyPackageFiles(packages []PackageFiles) []StrPackageFiles {
+	strPackages := []StrPackageFiles{}
+	for _, pack := range packages {
+		strPackages = append(strPackages, StrPackageFiles{pack.Package, pack.Manager, pack.Files, stringifySize(pack.Size)})
+	}
+	return strPackages
+}
+
+type StrMetadataChange struct {
+	Field  string
+	Value1 string
+	Value2 string
+}
+
+// StrMetadataDiff stores the changes of a metadata diff as rows, the changes
+// of the fields identifying the build of the images apart.
+type StrMetadataDiff struct {
+	Fields []StrMetadataChange
+	Build  []StrMetadataChange
+}
+
+// stringifyMetadataDiff lists the changes of a metadata diff as rows, with the
+// key of changed map entries after the field, e.g. Env[PATH]. Missing and
+// empty values are written as none.
+func stringifyMetadataDiff(diff MetadataDiff) StrMetadataDiff {
+	changes := stringifyFieldChanges(diff.Fields)
+	addKeyValueChanges := func(field string, keyChanges []KeyValueChange) {
+		for _, change := range keyChanges {
+			changes = append(changes, stringifyKeyValueChange(field, change))
+		}
+	}
+	addKeyDiff := func(field string, keys KeyDiff) {
+		for _, key := range keys.Dels {
+			changes = append(changes, StrMetadataChange{field, key, "none"})
+		}
+		for _, key := range keys.Adds {
+			changes = append(changes, StrMetadataChange{field, "none", key})
+		}
+	}
+	addKeyValueChanges("Env", diff.Env)
+	addKeyValueChanges("Labels", diff.Labels)
+	addKeyDiff("ExposedPorts", diff.ExposedPorts)
+	addKeyDiff("Volumes", diff.Volumes)
+	return StrMetadataDiff{Fields: changes, Build: stringifyFieldChanges(diff.Build)}
+}
+
+// stringifyFieldChanges lists the changed fields as rows, writing missing and
+// empty values as none.
+func stringifyFieldChanges(fieldChanges []FieldChange) []StrMetadataChange {
+	changes := []StrMetadataChange{}
+	format := func(v interface{}) string {
+		if value := formatMetadataValue(v); value != "" {
+			return value
+		}
+		return "none"
+	}
+	for _, change := range fieldChanges {
+		changes = append(changes, StrMetadataChange{change.Field, format(change.Value1), format(change.Value2)})
+	}
+	return changes
+}
+
+func stringifyKeyValueChange(field string, change KeyValueChange) StrMetadataChange {
+	value := func(v *string) string {
+		if v == nil {
+			return "none"
+		}
+		return *v
+	}
+	return StrMetadataChange{field + "[" + change.Key + "]", value(change.Value1), value(change.Value2)}
+}
+
+// stringifyMetadata lists the fields of the image metadata as field: value.
+func stringifyMetadata(metadata ImageMetadata) []string {
+	var lines []string
+	v := reflect.ValueOf(metadata)
+	for i := 0; i < v.NumField(); i++ {
+		lines = append(lines, v.Type().Field(i).Name+": "+formatMetadataValue(v.Field(i).Interface()))
+	}
+	return lines
+}
+
+type StrLayerChange struct {
+	Index  int
+	Layer1 string
+	Layer2 string
+}
+
+type StrManifestDiff struct {
+	Fields []StrMetadataChange
+	Layers []StrLayerChange
+}
+
+// stringifyManifestDiff lists the changed fields and annotations of a manifest
+// diff as rows, like stringifyMetadataDiff, and the changed layers by index.
+func stringifyManifestDiff(diff ManifestDiff) StrManifestDiff {
+	strDiff := StrManifestDiff{
+		Fields: stringifyFieldChanges(diff.Fields),
+		Layers: []StrLayerChange{},
+	}
+	for _, change := range diff.Annotations {
+		strDiff.Fields = append(strDiff.Fields, stringifyKeyValueChange("Annotations", change))
+	}
+	layer := func(l *LayerDescriptor) string {
+		if l == nil {
+			return "none"
+		}
+		return l.String()
+	}
+	for _, change := range diff.Layers {
+		strDiff.Layers = append(strDiff.Layers, StrLayerChange{change.Index, layer(change.Layer1), layer(change.Layer2)})
+	}
+	return strDiff
+}
+
+type StrLayerDescriptor struct {
+	MediaType   string
+	Compression string
+	Digest      string
+	DiffID      string
+	Size        string
+}
+
+func stringifyLayerDescriptors(layers []LayerDescriptor) []StrLayerDescriptor {
+	strLayers := []StrLayerDescriptor{}
+	for _, l := range layers {
+		strLayers = append(strLayers, StrLayerDescriptor{l.MediaType, l.Compression, l.Digest, l.DiffID, stringifySize(l.Size)})
+	}
+	return strLayers
+}
+
+type StrHistoryStep struct {
+	Created   string
+	Layer     string
+	Size      string
+	CreatedBy string
+}
+
+func stringifyHistory(history []HistoryStep) []StrHistoryStep {
+	strHistory := []StrHistoryStep{}
+	for _, step := range history {
+		strStep := StrHistoryStep{Created: step.Created, Layer: "none", Size: "none", CreatedBy: step.CreatedBy}
+		if step.Created == "" {
+			strStep.Created = "unknown"
+		}
+		if !step.EmptyLayer && step.Layer != "" {
+			strStep.Layer, strStep.Size = step.Layer, stringifySize(step.Size)
+		}
+		strHistory = append(strHistory, strStep)
+	}
+	return strHistory
+}
+
+type StrHistoryStepChange struct {
+	Step  string
+	Step1 string
+	Step2 string
+}
+
+// stringifyHistoryDiff lists the changed steps of a history diff as rows, the
+// index of a step being the one in each image when it moved.
+func stringifyHistoryDiff(diff HistoryDiff) []StrHistoryStepChange {
+	changes := []StrHistoryStepChange{}
+	step := func(s *HistoryStep) string {
+		if s == nil {
+			return "none"
+		}
+		return s.String()
+	}
+	stepIndex := func(i int) string {
+		if i < 0 {
+			return "none"
+		}
+		return strconv.Itoa(i)
+	}
+	for _, change := range diff.Steps {
+		index := stepIndex(change.Index1)
+		if change.Index1 != change.Index2 {
+			index += " -> " + stepIndex(change.Index2)
+		}
+		changes = append(changes, StrHistoryStepChange{index, step(change.Step1), step(change.Step2)})
+	}
+	return changes
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"github.com/GoogleContainerTools/container-diff/pkg/util"
+)
+
+// PackageFiles stores the number and total size of the files a package owns.
+type PackageFiles struct {
+	Package string
+	// Manager is the package manager the package was installed with, e.g. apt or pip.
+	Manager string
+	Files   int
+	Size    int64
+}
+
+// FileOwners stores the packages owning a file, as manager:package.
+type FileOwners struct {
+	Path   string
+	Owners []string
+}
+
+// OwnershipAnalysis stores the file ownership of an image: the files owned by each
+// package, the files no package owns and the files several packages own.
+type OwnershipAnalysis struct {
+	Packages   []PackageFiles
+	Unowned    []util.DirectoryEntry
+	MultiOwned []FileOwners
+}
+
+// OwnershipChange stores the owners of a file present in two images, as manager:package.
+// A file no package owns has no owners.
+type OwnershipChange struct {
+	Path    string
+	Owners1 []string
+	Owners2 []string
+}
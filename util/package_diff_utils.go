@@ -37,6 +37,7 @@ type MultiVersionInfo struct {
 	Package string
 	Info1   []PackageInfo
 	Info2   []PackageInfo
+	Change  VersionChange `json:",omitempty"`
 }
 
 // PackageDiff stores the difference information between two images.
@@ -52,17 +53,111 @@ type PackageLayerDiff struct {
 	PackageDiffs []PackageDiff
 }
 
+// PackageLayerChangeDiff stores how the package changes introduced by the layer at
+// the same index differ between two images. Each PackageDiff compares the packages
+// installed, removed or updated by the layer in Image1 with those of the layer in Image2.
+type PackageLayerChangeDiff struct {
+	Layer     int
+	Digest1   string `json:",omitempty"`
+	Digest2   string `json:",omitempty"`
+	Installed PackageDiff
+	Removed   PackageDiff
+	Updated   PackageDiff
+}
+
+// PackageLayerChangesDiff stores the layers of two images whose package changes differ.
+type PackageLayerChangesDiff struct {
+	LayerDiffs []PackageLayerChangeDiff
+}
+
+// MultiVersionPackageLayerDiff stores the difference information between two images
+// layer by layer in MultiVersionPackageDiff array
+type MultiVersionPackageLayerDiff struct {
+	PackageDiffs []MultiVersionPackageDiff
+}
+
+// MultiVersionPackageLayerChangeDiff is the multi-version counterpart of PackageLayerChangeDiff:
+// packages are keyed by name and installation path.
+type MultiVersionPackageLayerChangeDiff struct {
+	Layer     int
+	Digest1   string `json:",omitempty"`
+	Digest2   string `json:",omitempty"`
+	Installed MultiVersionPackageDiff
+	Removed   MultiVersionPackageDiff
+	Updated   MultiVersionPackageDiff
+}
+
+// MultiVersionPackageLayerChangesDiff stores the layers of two images whose multi-version package changes differ.
+type MultiVersionPackageLayerChangesDiff struct {
+	LayerDiffs []MultiVersionPackageLayerChangeDiff
+}
+
 // Info stores the information for one package in two different images.
 type Info struct {
 	Package string
 	Info1   PackageInfo
 	Info2   PackageInfo
+	Change  VersionChange `json:",omitempty"`
+	// Changelog are the changelog entries of the versions between Info1 and
+	// Info2 of an upgraded package, newest first.
+	Changelog []ChangelogEntry `json:",omitempty"`
 }
 
 // PackageInfo stores the specific metadata about a package.
 type PackageInfo struct {
 	Version string
 	Size    int64
+	// Epoch is the epoch of the version, for package managers recording it
+	// apart from the version, e.g. rpm.
+	Epoch string `json:",omitempty"`
+	// Project is the directory of the project owning the package, for package
+	// managers installing packages per project.
+	Project string `json:",omitempty"`
+	// Environment is the interpreter or virtual environment the package is
+	// installed for, for language package managers.
+	Environment string `json:",omitempty"`
+	// Arch is the architecture or platform the package was built for.
+	Arch string `json:",omitempty"`
+	// Build identifies the build of the package version, e.g. a conda build string.
+	Build string `json:",omitempty"`
+	// Repository is the channel or repository the package was installed from.
+	Repository string `json:",omitempty"`
+	// Replacement is the package the declared one was replaced with, e.g. by a Go
+	// module replace directive.
+	Replacement string `json:",omitempty"`
+	// Reason is why the package was installed, e.g. explicitly or as a
+	// dependency, for package managers recording it.
+	Reason string `json:",omitempty"`
+	// Source is the source package the package was built from.
+	Source string `json:",omitempty"`
+	// License is the license declared by the package metadata.
+	License    string `json:",omitempty"`
+	Maintainer string `json:",omitempty"`
+	Homepage   string `json:",omitempty"`
+	// Dependencies lists the packages the package depends on, as declared
+	// in its metadata.
+	Dependencies []string `json:",omitempty"`
+	// PURL is the package URL identifying the package across ecosystems.
+	PURL string `json:",omitempty"`
+}
+
+// packageInfoDiffers reports whether two instances of a package differ in version,
+// epoch or build, in the repository they were installed from or the reason they
+// were installed for, or in the metadata declared by the package (architecture,
+// source package, license, maintainer, homepage or dependencies). Sizes are not
+// compared.
+func packageInfoDiffers(info1, info2 PackageInfo) bool {
+	return info1.Version != info2.Version ||
+		info1.Epoch != info2.Epoch ||
+		info1.Build != info2.Build ||
+		info1.Repository != info2.Repository ||
+		info1.Reason != info2.Reason ||
+		info1.Arch != info2.Arch ||
+		info1.Source != info2.Source ||
+		info1.License != info2.License ||
+		info1.Maintainer != info2.Maintainer ||
+		info1.Homepage != info2.Homepage ||
+		!reflect.DeepEqual(info1.Dependencies, info2.Dependencies)
 }
 
 func multiVersionDiff(infoDiff []MultiVersionInfo, packageName string, map1, map2 map[string]PackageInfo) []MultiVersionInfo {
@@ -73,24 +168,22 @@ func multiVersionDiff(infoDiff []MultiVersionInfo, packageName string, map1, map
 		if !ok {
 			diff1 = append(diff1, packInfo1)
 			continue
-		} else {
-			// If a package instance is installed in the same place in Image1 and Image2 with the same version,
-			// then they are the same package and should not be included in the diff
-			if packInfo1.Version == packInfo2.Version {
-				delete(map2, path)
-			} else {
-				diff1 = append(diff1, packInfo1)
-				diff2 = append(diff2, packInfo2)
-				delete(map2, path)
-			}
+		}
+		// If a package instance is installed in the same place in Image1 and Image2 with the same version
+		// and metadata, then they are the same package and should not be included in the diff
+		if packageInfoDiffers(packInfo1, packInfo2) {
+			diff1 = append(diff1, packInfo1)
+			diff2 = append(diff2, packInfo2)
 		}
 	}
-	for _, packInfo2 := range map2 {
-		diff2 = append(diff2, packInfo2)
+	for path, packInfo2 := range map2 {
+		if _, ok := map1[path]; !ok {
+			diff2 = append(diff2, packInfo2)
+		}
 	}
 
 	if len(diff1) > 0 || len(diff2) > 0 {
-		infoDiff = append(infoDiff, MultiVersionInfo{packageName, diff1, diff2})
+		infoDiff = append(infoDiff, MultiVersionInfo{Package: packageName, Info1: diff1, Info2: diff2})
 	}
 	return infoDiff
 }
@@ -155,7 +248,7 @@ func diffMaps(map1, map2 interface{}) interface{} {
 		if !packageEntry2.IsValid() {
 			diff1.SetMapIndex(pack, packageEntry1)
 			// If the package exists in Image2's map of packages but the package information differs between images, add it to
-			// the difference.  Otherwise, if the information is consistent across images, delete it from the Image2's map of packages.
+			// the difference.
 		} else {
 			if multiV {
 				if !reflect.DeepEqual(packageEntry2.Interface(), packageEntry1.Interface()) {
@@ -166,19 +259,19 @@ func diffMaps(map1, map2 interface{}) interface{} {
 			} else {
 				packageInfo1 := packageEntry1.Interface().(PackageInfo)
 				packageInfo2 := packageEntry2.Interface().(PackageInfo)
-				// If two instances of the same package don't have the same version, then they are considered to be different
-				if packageInfo1.Version != packageInfo2.Version {
-					infoDiff = append(infoDiff, Info{pack.String(), packageInfo1, packageInfo2})
+				// If two instances of the same package don't have the same version or metadata, then they are considered to be different
+				if packageInfoDiffers(packageInfo1, packageInfo2) {
+					infoDiff = append(infoDiff, Info{Package: pack.String(), Info1: packageInfo1, Info2: packageInfo2})
 				}
 			}
-			map2Value.SetMapIndex(pack, reflect.Value{})
 		}
 	}
 
-	// The packages remaining in Image2's map of images are those that exist uniquely in Image2
+	// The packages of Image2 which are not in Image1's map of packages are those that exist uniquely in Image2
 	for _, key2 := range map2Value.MapKeys() {
-		packageEntry2 := map2Value.MapIndex(key2)
-		diff2.SetMapIndex(key2, packageEntry2)
+		if !map1Value.MapIndex(key2).IsValid() {
+			diff2.SetMapIndex(key2, map2Value.MapIndex(key2))
+		}
 	}
 
 	if multiV {
@@ -189,6 +282,92 @@ func diffMaps(map1, map2 interface{}) interface{} {
 		Packages2: diff2.Interface().(map[string]PackageInfo), InfoDiff: infoDiff}
 }
 
+// DiffLayerPackageChanges compares the package changes of two layers, as computed
+// against the package database of their preceding layers, and reports whether they differ.
+func DiffLayerPackageChanges(changes1, changes2 PackageDiff) (PackageLayerChangeDiff, bool) {
+	diff := PackageLayerChangeDiff{
+		Installed: GetMapDiff(nonNilPackageMap(changes1.Packages2), nonNilPackageMap(changes2.Packages2)),
+		Removed:   GetMapDiff(nonNilPackageMap(changes1.Packages1), nonNilPackageMap(changes2.Packages1)),
+		Updated:   GetMapDiff(updatedPackages(changes1), updatedPackages(changes2)),
+	}
+	same := packageDiffIsEmpty(diff.Installed) && packageDiffIsEmpty(diff.Removed) && packageDiffIsEmpty(diff.Updated)
+	return diff, !same
+}
+
+// updatedPackages returns the packages whose version a layer changed, with their new PackageInfo.
+func updatedPackages(changes PackageDiff) map[string]PackageInfo {
+	updated := make(map[string]PackageInfo)
+	for _, info := range changes.InfoDiff {
+		updated[info.Package] = info.Info2
+	}
+	return updated
+}
+
+func nonNilPackageMap(packages map[string]PackageInfo) map[string]PackageInfo {
+	if packages == nil {
+		return make(map[string]PackageInfo)
+	}
+	return packages
+}
+
+func packageDiffIsEmpty(diff PackageDiff) bool {
+	return len(diff.Packages1) == 0 && len(diff.Packages2) == 0 && len(diff.InfoDiff) == 0
+}
+
+// DiffMultiVersionLayerPackageChanges compares the package installations a layer of each
+// image added, removed or updated on top of the packages installed before it (prev1 and
+// prev2), and reports whether they differ.
+func DiffMultiVersionLayerPackageChanges(prev1, cur1, prev2, cur2 map[string]map[string]PackageInfo) (MultiVersionPackageLayerChangeDiff, bool) {
+	installed1, removed1, updated1 := multiVersionLayerChanges(prev1, cur1)
+	installed2, removed2, updated2 := multiVersionLayerChanges(prev2, cur2)
+	diff := MultiVersionPackageLayerChangeDiff{
+		Installed: GetMultiVersionMapDiff(installed1, installed2),
+		Removed:   GetMultiVersionMapDiff(removed1, removed2),
+		Updated:   GetMultiVersionMapDiff(updated1, updated2),
+	}
+	same := multiVersionPackageDiffIsEmpty(diff.Installed) && multiVersionPackageDiffIsEmpty(diff.Removed) &&
+		multiVersionPackageDiffIsEmpty(diff.Updated)
+	return diff, !same
+}
+
+// multiVersionLayerChanges splits the difference between two package states into the
+// installations only found in cur, those only found in prev, and those found in both
+// with a different version or metadata, with their PackageInfo from cur.
+func multiVersionLayerChanges(prev, cur map[string]map[string]PackageInfo) (installed, removed, updated map[string]map[string]PackageInfo) {
+	installed = make(map[string]map[string]PackageInfo)
+	removed = make(map[string]map[string]PackageInfo)
+	updated = make(map[string]map[string]PackageInfo)
+	for name, paths := range cur {
+		for path, info := range paths {
+			prevInfo, ok := prev[name][path]
+			if !ok {
+				addPackageInstallation(installed, name, path, info)
+			} else if packageInfoDiffers(prevInfo, info) {
+				addPackageInstallation(updated, name, path, info)
+			}
+		}
+	}
+	for name, paths := range prev {
+		for path, info := range paths {
+			if _, ok := cur[name][path]; !ok {
+				addPackageInstallation(removed, name, path, info)
+			}
+		}
+	}
+	return installed, removed, updated
+}
+
+func addPackageInstallation(packages map[string]map[string]PackageInfo, name, path string, info PackageInfo) {
+	if _, ok := packages[name]; !ok {
+		packages[name] = make(map[string]PackageInfo)
+	}
+	packages[name][path] = info
+}
+
+func multiVersionPackageDiffIsEmpty(diff MultiVersionPackageDiff) bool {
+	return len(diff.Packages1) == 0 && len(diff.Packages2) == 0 && len(diff.InfoDiff) == 0
+}
+
 func (pi PackageInfo) string() string {
 	return pi.Version
 }
@@ -50,45 +50,78 @@ func TestDiffMaps(t *testing.T) {
 		{
 			descrip: "Missing Packages.",
 			map1: map[string]PackageInfo{
-				"pac1": {"1.0", 40},
-				"pac3": {"3.0", 60}},
+				"pac1": {Version: "1.0", Size: 40},
+				"pac3": {Version: "3.0", Size: 60}},
 			map2: map[string]PackageInfo{
-				"pac4": {"4.0", 70},
-				"pac5": {"5.0", 80}},
+				"pac4": {Version: "4.0", Size: 70},
+				"pac5": {Version: "5.0", Size: 80}},
 			expected: PackageDiff{
 				Packages1: map[string]PackageInfo{
-					"pac1": {"1.0", 40},
-					"pac3": {"3.0", 60}},
+					"pac1": {Version: "1.0", Size: 40},
+					"pac3": {Version: "3.0", Size: 60}},
 				Packages2: map[string]PackageInfo{
-					"pac4": {"4.0", 70},
-					"pac5": {"5.0", 80}},
+					"pac4": {Version: "4.0", Size: 70},
+					"pac5": {Version: "5.0", Size: 80}},
 				InfoDiff: []Info{}},
 		},
 		{
 			descrip: "Different Versions and Sizes.",
 			map1: map[string]PackageInfo{
-				"pac2": {"2.0", 50},
-				"pac3": {"3.0", 60}},
+				"pac2": {Version: "2.0", Size: 50},
+				"pac3": {Version: "3.0", Size: 60}},
 			map2: map[string]PackageInfo{
-				"pac2": {"2.0", 45},
-				"pac3": {"4.0", 60}},
+				"pac2": {Version: "2.0", Size: 45},
+				"pac3": {Version: "4.0", Size: 60}},
 			expected: PackageDiff{
 				Packages1: map[string]PackageInfo{},
 				Packages2: map[string]PackageInfo{},
 				InfoDiff: []Info{
-					{"pac3", PackageInfo{"3.0", 60}, PackageInfo{"4.0", 60}}},
+					{Package: "pac3", Info1: PackageInfo{Version: "3.0", Size: 60}, Info2: PackageInfo{Version: "4.0", Size: 60}}},
+			},
+		},
+		{
+			descrip: "Different Metadata.",
+			map1: map[string]PackageInfo{
+				"pac1": {Version: "1.0", Size: 40, License: "GPL-2.0"},
+				"pac2": {Version: "2.0", Size: 50, Arch: "amd64"},
+				"pac3": {Version: "3.0", Size: 60, Dependencies: []string{"pac1"}}},
+			map2: map[string]PackageInfo{
+				"pac1": {Version: "1.0", Size: 40, License: "MIT"},
+				"pac2": {Version: "2.0", Size: 50, Arch: "arm64"},
+				"pac3": {Version: "3.0", Size: 65, Dependencies: []string{"pac1"}}},
+			expected: PackageDiff{
+				Packages1: map[string]PackageInfo{},
+				Packages2: map[string]PackageInfo{},
+				InfoDiff: []Info{
+					{Package: "pac1", Info1: PackageInfo{Version: "1.0", Size: 40, License: "GPL-2.0"}, Info2: PackageInfo{Version: "1.0", Size: 40, License: "MIT"}},
+					{Package: "pac2", Info1: PackageInfo{Version: "2.0", Size: 50, Arch: "amd64"}, Info2: PackageInfo{Version: "2.0", Size: 50, Arch: "arm64"}}},
+			},
+		},
+		{
+			descrip: "Different install reasons.",
+			map1: map[string]PackageInfo{
+				"curl": {Version: "8.4.0-2", Size: 40, Reason: "explicit"},
+				"zlib": {Version: "1:1.3-2", Size: 50, Reason: "dependency"}},
+			map2: map[string]PackageInfo{
+				"curl": {Version: "8.4.0-2", Size: 40, Reason: "dependency"},
+				"zlib": {Version: "1:1.3-2", Size: 50, Reason: "dependency"}},
+			expected: PackageDiff{
+				Packages1: map[string]PackageInfo{},
+				Packages2: map[string]PackageInfo{},
+				InfoDiff: []Info{
+					{Package: "curl", Info1: PackageInfo{Version: "8.4.0-2", Size: 40, Reason: "explicit"}, Info2: PackageInfo{Version: "8.4.0-2", Size: 40, Reason: "dependency"}}},
 			},
 		},
 		{
 			descrip: "Identical packages, versions, and sizes",
 			map1: map[string]PackageInfo{
-				"pac1": {"1.0", 40},
-				"pac2": {"2.0", 50},
-				"pac3": {"3.0", 60}},
+				"pac1": {Version: "1.0", Size: 40},
+				"pac2": {Version: "2.0", Size: 50},
+				"pac3": {Version: "3.0", Size: 60}},
 			map2: map[string]PackageInfo{
-				"pac1": {"1.0", 40},
-				"pac2": {"2.0", 50},
-				"pac3": {"3.0", 60}},
+				"pac1": {Version: "1.0", Size: 40},
+				"pac2": {Version: "2.0", Size: 50},
+				"pac3": {Version: "3.0", Size: 60}},
 			expected: PackageDiff{
 				Packages1: map[string]PackageInfo{},
 				Packages2: map[string]PackageInfo{},
@@ -97,49 +130,74 @@ func TestDiffMaps(t *testing.T) {
 		{
 			descrip: "MultiVersion call with identical Packages in different layers",
 			map1: map[string]map[string]PackageInfo{
-				"pac5": {"globalPath": {"version", 0}},
-				"pac3": {"notquite/localPath": {"version", 0}},
-				"pac4": {"globalPath": {"version", 0}}},
+				"pac5": {"globalPath": {Version: "version", Size: 0}},
+				"pac3": {"notquite/localPath": {Version: "version", Size: 0}},
+				"pac4": {"globalPath": {Version: "version", Size: 0}}},
 			map2: map[string]map[string]PackageInfo{
-				"pac5": {"globalPath": {"version", 0}},
-				"pac3": {"notquite/localPath": {"version", 0}},
-				"pac4": {"globalPath": {"version", 0}}},
+				"pac5": {"globalPath": {Version: "version", Size: 0}},
+				"pac3": {"notquite/localPath": {Version: "version", Size: 0}},
+				"pac4": {"globalPath": {Version: "version", Size: 0}}},
 			expected: MultiVersionPackageDiff{
 				Packages1: map[string]map[string]PackageInfo{},
 				Packages2: map[string]map[string]PackageInfo{},
 				InfoDiff:  []MultiVersionInfo{},
 			},
 		},
+		{
+			descrip: "MultiVersion Packages with different builds and channels",
+			map1: map[string]map[string]PackageInfo{
+				"numpy": {"/opt/conda": {Version: "1.26.4", Build: "py311h24aa872_0", Repository: "defaults", Size: 100}},
+				"scipy": {"/opt/conda/envs/ml": {Version: "1.11.4", Build: "py311h08b1b3b_0", Repository: "defaults", Size: 200}}},
+			map2: map[string]map[string]PackageInfo{
+				"numpy": {"/opt/conda": {Version: "1.26.4", Build: "py311h64a7726_0", Repository: "defaults", Size: 100}},
+				"scipy": {"/opt/conda/envs/ml": {Version: "1.11.4", Build: "py311h08b1b3b_0", Repository: "conda-forge", Size: 200}}},
+			expected: MultiVersionPackageDiff{
+				Packages1: map[string]map[string]PackageInfo{},
+				Packages2: map[string]map[string]PackageInfo{},
+				InfoDiff: []MultiVersionInfo{
+					{
+						Package: "numpy",
+						Info1:   []PackageInfo{{Version: "1.26.4", Build: "py311h24aa872_0", Repository: "defaults", Size: 100}},
+						Info2:   []PackageInfo{{Version: "1.26.4", Build: "py311h64a7726_0", Repository: "defaults", Size: 100}},
+					},
+					{
+						Package: "scipy",
+						Info1:   []PackageInfo{{Version: "1.11.4", Build: "py311h08b1b3b_0", Repository: "defaults", Size: 200}},
+						Info2:   []PackageInfo{{Version: "1.11.4", Build: "py311h08b1b3b_0", Repository: "conda-forge", Size: 200}},
+					},
+				},
+			},
+		},
 		{
 			descrip: "MultiVersion Packages",
 			map1: map[string]map[string]PackageInfo{
-				"pac5": {"onlyImg1": {"version", 0}},
-				"pac4": {"samePlace": {"version", 0}},
-				"pac1": {"node_modules/pac1": {"1.0", 40}},
-				"pac2": {"usr/local/lib/node_modules/pac2": {"2.0", 50},
-					"node_modules/pac2": {"3.0", 50}}},
+				"pac5": {"onlyImg1": {Version: "version", Size: 0}},
+				"pac4": {"samePlace": {Version: "version", Size: 0}},
+				"pac1": {"node_modules/pac1": {Version: "1.0", Size: 40}},
+				"pac2": {"usr/local/lib/node_modules/pac2": {Version: "2.0", Size: 50},
+					"node_modules/pac2": {Version: "3.0", Size: 50}}},
 			map2: map[string]map[string]PackageInfo{
-				"pac4": {"samePlace": {"version", 0}},
-				"pac1": {"node_modules/pac1": {"2.0", 40}},
-				"pac2": {"usr/local/lib/node_modules/pac2": {"4.0", 50}},
-				"pac3": {"usr/local/lib/node_modules/pac3": {"5.0", 100}}},
+				"pac4": {"samePlace": {Version: "version", Size: 0}},
+				"pac1": {"node_modules/pac1": {Version: "2.0", Size: 40}},
+				"pac2": {"usr/local/lib/node_modules/pac2": {Version: "4.0", Size: 50}},
+				"pac3": {"usr/local/lib/node_modules/pac3": {Version: "5.0", Size: 100}}},
 			expected: MultiVersionPackageDiff{
 				Packages1: map[string]map[string]PackageInfo{
-					"pac5": {"onlyImg1": {"version", 0}},
+					"pac5": {"onlyImg1": {Version: "version", Size: 0}},
 				},
 				Packages2: map[string]map[string]PackageInfo{
-					"pac3": {"usr/local/lib/node_modules/pac3": {"5.0", 100}},
+					"pac3": {"usr/local/lib/node_modules/pac3": {Version: "5.0", Size: 100}},
 				},
 				InfoDiff: []MultiVersionInfo{
 					{
 						Package: "pac1",
-						Info1:   []PackageInfo{{"1.0", 40}},
-						Info2:   []PackageInfo{{"2.0", 40}},
+						Info1:   []PackageInfo{{Version: "1.0", Size: 40}},
+						Info2:   []PackageInfo{{Version: "2.0", Size: 40}},
 					},
 					{
 						Package: "pac2",
-						Info1:   []PackageInfo{{"2.0", 50}, {"3.0", 50}},
-						Info2:   []PackageInfo{{"4.0", 50}},
+						Info1:   []PackageInfo{{Version: "2.0", Size: 50}, {Version: "3.0", Size: 50}},
+						Info2:   []PackageInfo{{Version: "4.0", Size: 50}},
 					},
 				},
 			},
@@ -0,0 +1,240 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// os-release locations, the latter being the fallback
+var osReleaseFiles = []string{"etc/os-release", "usr/lib/os-release"}
+
+// Distro identifies the Linux distribution of an image, from its os-release file.
+type Distro struct {
+	ID        string
+	VersionID string
+}
+
+// GetDistro reads the distribution of the image filesystem rooted at root.
+func GetDistro(root string) Distro {
+	var distro Distro
+	for _, osRelease := range osReleaseFiles {
+		file, err := os.Open(filepath.Join(root, osRelease))
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.SplitN(scanner.Text(), "=", 2)
+			if len(fields) != 2 {
+				continue
+			}
+			value := strings.Trim(fields[1], `"'`)
+			switch fields[0] {
+			case "ID":
+				distro.ID = value
+			case "VERSION_ID":
+				distro.VersionID = value
+			}
+		}
+		break
+	}
+	return distro
+}
+
+// String returns the distro qualifier of package URLs, e.g. debian-12.
+func (d Distro) String() string {
+	if d.VersionID == "" {
+		return d.ID
+	}
+	return d.ID + "-" + d.VersionID
+}
+
+// purlTypes maps analyze types to package URL types, with their default namespace
+var purlTypes = map[string]struct{ purlType, namespace string }{
+	"Apt":      {"deb", "debian"},
+	"RPM":      {"rpm", ""},
+	"Pacman":   {"alpm", "arch"},
+	"Emerge":   {"generic", ""},
+	"Pip":      {"pypi", ""},
+	"Node":     {"npm", ""},
+	"Conda":    {"conda", ""},
+	"Gem":      {"gem", ""},
+	"Java":     {"maven", ""},
+	"GoBinary": {"golang", ""},
+	"Composer": {"composer", ""},
+	"Dotnet":   {"nuget", ""},
+	"R":        {"cran", ""},
+}
+
+// PackageURL returns the package URL (purl) of a package found by the package
+// analyzer of the given analyze type, e.g. Apt, or an empty string for analyzers
+// without a package URL type. OS packages are namespaced by the image distro,
+// with their architecture and epoch as qualifiers.
+func PackageURL(analyzeType, name string, info PackageInfo, distro Distro) string {
+	purl, ok := purlTypes[analyzeType]
+	if !ok || name == "" {
+		return ""
+	}
+	namespace := purl.namespace
+	version := info.Version
+	qualifiers := make(map[string]string)
+	switch analyzeType {
+	case "Apt", "RPM", "Pacman":
+		if distro.ID != "" {
+			namespace = distro.ID
+			qualifiers["distro"] = distro.String()
+		}
+		qualifiers["arch"] = info.Arch
+		qualifiers["epoch"] = info.Epoch
+		if analyzeType == "Apt" {
+			// restore the '+' replaced when parsing the dpkg status file
+			version = strings.Replace(version, " ", "+", 1)
+			// dpkg versions carry their epoch, qualifiers hold it as for rpm
+			if i := strings.Index(version, ":"); i > 0 {
+				qualifiers["epoch"], version = version[:i], version[i+1:]
+			}
+		}
+	case "Emerge", "Composer", "GoBinary":
+		// category/name, vendor/name and module paths
+		if i := strings.LastIndex(name, "/"); i > 0 {
+			namespace, name = name[:i], name[i+1:]
+		}
+		if analyzeType == "GoBinary" && name == "stdlib" {
+			namespace = ""
+		}
+	case "Node":
+		// scoped packages, e.g. @babel/core
+		if i := strings.Index(name, "/"); strings.HasPrefix(name, "@") && i > 0 {
+			namespace, name = name[:i], name[i+1:]
+		}
+	case "Java":
+		// groupId:artifactId, or the jdk
+		if parts := strings.SplitN(name, ":", 2); len(parts) == 2 {
+			namespace, name = parts[0], parts[1]
+		} else {
+			return packageURL("generic", "", name, version, nil)
+		}
+	case "Pip":
+		name = strings.ToLower(strings.Replace(name, "_", "-", -1))
+	case "Conda", "Gem":
+		qualifiers["platform"] = info.Arch
+	}
+	return packageURL(purl.purlType, namespace, name, version, qualifiers)
+}
+
+// packageURL formats a package URL from its percent-encoded components, with
+// its non empty qualifiers sorted by key.
+func packageURL(purlType, namespace, name, version string, qualifiers map[string]string) string {
+	purl := "pkg:" + purlType + "/"
+	if namespace != "" {
+		for _, segment := range strings.Split(namespace, "/") {
+			purl += purlEscape(segment) + "/"
+		}
+	}
+	purl += purlEscape(name)
+	if version != "" {
+		purl += "@" + purlEscape(version)
+	}
+	var keys []string
+	for key, value := range qualifiers {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		separator := "&"
+		if i == 0 {
+			separator = "?"
+		}
+		purl += separator + key + "=" + purlEscape(qualifiers[key])
+	}
+	return purl
+}
+
+// purlEscape percent-encodes all but the unreserved characters and colons.
+func purlEscape(s string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte(".-_~:", c) >= 0 {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
+
+// PURLPackage is a package identified by its package URL, merging the packages
+// found by every analyzer with that package URL, wherever they are installed.
+type PURLPackage struct {
+	PURL string
+	// Analyzers are the analyzers which found the package, e.g. apt or pip.
+	Analyzers []string
+	// Paths are the installation paths of packages installed in several places.
+	Paths []string `json:",omitempty"`
+}
+
+// GroupByPackageURL merges the packages of the package analysis results into a
+// single list of packages sorted by package URL. Other results are ignored.
+func GroupByPackageURL(results map[string]Result, distro Distro) []PURLPackage {
+	analyzers := make(map[string]map[string]bool)
+	paths := make(map[string]map[string]bool)
+	for _, pkg := range GetSBOMPackages(results, distro) {
+		if pkg.PURL == "" {
+			continue
+		}
+		if analyzers[pkg.PURL] == nil {
+			analyzers[pkg.PURL] = make(map[string]bool)
+			paths[pkg.PURL] = make(map[string]bool)
+		}
+		analyzers[pkg.PURL][strings.ToLower(pkg.Type)] = true
+		if pkg.Path != "" {
+			paths[pkg.PURL][pkg.Path] = true
+		}
+	}
+
+	packages := []PURLPackage{}
+	for purl := range analyzers {
+		packages = append(packages, PURLPackage{
+			PURL:      purl,
+			Analyzers: sortedKeys(analyzers[purl]),
+			Paths:     sortedKeys(paths[purl]),
+		})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].PURL < packages[j].PURL
+	})
+	return packages
+}
+
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPackageURL(t *testing.T) {
+	debian := Distro{ID: "debian", VersionID: "12"}
+	testCases := []struct {
+		analyzeType string
+		name        string
+		info        PackageInfo
+		distro      Distro
+		expected    string
+	}{
+		{"Apt", "libssl3", PackageInfo{Version: "3.0.11-1~deb12u2", Arch: "amd64"}, debian, "pkg:deb/debian/libssl3@3.0.11-1~deb12u2?arch=amd64&distro=debian-12"},
+		{"Apt", "bash", PackageInfo{Version: "5.2.15-2 b2"}, Distro{}, "pkg:deb/debian/bash@5.2.15-2%2Bb2"},
+		{"Apt", "zlib1g", PackageInfo{Version: "1:1.2.13.dfsg-1", Arch: "amd64"}, debian, "pkg:deb/debian/zlib1g@1.2.13.dfsg-1?arch=amd64&distro=debian-12&epoch=1"},
+		{"RPM", "openssl-libs", PackageInfo{Version: "3.0.7-24.el9", Epoch: "1", Arch: "x86_64"}, Distro{ID: "rocky", VersionID: "9.3"}, "pkg:rpm/rocky/openssl-libs@3.0.7-24.el9?arch=x86_64&distro=rocky-9.3&epoch=1"},
+		{"RPM", "bash", PackageInfo{Version: "5.2.15-3.fc39", Arch: "x86_64"}, Distro{ID: "fedora", VersionID: "39"}, "pkg:rpm/fedora/bash@5.2.15-3.fc39?arch=x86_64&distro=fedora-39"},
+		{"Pacman", "curl", PackageInfo{Version: "8.4.0-2", Arch: "x86_64"}, Distro{ID: "arch"}, "pkg:alpm/arch/curl@8.4.0-2?arch=x86_64&distro=arch"},
+		{"Emerge", "sys-libs/glibc", PackageInfo{Version: "2.37"}, Distro{}, "pkg:generic/sys-libs/glibc@2.37"},
+		{"Pip", "Flask_Login", PackageInfo{Version: "0.6.3"}, debian, "pkg:pypi/flask-login@0.6.3"},
+		{"Node", "@babel/core", PackageInfo{Version: "7.23.2"}, debian, "pkg:npm/%40babel/core@7.23.2"},
+		{"Gem", "nokogiri", PackageInfo{Version: "1.15.4", Arch: "x86_64-linux"}, debian, "pkg:gem/nokogiri@1.15.4?platform=x86_64-linux"},
+		{"Java", "org.springframework:spring-core", PackageInfo{Version: "5.3.23"}, debian, "pkg:maven/org.springframework/spring-core@5.3.23"},
+		{"Java", "jdk", PackageInfo{Version: "17.0.8"}, debian, "pkg:generic/jdk@17.0.8"},
+		{"GoBinary", "github.com/spf13/cobra", PackageInfo{Version: "v1.8.0"}, debian, "pkg:golang/github.com/spf13/cobra@v1.8.0"},
+		{"GoBinary", "stdlib", PackageInfo{Version: "go1.21.3"}, debian, "pkg:golang/stdlib@go1.21.3"},
+		{"Composer", "monolog/monolog", PackageInfo{Version: "3.5.0"}, debian, "pkg:composer/monolog/monolog@3.5.0"},
+		{"R", "ggplot2", PackageInfo{Version: "3.4.4"}, debian, "pkg:cran/ggplot2@3.4.4"},
+		{"Size", "image", PackageInfo{}, debian, ""},
+	}
+	for _, test := range testCases {
+		if purl := PackageURL(test.analyzeType, test.name, test.info, test.distro); purl != test.expected {
+			t.Errorf("PackageURL(%s, %s): expected %s but got %s", test.analyzeType, test.name, test.expected, purl)
+		}
+	}
+}
+
+func TestGetDistro(t *testing.T) {
+	root, err := ioutil.TempDir("", "distro")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	if distro := GetDistro(root); distro != (Distro{}) {
+		t.Errorf("Expected no distro but got %v", distro)
+	}
+	os.MkdirAll(filepath.Join(root, "usr/lib"), 0755)
+	osRelease := "PRETTY_NAME=\"Debian GNU/Linux 12 (bookworm)\"\nNAME=\"Debian GNU/Linux\"\nVERSION_ID=\"12\"\nID=debian\n"
+	if err := ioutil.WriteFile(filepath.Join(root, "usr/lib/os-release"), []byte(osRelease), 0644); err != nil {
+		t.Fatalf("Unable to write os-release: %s", err)
+	}
+	expected := Distro{ID: "debian", VersionID: "12"}
+	if distro := GetDistro(root); distro != expected {
+		t.Errorf("Expected %v but got %v", expected, distro)
+	}
+}
+
+func TestGroupByPackageURL(t *testing.T) {
+	results := map[string]Result{
+		"AptAnalyzer": &SingleVersionPackageAnalyzeResult{
+			AnalyzeType: "Apt",
+			Analysis: map[string]PackageInfo{
+				"bash": {Version: "5.2.15-2 b2", Arch: "amd64", PURL: "pkg:deb/debian/bash@5.2.15-2%2Bb2?arch=amd64&distro=debian-12"},
+			},
+		},
+		"PipAnalyzer": &MultiVersionPackageAnalyzeResult{
+			AnalyzeType: "Pip",
+			Analysis: map[string]map[string]PackageInfo{
+				"six": {
+					"/usr/lib/python3/dist-packages":          {Version: "1.16.0"},
+					"/venv/lib/python3.11/site-packages":      {Version: "1.16.0"},
+					"/usr/local/lib/python3.11/site-packages": {Version: "1.15.0"},
+				},
+			},
+		},
+		"SizeAnalyzer": &SizeAnalyzeResult{AnalyzeType: "Size"},
+	}
+	expected := []PURLPackage{
+		{PURL: "pkg:deb/debian/bash@5.2.15-2%2Bb2?arch=amd64&distro=debian-12", Analyzers: []string{"apt"}},
+		{PURL: "pkg:pypi/six@1.15.0", Analyzers: []string{"pip"}, Paths: []string{"/usr/local/lib/python3.11/site-packages"}},
+		{PURL: "pkg:pypi/six@1.16.0", Analyzers: []string{"pip"}, Paths: []string{"/usr/lib/python3/dist-packages", "/venv/lib/python3.11/site-packages"}},
+	}
+	if packages := GroupByPackageURL(results, Distro{ID: "debian", VersionID: "12"}); !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+}
@@ -0,0 +1,557 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SBOM output formats of analyze
+const (
+	SPDXJSON      = "spdx-json"
+	CycloneDXJSON = "cyclonedx-json"
+)
+
+var SBOMFormats = []string{SPDXJSON, CycloneDXJSON}
+
+// spdxNamespaceBase prefixes the unique namespace of the SPDX documents
+const spdxNamespaceBase = "https://github.com/GoogleContainerTools/container-diff/spdx"
+
+// noAssertion is the SPDX value of unknown fields
+const noAssertion = "NOASSERTION"
+
+// SBOMSource describes the image an SBOM is generated for.
+type SBOMSource struct {
+	// Image is the image as specified on the command line
+	Image string
+	// Digest is the image digest, e.g. sha256:abc...
+	Digest string
+	Distro Distro
+	// Tool is the version of container-diff generating the SBOM
+	Tool    string
+	Created time.Time
+}
+
+// SBOMPackage is a package found in an image by a package analyzer.
+type SBOMPackage struct {
+	Name string
+	// Path is the installation path of packages installed in several places
+	Path string
+	// Type is the analyze type of the analyzer which found the package, e.g. Apt
+	Type string
+	Info PackageInfo
+	PURL string
+}
+
+// GetSBOMPackages collects the packages of the package analysis results, along
+// with their package URL, computed for the distro unless set by the analyzer.
+// Other results are ignored.
+func GetSBOMPackages(results map[string]Result, distro Distro) []SBOMPackage {
+	packages := []SBOMPackage{}
+	add := func(analyzeType, name, path string, info PackageInfo) {
+		purl := info.PURL
+		if purl == "" {
+			purl = PackageURL(analyzeType, name, info, distro)
+		}
+		packages = append(packages, SBOMPackage{
+			Name: name,
+			Path: path,
+			Type: analyzeType,
+			Info: info,
+			PURL: purl,
+		})
+	}
+	for _, result := range results {
+		switch r := result.(type) {
+		case *SingleVersionPackageAnalyzeResult:
+			if analysis, ok := r.Analysis.(map[string]PackageInfo); ok {
+				for name, info := range analysis {
+					add(r.AnalyzeType, name, "", info)
+				}
+			}
+		case *MultiVersionPackageAnalyzeResult:
+			if analysis, ok := r.Analysis.(map[string]map[string]PackageInfo); ok {
+				for name, paths := range analysis {
+					for path, info := range paths {
+						add(r.AnalyzeType, name, path, info)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		p1, p2 := packages[i], packages[j]
+		if p1.Type != p2.Type {
+			return p1.Type < p2.Type
+		}
+		if p1.Name != p2.Name {
+			return p1.Name < p2.Name
+		}
+		return p1.Path < p2.Path
+	})
+	return packages
+}
+
+// WriteSBOM writes the SBOM of the packages in the given format.
+func WriteSBOM(writer io.Writer, format string, source SBOMSource, packages []SBOMPackage) error {
+	switch format {
+	case SPDXJSON:
+		return JSONify(writer, NewSPDXDocument(source, packages))
+	case CycloneDXJSON:
+		return JSONify(writer, NewCycloneDXDocument(source, packages))
+	}
+	return fmt.Errorf("unknown SBOM format %s, expected one of %s", format, strings.Join(SBOMFormats, ", "))
+}
+
+// imageURL returns the package URL of the image, an oci purl, if its digest is known.
+func imageURL(source SBOMSource) string {
+	if source.Digest == "" {
+		return ""
+	}
+	repository := source.Image
+	if i := strings.Index(repository, "://"); i >= 0 {
+		// daemon:// and remote:// prefixes
+		repository = repository[i+3:]
+	}
+	repository = strings.SplitN(repository, "@", 2)[0]
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	name := repository[strings.LastIndex(repository, "/")+1:]
+	return packageURL("oci", "", strings.ToLower(name), source.Digest, map[string]string{"repository_url": repository})
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// SPDXDocument is an SPDX 2.3 document, in its JSON serialization.
+type SPDXDocument struct {
+	SPDXVersion       string                 `json:"spdxVersion"`
+	DataLicense       string                 `json:"dataLicense"`
+	SPDXID            string                 `json:"SPDXID"`
+	Name              string                 `json:"name"`
+	DocumentNamespace string                 `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo       `json:"creationInfo"`
+	Packages          []SPDXPackage          `json:"packages"`
+	Relationships     []SPDXRelationship     `json:"relationships"`
+	ExtractedLicenses []SPDXExtractedLicense `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type SPDXPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	Purpose          string            `json:"primaryPackagePurpose,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Homepage         string            `json:"homepage,omitempty"`
+	SourceInfo       string            `json:"sourceInfo,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Checksums        []SPDXChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs,omitempty"`
+}
+
+type SPDXChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type SPDXExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type SPDXRelationship struct {
+	Element        string `json:"spdxElementId"`
+	Type           string `json:"relationshipType"`
+	RelatedElement string `json:"relatedSpdxElement"`
+}
+
+type SPDXExtractedLicense struct {
+	LicenseID     string `json:"licenseId"`
+	Name          string `json:"name"`
+	ExtractedText string `json:"extractedText"`
+}
+
+// NewSPDXDocument returns the SPDX document of the image packages: the image is
+// described by the document and contains the packages.
+func NewSPDXDocument(source SBOMSource, packages []SBOMPackage) SPDXDocument {
+	doc := SPDXDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              source.Image,
+		DocumentNamespace: fmt.Sprintf("%s/%s-%s", spdxNamespaceBase, purlEscape(source.Image), newUUID()),
+		CreationInfo: SPDXCreationInfo{
+			Created:  source.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: container-diff-" + source.Tool},
+		},
+		Packages:      []SPDXPackage{},
+		Relationships: []SPDXRelationship{},
+	}
+
+	image := SPDXPackage{
+		SPDXID:           "SPDXRef-Image",
+		Name:             source.Image,
+		Purpose:          "CONTAINER",
+		DownloadLocation: noAssertion,
+		LicenseConcluded: noAssertion,
+		LicenseDeclared:  noAssertion,
+		CopyrightText:    noAssertion,
+	}
+	if source.Digest != "" {
+		image.VersionInfo = source.Digest
+		if parts := strings.SplitN(source.Digest, ":", 2); len(parts) == 2 {
+			image.Checksums = []SPDXChecksum{{Algorithm: strings.ToUpper(parts[0]), Value: parts[1]}}
+		}
+		image.ExternalRefs = []SPDXExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: imageURL(source)}}
+	}
+	doc.Packages = append(doc.Packages, image)
+	doc.Relationships = append(doc.Relationships, SPDXRelationship{Element: doc.SPDXID, Type: "DESCRIBES", RelatedElement: image.SPDXID})
+
+	extracted := make(map[string]string)
+	for i, pkg := range packages {
+		spdxPackage := SPDXPackage{
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%s-%d", pkg.Type, i+1),
+			Name:             pkg.Name,
+			VersionInfo:      pkg.Info.Version,
+			DownloadLocation: noAssertion,
+			Homepage:         pkg.Info.Homepage,
+			SourceInfo:       "acquired package info from the " + strings.ToLower(pkg.Type) + " analyzer",
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  noAssertion,
+			CopyrightText:    noAssertion,
+		}
+		if pkg.Type == "Apt" {
+			// restore the '+' replaced when parsing the dpkg status file
+			spdxPackage.VersionInfo = strings.Replace(spdxPackage.VersionInfo, " ", "+", 1)
+		}
+		if pkg.Path != "" {
+			spdxPackage.SourceInfo += ": " + pkg.Path
+		}
+		if license := NormalizeLicense(pkg.Info.License); license != "" {
+			expression, refs := SPDXExpression(license)
+			spdxPackage.LicenseDeclared = expression
+			for id, name := range refs {
+				extracted[id] = name
+			}
+		}
+		if pkg.PURL != "" {
+			spdxPackage.ExternalRefs = []SPDXExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: pkg.PURL}}
+		}
+		doc.Packages = append(doc.Packages, spdxPackage)
+		doc.Relationships = append(doc.Relationships, SPDXRelationship{Element: image.SPDXID, Type: "CONTAINS", RelatedElement: spdxPackage.SPDXID})
+	}
+
+	for id, name := range extracted {
+		doc.ExtractedLicenses = append(doc.ExtractedLicenses, SPDXExtractedLicense{LicenseID: id, Name: name, ExtractedText: name})
+	}
+	sort.Slice(doc.ExtractedLicenses, func(i, j int) bool {
+		return doc.ExtractedLicenses[i].LicenseID < doc.ExtractedLicenses[j].LicenseID
+	})
+	return doc
+}
+
+// CycloneDXDocument is a CycloneDX 1.5 BOM, in its JSON serialization.
+type CycloneDXDocument struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     CycloneDXMetadata    `json:"metadata"`
+	Components   []CycloneDXComponent `json:"components"`
+}
+
+type CycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     CycloneDXTools     `json:"tools"`
+	Component CycloneDXComponent `json:"component"`
+}
+
+type CycloneDXTools struct {
+	Components []CycloneDXComponent `json:"components"`
+}
+
+type CycloneDXComponent struct {
+	Type       string              `json:"type"`
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	Hashes     []CycloneDXHash     `json:"hashes,omitempty"`
+	Licenses   []CycloneDXLicense  `json:"licenses,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	Properties []CycloneDXProperty `json:"properties,omitempty"`
+}
+
+type CycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+// CycloneDXLicense holds either an SPDX license expression or a license name.
+type CycloneDXLicense struct {
+	Expression string                `json:"expression,omitempty"`
+	License    *CycloneDXLicenseName `json:"license,omitempty"`
+}
+
+type CycloneDXLicenseName struct {
+	Name string `json:"name"`
+}
+
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NewCycloneDXDocument returns the CycloneDX BOM of the image packages, with the
+// image as the metadata component.
+func NewCycloneDXDocument(source SBOMSource, packages []SBOMPackage) CycloneDXDocument {
+	doc := CycloneDXDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: source.Created.UTC().Format(time.RFC3339),
+			Tools: CycloneDXTools{
+				Components: []CycloneDXComponent{{Type: "application", Name: "container-diff", Version: source.Tool}},
+			},
+			Component: CycloneDXComponent{
+				Type:    "container",
+				BOMRef:  "image",
+				Name:    source.Image,
+				Version: source.Digest,
+				PURL:    imageURL(source),
+			},
+		},
+		Components: []CycloneDXComponent{},
+	}
+	if parts := strings.SplitN(source.Digest, ":", 2); len(parts) == 2 && parts[0] == "sha256" {
+		doc.Metadata.Component.Hashes = []CycloneDXHash{{Algorithm: "SHA-256", Content: parts[1]}}
+	}
+
+	for i, pkg := range packages {
+		component := CycloneDXComponent{
+			Type:    "library",
+			BOMRef:  fmt.Sprintf("%s-%d", strings.ToLower(pkg.Type), i+1),
+			Name:    pkg.Name,
+			Version: pkg.Info.Version,
+			PURL:    pkg.PURL,
+			Properties: []CycloneDXProperty{
+				{Name: "container-diff:analyzer", Value: strings.ToLower(pkg.Type)},
+			},
+		}
+		if pkg.Type == "Apt" {
+			// restore the '+' replaced when parsing the dpkg status file
+			component.Version = strings.Replace(component.Version, " ", "+", 1)
+		}
+		if pkg.Path != "" {
+			component.Properties = append(component.Properties, CycloneDXProperty{Name: "container-diff:path", Value: pkg.Path})
+		}
+		if license := NormalizeLicense(pkg.Info.License); license != "" {
+			if expression, refs := SPDXExpression(license); len(refs) == 0 {
+				component.Licenses = []CycloneDXLicense{{Expression: expression}}
+			} else {
+				component.Licenses = []CycloneDXLicense{{License: &CycloneDXLicenseName{Name: license}}}
+			}
+		}
+		doc.Components = append(doc.Components, component)
+	}
+	return doc
+}
+
+// sbomDocument holds the fields of SPDX and CycloneDX JSON documents needed to
+// read their packages back, leaving out those whose shape differs between
+// versions of the specifications.
+type sbomDocument struct {
+	SPDXVersion string `json:"spdxVersion"`
+	Packages    []struct {
+		SPDXID       string `json:"SPDXID"`
+		Name         string `json:"name"`
+		VersionInfo  string `json:"versionInfo"`
+		Purpose      string `json:"primaryPackagePurpose"`
+		SourceInfo   string `json:"sourceInfo"`
+		ExternalRefs []struct {
+			Type    string `json:"referenceType"`
+			Locator string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+	BOMFormat  string          `json:"bomFormat"`
+	Components []sbomComponent `json:"components"`
+}
+
+type sbomComponent struct {
+	Name       string              `json:"name"`
+	Version    string              `json:"version"`
+	PURL       string              `json:"purl"`
+	Properties []CycloneDXProperty `json:"properties"`
+	Components []sbomComponent     `json:"components"`
+}
+
+// spdxSourceInfoPrefix starts the sourceInfo of the packages of the SPDX
+// documents written by container-diff
+const spdxSourceInfoPrefix = "acquired package info from the "
+
+// IsSBOM reports whether path is an SPDX or CycloneDX JSON document rather
+// than an image.
+func IsSBOM(path string) bool {
+	if !strings.HasSuffix(strings.ToLower(path), ".json") {
+		return false
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var doc struct {
+		SPDXVersion string `json:"spdxVersion"`
+		BOMFormat   string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+	return doc.SPDXVersion != "" || doc.BOMFormat == "CycloneDX"
+}
+
+// ReadSBOM reads the packages listed by an SPDX or CycloneDX JSON document,
+// typed by the analyze type of the package analyzer finding them in images.
+// The analyzer is read from the annotations of the documents written by
+// container-diff, and otherwise derived from the package URL. Packages whose
+// analyzer can't be determined are skipped.
+func ReadSBOM(path string) ([]SBOMPackage, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc sbomDocument
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("parsing SBOM %s: %s", path, err)
+	}
+
+	packages := []SBOMPackage{}
+	add := func(name, version, analyzer, path, purl string) {
+		analyzeType := analyzeTypes[analyzer]
+		if analyzeType == "" {
+			analyzeType = purlAnalyzeType(purl)
+		}
+		if analyzeType == "" || name == "" {
+			return
+		}
+		if analyzeType == "Apt" {
+			// match the versions read from the dpkg status file
+			version = strings.Replace(version, "+", " ", 1)
+		}
+		packages = append(packages, SBOMPackage{
+			Name: name,
+			Path: path,
+			Type: analyzeType,
+			Info: PackageInfo{Version: version},
+			PURL: purl,
+		})
+	}
+
+	switch {
+	case doc.SPDXVersion != "":
+		for _, pkg := range doc.Packages {
+			if pkg.Purpose == "CONTAINER" || pkg.SPDXID == "SPDXRef-Image" {
+				continue
+			}
+			var analyzer, path, purl string
+			if strings.HasPrefix(pkg.SourceInfo, spdxSourceInfoPrefix) {
+				source := strings.SplitN(strings.TrimPrefix(pkg.SourceInfo, spdxSourceInfoPrefix), ": ", 2)
+				analyzer = strings.TrimSuffix(source[0], " analyzer")
+				if len(source) == 2 {
+					path = source[1]
+				}
+			}
+			for _, ref := range pkg.ExternalRefs {
+				if ref.Type == "purl" {
+					purl = ref.Locator
+				}
+			}
+			add(pkg.Name, pkg.VersionInfo, analyzer, path, purl)
+		}
+	case doc.BOMFormat == "CycloneDX":
+		var addComponents func(components []sbomComponent)
+		addComponents = func(components []sbomComponent) {
+			for _, component := range components {
+				var analyzer, path string
+				for _, property := range component.Properties {
+					switch property.Name {
+					case "container-diff:analyzer":
+						analyzer = property.Value
+					case "container-diff:path":
+						path = property.Value
+					}
+				}
+				add(component.Name, component.Version, analyzer, path, component.PURL)
+				addComponents(component.Components)
+			}
+		}
+		addComponents(doc.Components)
+	default:
+		return nil, fmt.Errorf("%s is neither an SPDX nor a CycloneDX JSON document", path)
+	}
+	return packages, nil
+}
+
+// analyzeTypes maps analyzer names, as annotated in SBOMs, to analyze types
+var analyzeTypes = func() map[string]string {
+	types := make(map[string]string)
+	for analyzeType := range purlTypes {
+		types[strings.ToLower(analyzeType)] = analyzeType
+	}
+	return types
+}()
+
+// purlAnalyzeType returns the analyze type of the analyzer finding packages of
+// the type of the package URL, or an empty string if there's none or several.
+func purlAnalyzeType(purl string) string {
+	if !strings.HasPrefix(purl, "pkg:") {
+		return ""
+	}
+	purlType := strings.ToLower(strings.SplitN(strings.TrimPrefix(purl, "pkg:"), "/", 2)[0])
+	if purlType == "generic" {
+		return ""
+	}
+	for analyzeType, t := range purlTypes {
+		if t.purlType == purlType {
+			return analyzeType
+		}
+	}
+	return ""
+}
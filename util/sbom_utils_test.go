@@ -0,0 +1,224 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var sbomSource = SBOMSource{
+	Image:   "gcr.io/project/app:latest",
+	Digest:  "sha256:0123456789abcdef",
+	Distro:  Distro{ID: "debian", VersionID: "12"},
+	Tool:    "v0.19.0",
+	Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+var sbomResults = map[string]Result{
+	"AptAnalyzer": &SingleVersionPackageAnalyzeResult{
+		AnalyzeType: "Apt",
+		Analysis: map[string]PackageInfo{
+			"bash": {Version: "5.2.15-2 b2", Arch: "amd64", License: "GPL-3+"},
+		},
+	},
+	"NodeAnalyzer": &MultiVersionPackageAnalyzeResult{
+		AnalyzeType: "Node",
+		Analysis: map[string]map[string]PackageInfo{
+			"left-pad": {"/app/node_modules/left-pad/": {Version: "1.3.0", License: "WTFPL OR Custom"}},
+		},
+	},
+	"SizeAnalyzer": &SizeAnalyzeResult{AnalyzeType: "Size"},
+}
+
+func TestGetSBOMPackages(t *testing.T) {
+	expected := []SBOMPackage{
+		{Name: "bash", Type: "Apt", Info: PackageInfo{Version: "5.2.15-2 b2", Arch: "amd64", License: "GPL-3+"},
+			PURL: "pkg:deb/debian/bash@5.2.15-2%2Bb2?arch=amd64&distro=debian-12"},
+		{Name: "left-pad", Path: "/app/node_modules/left-pad/", Type: "Node", Info: PackageInfo{Version: "1.3.0", License: "WTFPL OR Custom"},
+			PURL: "pkg:npm/left-pad@1.3.0"},
+	}
+	if packages := GetSBOMPackages(sbomResults, sbomSource.Distro); !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+}
+
+func TestNewSPDXDocument(t *testing.T) {
+	doc := NewSPDXDocument(sbomSource, GetSBOMPackages(sbomResults, sbomSource.Distro))
+	if !strings.HasPrefix(doc.DocumentNamespace, spdxNamespaceBase+"/gcr.io%2Fproject%2Fapp:latest-") {
+		t.Errorf("Unexpected document namespace %s", doc.DocumentNamespace)
+	}
+	if doc.CreationInfo.Created != "2024-01-02T03:04:05Z" {
+		t.Errorf("Unexpected creation time %s", doc.CreationInfo.Created)
+	}
+	expectedPackages := []SPDXPackage{
+		{
+			SPDXID: "SPDXRef-Image", Name: "gcr.io/project/app:latest", VersionInfo: "sha256:0123456789abcdef", Purpose: "CONTAINER",
+			DownloadLocation: noAssertion, LicenseConcluded: noAssertion, LicenseDeclared: noAssertion, CopyrightText: noAssertion,
+			Checksums: []SPDXChecksum{{Algorithm: "SHA256", Value: "0123456789abcdef"}},
+			ExternalRefs: []SPDXExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl",
+				Locator: "pkg:oci/app@sha256:0123456789abcdef?repository_url=gcr.io%2Fproject%2Fapp"}},
+		},
+		{
+			SPDXID: "SPDXRef-Package-Apt-1", Name: "bash", VersionInfo: "5.2.15-2+b2", DownloadLocation: noAssertion,
+			SourceInfo: "acquired package info from the apt analyzer", LicenseConcluded: noAssertion,
+			LicenseDeclared: "GPL-3.0-or-later", CopyrightText: noAssertion,
+			ExternalRefs: []SPDXExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: "pkg:deb/debian/bash@5.2.15-2%2Bb2?arch=amd64&distro=debian-12"}},
+		},
+		{
+			SPDXID: "SPDXRef-Package-Node-2", Name: "left-pad", VersionInfo: "1.3.0", DownloadLocation: noAssertion,
+			SourceInfo: "acquired package info from the node analyzer: /app/node_modules/left-pad/", LicenseConcluded: noAssertion,
+			LicenseDeclared: "WTFPL OR LicenseRef-Custom", CopyrightText: noAssertion,
+			ExternalRefs: []SPDXExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: "pkg:npm/left-pad@1.3.0"}},
+		},
+	}
+	if !reflect.DeepEqual(doc.Packages, expectedPackages) {
+		t.Errorf("Expected packages: %v but got: %v", expectedPackages, doc.Packages)
+	}
+	expectedRelationships := []SPDXRelationship{
+		{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", RelatedElement: "SPDXRef-Image"},
+		{Element: "SPDXRef-Image", Type: "CONTAINS", RelatedElement: "SPDXRef-Package-Apt-1"},
+		{Element: "SPDXRef-Image", Type: "CONTAINS", RelatedElement: "SPDXRef-Package-Node-2"},
+	}
+	if !reflect.DeepEqual(doc.Relationships, expectedRelationships) {
+		t.Errorf("Expected relationships: %v but got: %v", expectedRelationships, doc.Relationships)
+	}
+	expectedLicenses := []SPDXExtractedLicense{{LicenseID: "LicenseRef-Custom", Name: "Custom", ExtractedText: "Custom"}}
+	if !reflect.DeepEqual(doc.ExtractedLicenses, expectedLicenses) {
+		t.Errorf("Expected extracted licenses: %v but got: %v", expectedLicenses, doc.ExtractedLicenses)
+	}
+}
+
+func TestNewCycloneDXDocument(t *testing.T) {
+	doc := NewCycloneDXDocument(sbomSource, GetSBOMPackages(sbomResults, sbomSource.Distro))
+	if !strings.HasPrefix(doc.SerialNumber, "urn:uuid:") || len(doc.SerialNumber) != len("urn:uuid:")+36 {
+		t.Errorf("Unexpected serial number %s", doc.SerialNumber)
+	}
+	expectedImage := CycloneDXComponent{
+		Type: "container", BOMRef: "image", Name: "gcr.io/project/app:latest", Version: "sha256:0123456789abcdef",
+		Hashes: []CycloneDXHash{{Algorithm: "SHA-256", Content: "0123456789abcdef"}},
+		PURL:   "pkg:oci/app@sha256:0123456789abcdef?repository_url=gcr.io%2Fproject%2Fapp",
+	}
+	if !reflect.DeepEqual(doc.Metadata.Component, expectedImage) {
+		t.Errorf("Expected image component: %v but got: %v", expectedImage, doc.Metadata.Component)
+	}
+	expectedComponents := []CycloneDXComponent{
+		{
+			Type: "library", BOMRef: "apt-1", Name: "bash", Version: "5.2.15-2+b2",
+			Licenses:   []CycloneDXLicense{{Expression: "GPL-3.0-or-later"}},
+			PURL:       "pkg:deb/debian/bash@5.2.15-2%2Bb2?arch=amd64&distro=debian-12",
+			Properties: []CycloneDXProperty{{Name: "container-diff:analyzer", Value: "apt"}},
+		},
+		{
+			Type: "library", BOMRef: "node-2", Name: "left-pad", Version: "1.3.0",
+			Licenses: []CycloneDXLicense{{License: &CycloneDXLicenseName{Name: "WTFPL OR Custom"}}},
+			PURL:     "pkg:npm/left-pad@1.3.0",
+			Properties: []CycloneDXProperty{
+				{Name: "container-diff:analyzer", Value: "node"},
+				{Name: "container-diff:path", Value: "/app/node_modules/left-pad/"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(doc.Components, expectedComponents) {
+		t.Errorf("Expected components: %v but got: %v", expectedComponents, doc.Components)
+	}
+}
+
+func TestWriteSBOM(t *testing.T) {
+	for _, format := range SBOMFormats {
+		var buffer bytes.Buffer
+		if err := WriteSBOM(&buffer, format, sbomSource, GetSBOMPackages(sbomResults, sbomSource.Distro)); err != nil {
+			t.Fatalf("Got unexpected error writing %s: %s", format, err)
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(buffer.Bytes(), &doc); err != nil {
+			t.Errorf("Invalid %s document: %s", format, err)
+		}
+	}
+	if err := WriteSBOM(&bytes.Buffer{}, "xml", sbomSource, nil); err == nil {
+		t.Errorf("Expected error for unknown format")
+	}
+}
+
+func TestReadSBOM(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sbom")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	written := GetSBOMPackages(sbomResults, sbomSource.Distro)
+	expected := []SBOMPackage{
+		{Name: "bash", Type: "Apt", Info: PackageInfo{Version: "5.2.15-2 b2"}, PURL: written[0].PURL},
+		{Name: "left-pad", Path: "/app/node_modules/left-pad/", Type: "Node", Info: PackageInfo{Version: "1.3.0"}, PURL: written[1].PURL},
+	}
+	for _, format := range SBOMFormats {
+		path := filepath.Join(dir, format+".json")
+		var buf bytes.Buffer
+		if err := WriteSBOM(&buf, format, sbomSource, written); err != nil {
+			t.Fatalf("Got unexpected error writing %s: %s", format, err)
+		}
+		if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatalf("Unable to write %s: %s", path, err)
+		}
+		if !IsSBOM(path) {
+			t.Errorf("Expected %s to be an SBOM", path)
+		}
+		packages, err := ReadSBOM(path)
+		if err != nil {
+			t.Fatalf("Got unexpected error reading %s: %s", format, err)
+		}
+		if !reflect.DeepEqual(packages, expected) {
+			t.Errorf("%s: expected: %v but got: %v", format, expected, packages)
+		}
+	}
+
+	// documents of other tools are typed by package URL
+	thirdParty := filepath.Join(dir, "bom.json")
+	content := `{"bomFormat": "CycloneDX", "specVersion": "1.4", "metadata": {"tools": [{"name": "scanner"}]},
+		"components": [
+			{"type": "library", "name": "requests", "version": "2.31.0", "purl": "pkg:pypi/requests@2.31.0"},
+			{"type": "library", "name": "sys-apps/sed", "version": "4.8", "purl": "pkg:generic/sed@4.8"},
+			{"type": "operating-system", "name": "debian", "version": "12"}
+		]}`
+	if err := ioutil.WriteFile(thirdParty, []byte(content), 0644); err != nil {
+		t.Fatalf("Unable to write %s: %s", thirdParty, err)
+	}
+	expected = []SBOMPackage{
+		{Name: "requests", Type: "Pip", Info: PackageInfo{Version: "2.31.0"}, PURL: "pkg:pypi/requests@2.31.0"},
+	}
+	if packages, err := ReadSBOM(thirdParty); err != nil {
+		t.Errorf("Got unexpected error: %s", err)
+	} else if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, packages)
+	}
+
+	notSBOM := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(notSBOM, []byte(`{"name": "app"}`), 0644); err != nil {
+		t.Fatalf("Unable to write %s: %s", notSBOM, err)
+	}
+	if IsSBOM(notSBOM) || IsSBOM("gcr.io/project/app:latest") {
+		t.Errorf("Expected only SBOMs to be detected")
+	}
+}
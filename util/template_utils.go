@@ -57,7 +57,13 @@ Packages found only in {{.Image2}}:{{if not .Diff.Packages2}} None{{else}}
 NAME	VERSION	SIZE{{range .Diff.Packages2}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}{{end}}{{end}}
 
 Version differences:{{if not .Diff.InfoDiff}} None{{else}}
-PACKAGE	IMAGE1 ({{.Image1}})	IMAGE2 ({{.Image2}}){{range .Diff.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{.Info1.Version}}, {{.Info1.Size}}	{{.Info2.Version}}, {{.Info2.Size}}{{end}}
+PACKAGE	IMAGE1 ({{.Image1}})	IMAGE2 ({{.Image2}})	CHANGE{{range .Diff.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{.Info1.Version}}, {{.Info1.Size}}	{{.Info2.Version}}, {{.Info2.Size}}	{{.Change}}{{end}}
+{{end}}{{if .Diff.Changelogs}}
+Changelog entries of upgraded packages in {{.Image2}}:{{range .Diff.Changelogs}}
+
+{{.Package}} {{.Info1.Version}} -> {{.Info2.Version}}{{range .Changelog}}
+{{.Version}}{{if .CVEs}} ({{join .CVEs ", "}}){{end}}{{if .Date}} - {{.Date}}{{end}}
+{{.Text}}{{end}}{{end}}
 {{end}}
 `
 
@@ -71,26 +77,147 @@ Packages found only in {{.Image2}}:{{if not .Diff.Packages2}} None{{else}}
 NAME	VERSION	SIZE{{range .Diff.Packages2}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}{{end}}{{end}}
 
 Version differences:{{if not .Diff.InfoDiff}} None{{else}}
-PACKAGE	IMAGE1 ({{.Image1}})	IMAGE2 ({{.Image2}}){{range .Diff.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{range .Info1}}{{.Version}}, {{.Size}}{{end}}	{{range .Info2}}{{.Version}}, {{.Size}}{{end}}{{end}}
+PACKAGE	IMAGE1 ({{.Image1}})	IMAGE2 ({{.Image2}})	CHANGE{{range .Diff.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{range .Info1}}{{.Version}}, {{.Size}}{{end}}	{{range .Info2}}{{.Version}}, {{.Size}}{{end}}	{{.Change}}{{end}}
+{{end}}
+`
+
+const SingleVersionPackageLayerDiffOutput = `
+-----{{.DiffType}}-----
+
+Layers whose package changes differ between {{.Image1}} and {{.Image2}}:{{if not .Diff}} None{{end}}
+{{range .Diff}}
+Layer {{.Layer}}:
+Packages installed only in {{$.Image1}}:{{if not .Installed.Packages1}} None{{else}}
+NAME	VERSION	SIZE{{range .Installed.Packages1}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}{{end}}{{end}}
+Packages installed only in {{$.Image2}}:{{if not .Installed.Packages2}} None{{else}}
+NAME	VERSION	SIZE{{range .Installed.Packages2}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}{{end}}{{end}}
+Packages installed with different versions:{{if not .Installed.InfoDiff}} None{{else}}
+PACKAGE	IMAGE1 ({{$.Image1}})	IMAGE2 ({{$.Image2}})	CHANGE{{range .Installed.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{.Info1.Version}}, {{.Info1.Size}}	{{.Info2.Version}}, {{.Info2.Size}}	{{.Change}}{{end}}{{end}}
+Packages removed only in {{$.Image1}}:{{if not .Removed.Packages1}} None{{else}}
+NAME	VERSION	SIZE{{range .Removed.Packages1}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}{{end}}{{end}}
+Packages removed only in {{$.Image2}}:{{if not .Removed.Packages2}} None{{else}}
+NAME	VERSION	SIZE{{range .Removed.Packages2}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}{{end}}{{end}}
+Packages removed with different versions:{{if not .Removed.InfoDiff}} None{{else}}
+PACKAGE	IMAGE1 ({{$.Image1}})	IMAGE2 ({{$.Image2}})	CHANGE{{range .Removed.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{.Info1.Version}}, {{.Info1.Size}}	{{.Info2.Version}}, {{.Info2.Size}}	{{.Change}}{{end}}{{end}}
+Packages updated only in {{$.Image1}}:{{if not .Updated.Packages1}} None{{else}}
+NAME	VERSION	SIZE{{range .Updated.Packages1}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}{{end}}{{end}}
+Packages updated only in {{$.Image2}}:{{if not .Updated.Packages2}} None{{else}}
+NAME	VERSION	SIZE{{range .Updated.Packages2}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}{{end}}{{end}}
+Packages updated with different versions:{{if not .Updated.InfoDiff}} None{{else}}
+PACKAGE	IMAGE1 ({{$.Image1}})	IMAGE2 ({{$.Image2}})	CHANGE{{range .Updated.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{.Info1.Version}}, {{.Info1.Size}}	{{.Info2.Version}}, {{.Info2.Size}}	{{.Change}}{{end}}{{end}}
+{{end}}
+`
+
+const MultiVersionPackageLayerDiffOutput = `
+-----{{.DiffType}}-----
+
+Layers whose package changes differ between {{.Image1}} and {{.Image2}}:{{if not .Diff}} None{{end}}
+{{range .Diff}}
+Layer {{.Layer}}:
+Packages installed only in {{$.Image1}}:{{if not .Installed.Packages1}} None{{else}}
+NAME	VERSION	SIZE	INSTALLATION{{range .Installed.Packages1}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}	{{.Path}}{{end}}{{end}}
+Packages installed only in {{$.Image2}}:{{if not .Installed.Packages2}} None{{else}}
+NAME	VERSION	SIZE	INSTALLATION{{range .Installed.Packages2}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}	{{.Path}}{{end}}{{end}}
+Packages installed with different versions:{{if not .Installed.InfoDiff}} None{{else}}
+PACKAGE	IMAGE1 ({{$.Image1}})	IMAGE2 ({{$.Image2}})	CHANGE{{range .Installed.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{range .Info1}}{{.Version}}, {{.Size}}{{end}}	{{range .Info2}}{{.Version}}, {{.Size}}{{end}}	{{.Change}}{{end}}{{end}}
+Packages removed only in {{$.Image1}}:{{if not .Removed.Packages1}} None{{else}}
+NAME	VERSION	SIZE	INSTALLATION{{range .Removed.Packages1}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}	{{.Path}}{{end}}{{end}}
+Packages removed only in {{$.Image2}}:{{if not .Removed.Packages2}} None{{else}}
+NAME	VERSION	SIZE	INSTALLATION{{range .Removed.Packages2}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}	{{.Path}}{{end}}{{end}}
+Packages removed with different versions:{{if not .Removed.InfoDiff}} None{{else}}
+PACKAGE	IMAGE1 ({{$.Image1}})	IMAGE2 ({{$.Image2}})	CHANGE{{range .Removed.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{range .Info1}}{{.Version}}, {{.Size}}{{end}}	{{range .Info2}}{{.Version}}, {{.Size}}{{end}}	{{.Change}}{{end}}{{end}}
+Packages updated only in {{$.Image1}}:{{if not .Updated.Packages1}} None{{else}}
+NAME	VERSION	SIZE	INSTALLATION{{range .Updated.Packages1}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}	{{.Path}}{{end}}{{end}}
+Packages updated only in {{$.Image2}}:{{if not .Updated.Packages2}} None{{else}}
+NAME	VERSION	SIZE	INSTALLATION{{range .Updated.Packages2}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}	{{.Path}}{{end}}{{end}}
+Packages updated with different versions:{{if not .Updated.InfoDiff}} None{{else}}
+PACKAGE	IMAGE1 ({{$.Image1}})	IMAGE2 ({{$.Image2}})	CHANGE{{range .Updated.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{range .Info1}}{{.Version}}, {{.Size}}{{end}}	{{range .Info2}}{{.Version}}, {{.Size}}{{end}}	{{.Change}}{{end}}{{end}}
 {{end}}
 `
 
 const HistoryDiffOutput = `
 -----{{.DiffType}}-----
 
-Docker history lines found only in {{.Image1}}:{{if not .Diff.Adds}} None{{else}}{{block "list" .Diff.Dels}}{{"\n"}}{{range .}}{{print "-" .}}{{"\n"}}{{end}}{{end}}{{end}}
+History differences between {{.Image1}} and {{.Image2}}:{{if not .Diff}} None{{else}}
+STEP	IMAGE1 ({{.Image1}})	IMAGE2 ({{.Image2}}){{range .Diff}}{{"\n"}}{{print "-"}}{{.Step}}	{{.Step1}}	{{.Step2}}{{end}}
+{{end}}
+`
 
-Docker history lines found only in {{.Image2}}:{{if not .Diff.Dels}} None{{else}}{{block "list2" .Diff.Adds}}{{"\n"}}{{range .}}{{print "-" .}}{{"\n"}}{{end}}{{end}}{{end}}
+const LicenseDiffOutput = `
+-----{{.DiffType}}-----
+
+Licenses found only in {{.Image2}}:{{if not .Diff.Added}} None{{else}}
+PACKAGE	MANAGER	VERSION	LICENSE{{range .Diff.Added}}{{"\n"}}{{print "-"}}{{.Package}}{{if .Path}} ({{.Path}}){{end}}	{{.Manager}}	{{.Version}}	{{.License}}{{end}}{{end}}
+
+Licenses found only in {{.Image1}}:{{if not .Diff.Removed}} None{{else}}
+PACKAGE	MANAGER	VERSION	LICENSE{{range .Diff.Removed}}{{"\n"}}{{print "-"}}{{.Package}}{{if .Path}} ({{.Path}}){{end}}	{{.Manager}}	{{.Version}}	{{.License}}{{end}}{{end}}
+
+License differences:{{if not .Diff.Changed}} None{{else}}
+PACKAGE	MANAGER	IMAGE1 ({{.Image1}})	IMAGE2 ({{.Image2}}){{range .Diff.Changed}}{{"\n"}}{{print "-"}}{{.Package}}{{if .Path}} ({{.Path}}){{end}}	{{.Manager}}	{{.License1}}, {{.Version1}}	{{.License2}}, {{.Version2}}{{end}}
+{{end}}
+`
+
+const IntegrityDiffOutput = `
+-----{{.DiffType}}-----
+
+Integrity regressions in {{.Image2}}:{{if not .Diff.Regressions}} None{{else}}
+FILE	PACKAGE	MANAGER	STATUS{{range .Diff.Regressions}}{{"\n"}}{{print "-"}}{{.Path}}	{{.Package}}	{{.Manager}}	{{.Status}}{{end}}{{end}}
+
+Integrity failures resolved in {{.Image2}}:{{if not .Diff.Resolved}} None{{else}}
+FILE	PACKAGE	MANAGER	STATUS{{range .Diff.Resolved}}{{"\n"}}{{print "-"}}{{.Path}}	{{.Package}}	{{.Manager}}	{{.Status}}{{end}}
+{{end}}
+`
+
+const VulnDiffOutput = `
+-----{{.DiffType}}-----
+
+Vulnerabilities introduced in {{.Image2}}:{{if not .Diff.Introduced}} None{{else}}
+ID	ALIASES	PACKAGE	VERSION	MANAGER	SEVERITY	FIXED{{range .Diff.Introduced}}{{"\n"}}{{print "-"}}{{.ID}}	{{join .Aliases ", "}}	{{.Package}}	{{.Version}}	{{.Manager}}	{{.Severity}}	{{.Fixed}}{{end}}{{end}}
+
+Vulnerabilities resolved in {{.Image2}}:{{if not .Diff.Resolved}} None{{else}}
+ID	ALIASES	PACKAGE	VERSION	MANAGER	SEVERITY	FIXED{{range .Diff.Resolved}}{{"\n"}}{{print "-"}}{{.ID}}	{{join .Aliases ", "}}	{{.Package}}	{{.Version}}	{{.Manager}}	{{.Severity}}	{{.Fixed}}{{end}}
+{{end}}
+`
+
+const DependencyDiffOutput = `
+-----{{.DiffType}}-----
+
+Packages found only in {{.Image1}}:{{if not .Diff.Packages1}} None{{else}}
+PACKAGE	MANAGER	PULLED IN BY{{range .Diff.Packages1}}{{"\n"}}{{print "-"}}{{.Package}}	{{.Manager}}	{{if .Chain}}{{join .Chain " -> "}}{{else}}none{{end}}{{end}}{{end}}
+
+Packages found only in {{.Image2}}:{{if not .Diff.Packages2}} None{{else}}
+PACKAGE	MANAGER	PULLED IN BY{{range .Diff.Packages2}}{{"\n"}}{{print "-"}}{{.Package}}	{{.Manager}}	{{if .Chain}}{{join .Chain " -> "}}{{else}}none{{end}}{{end}}
+{{end}}
+`
+
+const OwnershipDiffOutput = `
+-----{{.DiffType}}-----
+
+Ownership differences:{{if not .Diff}} None{{else}}
+PATH	IMAGE1 ({{.Image1}})	IMAGE2 ({{.Image2}}){{range .Diff}}{{"\n"}}{{print "-"}}{{.Path}}	{{if .Owners1}}{{join .Owners1 ", "}}{{else}}unowned{{end}}	{{if .Owners2}}{{join .Owners2 ", "}}{{else}}unowned{{end}}{{end}}
+{{end}}
 `
 
 const MetadataDiffOutput = `
 -----{{.DiffType}}-----
 
-Image metadata differences between {{.Image1}} and {{.Image2}}:
+Image metadata differences between {{.Image1}} and {{.Image2}}:{{if not .Diff.Fields}} None{{else}}
+FIELD	IMAGE1 ({{.Image1}})	IMAGE2 ({{.Image2}}){{range .Diff.Fields}}{{"\n"}}{{print "-"}}{{.Field}}	{{.Value1}}	{{.Value2}}{{end}}{{end}}
 
-{{.Image1}}{{if not .Diff.Adds}} None{{else}}{{block "list" .Diff.Adds}}{{"\n"}}{{range .}}{{print "-" .}}{{"\n"}}{{end}}{{end}}{{end}}
+Build differences:{{if not .Diff.Build}} None{{else}}
+FIELD	IMAGE1 ({{.Image1}})	IMAGE2 ({{.Image2}}){{range .Diff.Build}}{{"\n"}}{{print "-"}}{{.Field}}	{{.Value1}}	{{.Value2}}{{end}}
+{{end}}
+`
+
+const ManifestDiffOutput = `
+-----{{.DiffType}}-----
 
-{{.Image2}}{{if not .Diff.Dels}} None{{else}}{{block "list2" .Diff.Dels}}{{"\n"}}{{range .}}{{print "-" .}}{{"\n"}}{{end}}{{end}}{{end}}
+Manifest differences between {{.Image1}} and {{.Image2}}:{{if not .Diff.Fields}} None{{else}}
+FIELD	IMAGE1 ({{.Image1}})	IMAGE2 ({{.Image2}}){{range .Diff.Fields}}{{"\n"}}{{print "-"}}{{.Field}}	{{.Value1}}	{{.Value2}}{{end}}{{end}}
+
+Layer differences:{{if not .Diff.Layers}} None{{else}}
+INDEX	IMAGE1 ({{.Image1}})	IMAGE2 ({{.Image2}}){{range .Diff.Layers}}{{"\n"}}{{print "-"}}{{.Index}}	{{.Layer1}}	{{.Layer2}}{{end}}
+{{end}}
 `
 
 const FilenameDiffOutput = `
@@ -122,6 +249,85 @@ const ListAnalysisOutput = `
 Analysis for {{.Image}}:{{if not .Analysis}} None{{else}}{{block "list" .Analysis}}{{"\n"}}{{range .}}{{print "-" .}}{{"\n"}}{{end}}{{end}}{{end}}
 `
 
+const HistoryAnalysisOutput = `
+-----{{.AnalyzeType}}-----
+
+History of {{.Image}}:{{if not .Analysis}} None{{else}}
+CREATED	LAYER	SIZE	CREATED BY{{range .Analysis}}{{"\n"}}{{print "-"}}{{.Created}}	{{.Layer}}	{{.Size}}	{{.CreatedBy}}{{end}}
+{{end}}
+`
+
+const ManifestAnalysisOutput = `
+-----{{.AnalyzeType}}-----
+
+Manifest of {{.Image}}:
+-Digest: {{.Analysis.Digest}}
+-MediaType: {{.Analysis.MediaType}}
+-SchemaVersion: {{.Analysis.SchemaVersion}}
+-Config: {{.Analysis.Config}}{{if .Analysis.Subject}}
+-Subject: {{.Analysis.Subject}}{{end}}
+
+Annotations:{{if not .Analysis.Annotations}} None{{else}}{{range $key, $value := .Analysis.Annotations}}{{"\n"}}{{print "-"}}{{$key}}: {{$value}}{{end}}{{end}}
+
+Layers:{{if not .Analysis.Layers}} None{{else}}
+DIGEST	MEDIA TYPE	COMPRESSION	SIZE	DIFF ID{{range .Analysis.Layers}}{{"\n"}}{{print "-"}}{{.Digest}}	{{.MediaType}}	{{.Compression}}	{{.Size}}	{{.DiffID}}{{end}}
+{{end}}
+`
+
+const LicenseAnalysisOutput = `
+-----{{.AnalyzeType}}-----
+
+Licenses found in {{.Image}}:{{if not .Analysis}} None{{else}}
+PACKAGE	MANAGER	VERSION	LICENSE{{range .Analysis}}{{"\n"}}{{print "-"}}{{.Package}}{{if .Path}} ({{.Path}}){{end}}	{{.Manager}}	{{.Version}}	{{if .License}}{{.License}}{{else}}unknown{{end}}{{end}}
+{{end}}
+`
+
+const IntegrityAnalysisOutput = `
+-----{{.AnalyzeType}}-----
+
+Package files failing verification in {{.Image}}:{{if not .Analysis}} None{{else}}
+FILE	PACKAGE	MANAGER	STATUS{{range .Analysis}}{{"\n"}}{{print "-"}}{{.Path}}	{{.Package}}	{{.Manager}}	{{.Status}}{{end}}
+{{end}}
+`
+
+const PURLAnalysisOutput = `
+-----{{.AnalyzeType}}-----
+
+Packages found in {{.Image}}:{{if not .Analysis}} None{{else}}
+PURL	ANALYZERS	PATHS{{range .Analysis}}{{"\n"}}{{print "-"}}{{.PURL}}	{{join .Analyzers ", "}}	{{join .Paths ", "}}{{end}}
+{{end}}
+`
+
+const VulnAnalysisOutput = `
+-----{{.AnalyzeType}}-----
+
+Vulnerabilities in {{.Image}}:{{if not .Analysis}} None{{else}}
+ID	ALIASES	PACKAGE	VERSION	MANAGER	SEVERITY	FIXED{{range .Analysis}}{{"\n"}}{{print "-"}}{{.ID}}	{{join .Aliases ", "}}	{{.Package}}	{{.Version}}	{{.Manager}}	{{.Severity}}	{{.Fixed}}{{end}}
+{{end}}
+`
+
+const DependencyAnalysisOutput = `
+-----{{.AnalyzeType}}-----
+
+Packages in {{.Image}}:{{if not .Analysis}} None{{else}}
+PACKAGE	MANAGER	INSTALLED	DEPENDENCIES	DEPENDENTS{{range .Analysis}}{{"\n"}}{{print "-"}}{{.Package}}	{{.Manager}}	{{if .Orphan}}orphan{{else if .TopLevel}}top-level{{else}}dependency{{end}}	{{join .Dependencies ", "}}	{{join .Dependents ", "}}{{end}}
+{{end}}
+`
+
+const OwnershipAnalysisOutput = `
+-----{{.AnalyzeType}}-----
+
+Packages owning files in {{.Image}}:{{if not .Analysis.Packages}} None{{else}}
+PACKAGE	MANAGER	FILES	SIZE{{range .Analysis.Packages}}{{"\n"}}{{print "-"}}{{.Package}}	{{.Manager}}	{{.Files}}	{{.Size}}{{end}}{{end}}
+
+Files owned by no package:{{if not .Analysis.Unowned}} None{{else}}
+FILE	SIZE{{range .Analysis.Unowned}}{{"\n"}}{{.Name}}	{{.Size}}{{end}}{{end}}
+
+Files owned by several packages:{{if not .Analysis.MultiOwned}} None{{else}}
+FILE	PACKAGES{{range .Analysis.MultiOwned}}{{"\n"}}{{.Path}}	{{join .Owners ", "}}{{end}}
+{{end}}
+`
+
 const FileAnalysisOutput = `
 -----{{.AnalyzeType}}-----
 
@@ -183,7 +389,23 @@ NAME	VERSION	SIZE{{range $analysis.Packages1}}{{"\n"}}{{print "-"}}{{.Name}}	{{.
 NAME	VERSION	SIZE{{range $analysis.Packages2}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}{{end}}{{end}}
 {{if ne $index 0}}
 Version differences:{{if not $analysis.InfoDiff}} None{{else}}
-PACKAGE	PREV_LAYER	CURRENT_LAYER {{range $analysis.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{.Info1.Version}}, {{.Info1.Size}}	{{.Info2.Version}}, {{.Info2.Size}}{{end}}
+PACKAGE	PREV_LAYER	CURRENT_LAYER	CHANGE{{range $analysis.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{.Info1.Version}}, {{.Info1.Size}}	{{.Info2.Version}}, {{.Info2.Size}}	{{.Change}}{{end}}
+{{end}}{{end}}{{end}}
+{{end}}
+`
+
+const MultiVersionPackageLayerOutput = `
+-----{{.AnalyzeType}}-----
+{{range $index, $analysis := .Analysis}}
+For Layer {{$index}}:{{if not (or (or $analysis.Packages1 $analysis.Packages2) $analysis.InfoDiff)}} No package changes {{else}}
+{{if ne $index 0}}Deleted packages from previous layers:{{if not $analysis.Packages1}} None{{else}}
+NAME	VERSION	SIZE	INSTALLATION{{range $analysis.Packages1}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}	{{.Path}}{{end}}{{end}}
+
+{{end}}Packages added in this layer:{{if not $analysis.Packages2}} None{{else}}
+NAME	VERSION	SIZE	INSTALLATION{{range $analysis.Packages2}}{{"\n"}}{{print "-"}}{{.Name}}	{{.Version}}	{{.Size}}	{{.Path}}{{end}}{{end}}
+{{if ne $index 0}}
+Version differences:{{if not $analysis.InfoDiff}} None{{else}}
+PACKAGE	PREV_LAYER	CURRENT_LAYER	CHANGE{{range $analysis.InfoDiff}}{{"\n"}}{{print "-"}}{{.Package}}	{{range .Info1}}{{.Version}}, {{.Size}}{{end}}	{{range .Info2}}{{.Version}}, {{.Size}}{{end}}	{{.Change}}{{end}}
 {{end}}{{end}}{{end}}
 {{end}}
 `
@@ -0,0 +1,770 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// VersionChange classifies how the version of a package moved between two images.
+type VersionChange string
+
+const (
+	VersionUpgrade   VersionChange = "upgrade"
+	VersionDowngrade VersionChange = "downgrade"
+	// VersionRebuild marks versions that differ as strings but order as equal,
+	// e.g. semver build metadata or PEP 440 zero padding.
+	VersionRebuild VersionChange = "rebuild"
+	// MetadataChange marks packages whose version is unchanged but whose
+	// metadata, e.g. license or architecture, differs.
+	MetadataChange VersionChange = "metadata"
+)
+
+// OnlyUpgrades and OnlyDowngrades restrict the reported version differences
+// to packages whose version moved in that direction.
+var OnlyUpgrades bool
+var OnlyDowngrades bool
+
+// VersionComparator orders two version strings of a single ecosystem.
+// It returns a negative number if v1 < v2, zero if they are equivalent and a
+// positive number if v1 > v2.
+type VersionComparator func(v1, v2 string) int
+
+// ClassifyVersionChange determines whether moving from v1 to v2 is an upgrade,
+// a downgrade or a rebuild according to compare. Identical versions are
+// classified as a metadata change.
+func ClassifyVersionChange(v1, v2 string, compare VersionComparator) VersionChange {
+	if v1 == v2 {
+		return MetadataChange
+	}
+	switch c := compare(v1, v2); {
+	case c < 0:
+		return VersionUpgrade
+	case c > 0:
+		return VersionDowngrade
+	default:
+		return VersionRebuild
+	}
+}
+
+// ClassifyPackageDiff sets the version change of every entry in the diff's InfoDiff.
+func ClassifyPackageDiff(diff *PackageDiff, compare VersionComparator) {
+	for i, info := range diff.InfoDiff {
+		diff.InfoDiff[i].Change = ClassifyVersionChange(epochVersion(info.Info1), epochVersion(info.Info2), compare)
+	}
+}
+
+// epochVersion returns the version of a package prefixed by its epoch, for
+// package managers recording the epoch apart from the version, so that the
+// epoch takes precedence when ordering versions.
+func epochVersion(info PackageInfo) string {
+	if info.Epoch == "" {
+		return info.Version
+	}
+	return info.Epoch + ":" + info.Version
+}
+
+// ClassifyMultiVersionPackageDiff sets the version change of every entry in the diff's InfoDiff.
+// Packages with several installations are classified by their highest version in each image;
+// entries that only gained or lost installations are left unclassified.
+func ClassifyMultiVersionPackageDiff(diff *MultiVersionPackageDiff, compare VersionComparator) {
+	for i, info := range diff.InfoDiff {
+		if len(info.Info1) == 0 || len(info.Info2) == 0 {
+			continue
+		}
+		v1 := highestVersion(info.Info1, compare)
+		v2 := highestVersion(info.Info2, compare)
+		diff.InfoDiff[i].Change = ClassifyVersionChange(v1, v2, compare)
+	}
+}
+
+func highestVersion(infos []PackageInfo, compare VersionComparator) string {
+	highest := epochVersion(infos[0])
+	for _, info := range infos[1:] {
+		if version := epochVersion(info); compare(version, highest) > 0 {
+			highest = version
+		}
+	}
+	return highest
+}
+
+func filterInfoDiff(infoDiff []Info) []Info {
+	if !OnlyUpgrades && !OnlyDowngrades {
+		return infoDiff
+	}
+	filtered := []Info{}
+	for _, info := range infoDiff {
+		if keepVersionChange(info.Change) {
+			filtered = append(filtered, info)
+		}
+	}
+	return filtered
+}
+
+func filterMultiVersionInfoDiff(infoDiff []MultiVersionInfo) []MultiVersionInfo {
+	if !OnlyUpgrades && !OnlyDowngrades {
+		return infoDiff
+	}
+	filtered := []MultiVersionInfo{}
+	for _, info := range infoDiff {
+		if keepVersionChange(info.Change) {
+			filtered = append(filtered, info)
+		}
+	}
+	return filtered
+}
+
+func keepVersionChange(change VersionChange) bool {
+	return (OnlyUpgrades && change == VersionUpgrade) || (OnlyDowngrades && change == VersionDowngrade)
+}
+
+// CompareDebianVersions compares two dpkg versions of the form [epoch:]upstream[-revision]
+// following the algorithm described in deb-version(7).
+func CompareDebianVersions(v1, v2 string) int {
+	e1, u1, r1 := splitDebianVersion(v1)
+	e2, u2, r2 := splitDebianVersion(v2)
+	if c := compareNumericStrings(e1, e2); c != 0 {
+		return c
+	}
+	if c := dpkgVerRevCmp(u1, u2); c != 0 {
+		return c
+	}
+	return dpkgVerRevCmp(r1, r2)
+}
+
+func splitDebianVersion(version string) (epoch, upstream, revision string) {
+	version = strings.TrimSpace(version)
+	epoch = "0"
+	if i := strings.Index(version, ":"); i >= 0 {
+		epoch, version = version[:i], version[i+1:]
+	}
+	if i := strings.LastIndex(version, "-"); i >= 0 {
+		return epoch, version[:i], version[i+1:]
+	}
+	return epoch, version, ""
+}
+
+// dpkgOrder weights a character for dpkgVerRevCmp: '~' sorts before everything,
+// even the end of a part, and letters sort before all other non-digits.
+func dpkgOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case isLetter(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func dpkgVerRevCmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			ac, bc := dpkgOrder(a, i), dpkgOrder(b, j)
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+	return 0
+}
+
+// CompareRPMVersions compares two RPM versions of the form [epoch:]version[-release]
+// using the same segment comparison as rpmvercmp.
+func CompareRPMVersions(v1, v2 string) int {
+	e1, ver1, rel1 := splitRPMVersion(v1)
+	e2, ver2, rel2 := splitRPMVersion(v2)
+	if c := compareNumericStrings(e1, e2); c != 0 {
+		return c
+	}
+	if c := rpmVerCmp(ver1, ver2); c != 0 {
+		return c
+	}
+	return rpmVerCmp(rel1, rel2)
+}
+
+func splitRPMVersion(version string) (epoch, ver, release string) {
+	version = strings.TrimSpace(version)
+	epoch = "0"
+	if i := strings.Index(version, ":"); i >= 0 {
+		epoch, version = version[:i], version[i+1:]
+	}
+	if i := strings.LastIndex(version, "-"); i >= 0 {
+		return epoch, version[:i], version[i+1:]
+	}
+	return epoch, version, ""
+}
+
+func rpmVerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+	for len(a) > 0 || len(b) > 0 {
+		a = strings.TrimLeftFunc(a, isRPMSeparator)
+		b = strings.TrimLeftFunc(b, isRPMSeparator)
+
+		// a tilde sorts before everything else, including the end of the string
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		// a caret sorts after the end of the string but before anything else
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			if a == "" {
+				return -1
+			}
+			if b == "" {
+				return 1
+			}
+			if !strings.HasPrefix(a, "^") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "^") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		if a == "" || b == "" {
+			break
+		}
+
+		numeric := isDigit(a[0])
+		segA, restA := splitSegment(a, numeric)
+		segB, restB := splitSegment(b, numeric)
+		if segB == "" {
+			// numeric segments are always newer than alpha segments
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+		var c int
+		if numeric {
+			c = compareNumericStrings(segA, segB)
+		} else {
+			c = strings.Compare(segA, segB)
+		}
+		if c != 0 {
+			return c
+		}
+		a, b = restA, restB
+	}
+	if a == "" && b == "" {
+		return 0
+	}
+	if a == "" {
+		return -1
+	}
+	return 1
+}
+
+func isRPMSeparator(r rune) bool {
+	if r >= 128 {
+		return true
+	}
+	return !isDigit(byte(r)) && !isLetter(byte(r)) && r != '~' && r != '^'
+}
+
+func splitSegment(s string, numeric bool) (string, string) {
+	i := 0
+	for i < len(s) && ((numeric && isDigit(s[i])) || (!numeric && isLetter(s[i]))) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+var pep440Regex = regexp.MustCompile(`^v?(?:(?P<epoch>[0-9]+)!)?(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?P<pre>[-_.]?(?P<pre_l>a|b|c|rc|alpha|beta|pre|preview)[-_.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?P<post>(?:-(?P<post_n1>[0-9]+))|(?:[-_.]?(?P<post_l>post|rev|r)[-_.]?(?P<post_n2>[0-9]+)?))?` +
+	`(?P<dev>[-_.]?(?P<dev_l>dev)[-_.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+type pep440Version struct {
+	epoch   string
+	release []string
+	// pre, post and dev hold a phase rank followed by its number so that a
+	// missing segment sorts as PEP 440 requires
+	pre   [2]int
+	post  int
+	dev   int
+	local []string
+}
+
+const (
+	pep440Absent   = -1
+	pep440Infinity = 1<<31 - 1
+)
+
+// ComparePEP440Versions compares two Python package versions as specified by PEP 440.
+// Versions that do not conform to PEP 440 fall back to dpkg-style ordering.
+func ComparePEP440Versions(v1, v2 string) int {
+	p1, ok1 := parsePEP440(v1)
+	p2, ok2 := parsePEP440(v2)
+	if !ok1 || !ok2 {
+		return dpkgVerRevCmp(v1, v2)
+	}
+	if c := compareNumericStrings(p1.epoch, p2.epoch); c != 0 {
+		return c
+	}
+	if c := compareReleases(p1.release, p2.release); c != 0 {
+		return c
+	}
+	for k := 0; k < 2; k++ {
+		if c := p1.pre[k] - p2.pre[k]; c != 0 {
+			return sign(c)
+		}
+	}
+	if c := p1.post - p2.post; c != 0 {
+		return sign(c)
+	}
+	if c := p1.dev - p2.dev; c != 0 {
+		return sign(c)
+	}
+	return comparePEP440Local(p1.local, p2.local)
+}
+
+func parsePEP440(version string) (pep440Version, bool) {
+	match := pep440Regex.FindStringSubmatch(strings.ToLower(strings.TrimSpace(version)))
+	if match == nil {
+		return pep440Version{}, false
+	}
+	group := func(name string) string {
+		return match[pep440Regex.SubexpIndex(name)]
+	}
+
+	v := pep440Version{epoch: group("epoch"), post: pep440Absent, dev: pep440Infinity}
+	if v.epoch == "" {
+		v.epoch = "0"
+	}
+	v.release = strings.Split(group("release"), ".")
+
+	if group("pre") != "" {
+		ranks := map[string]int{"a": 0, "alpha": 0, "b": 1, "beta": 1, "c": 2, "rc": 2, "pre": 2, "preview": 2}
+		v.pre = [2]int{ranks[group("pre_l")], atoiOrZero(group("pre_n"))}
+	} else if group("post") == "" && group("dev") != "" {
+		// 1.0.dev0 sorts before 1.0a0
+		v.pre = [2]int{pep440Absent, 0}
+	} else {
+		v.pre = [2]int{pep440Infinity, 0}
+	}
+	if group("post") != "" {
+		v.post = atoiOrZero(group("post_n1") + group("post_n2"))
+	}
+	if group("dev") != "" {
+		v.dev = atoiOrZero(group("dev_n"))
+	}
+	if local := group("local"); local != "" {
+		v.local = strings.FieldsFunc(local, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	}
+	return v, true
+}
+
+// compareReleases compares dotted numeric releases, ignoring trailing zeros.
+func compareReleases(r1, r2 []string) int {
+	for i := 0; i < len(r1) || i < len(r2); i++ {
+		s1, s2 := "0", "0"
+		if i < len(r1) {
+			s1 = r1[i]
+		}
+		if i < len(r2) {
+			s2 = r2[i]
+		}
+		if c := compareNumericStrings(s1, s2); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// comparePEP440Local orders local version labels: no label sorts first,
+// numeric segments sort after alphanumeric ones and longer labels win ties.
+func comparePEP440Local(l1, l2 []string) int {
+	for i := 0; i < len(l1) && i < len(l2); i++ {
+		n1, n2 := isNumeric(l1[i]), isNumeric(l2[i])
+		switch {
+		case n1 && n2:
+			if c := compareNumericStrings(l1[i], l2[i]); c != 0 {
+				return c
+			}
+		case n1:
+			return 1
+		case n2:
+			return -1
+		default:
+			if c := strings.Compare(l1[i], l2[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(l1) - len(l2))
+}
+
+var semverRegex = regexp.MustCompile(`^[v=]?\s*([0-9]+)(?:\.([0-9]+))?(?:\.([0-9]+))?(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// CompareSemverVersions compares two semantic versions as used by npm.
+// Build metadata is ignored, so versions differing only in it are equivalent.
+// Versions that are not valid semver fall back to dpkg-style ordering.
+func CompareSemverVersions(v1, v2 string) int {
+	m1 := semverRegex.FindStringSubmatch(strings.TrimSpace(v1))
+	m2 := semverRegex.FindStringSubmatch(strings.TrimSpace(v2))
+	if m1 == nil || m2 == nil {
+		return dpkgVerRevCmp(v1, v2)
+	}
+	for i := 1; i <= 3; i++ {
+		if c := compareNumericStrings(orZero(m1[i]), orZero(m2[i])); c != 0 {
+			return c
+		}
+	}
+	pre1, pre2 := m1[4], m2[4]
+	switch {
+	case pre1 == "" && pre2 == "":
+		return 0
+	case pre1 == "":
+		return 1
+	case pre2 == "":
+		return -1
+	}
+	ids1, ids2 := strings.Split(pre1, "."), strings.Split(pre2, ".")
+	for i := 0; i < len(ids1) && i < len(ids2); i++ {
+		n1, n2 := isNumeric(ids1[i]), isNumeric(ids2[i])
+		switch {
+		case n1 && n2:
+			if c := compareNumericStrings(ids1[i], ids2[i]); c != 0 {
+				return c
+			}
+		case n1:
+			return -1
+		case n2:
+			return 1
+		default:
+			if c := strings.Compare(ids1[i], ids2[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(ids1) - len(ids2))
+}
+
+var gentooRegex = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)*)([a-z]?)((?:_(?:alpha|beta|pre|rc|p)[0-9]*)*)(?:-r([0-9]+))?$`)
+var gentooSuffixRegex = regexp.MustCompile(`_(alpha|beta|pre|rc|p)([0-9]*)`)
+
+// gentooSuffixRank orders the Gentoo version suffixes; a missing suffix ranks
+// between _rc and _p.
+var gentooSuffixRank = map[string]int{"alpha": 0, "beta": 1, "pre": 2, "rc": 3, "": 4, "p": 5}
+
+// CompareGentooVersions compares two Gentoo package versions following the
+// algorithm of the Package Manager Specification.
+// Versions that are not valid Gentoo versions fall back to dpkg-style ordering.
+func CompareGentooVersions(v1, v2 string) int {
+	m1 := gentooRegex.FindStringSubmatch(strings.TrimSpace(v1))
+	m2 := gentooRegex.FindStringSubmatch(strings.TrimSpace(v2))
+	if m1 == nil || m2 == nil {
+		return dpkgVerRevCmp(v1, v2)
+	}
+	if c := compareSuffixedVersions(m1, m2, gentooSuffixRegex, gentooSuffixRank); c != 0 {
+		return c
+	}
+	return compareNumericStrings(orZero(m1[4]), orZero(m2[4]))
+}
+
+var apkRegex = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)*)([a-z]?)((?:_(?:alpha|beta|pre|rc|cvs|svn|git|hg|p)[0-9]*)*)(?:~([0-9a-f]+))?(?:-r([0-9]+))?$`)
+var apkSuffixRegex = regexp.MustCompile(`_(alpha|beta|pre|rc|cvs|svn|git|hg|p)([0-9]*)`)
+
+// apkSuffixRank orders the apk version suffixes; a missing suffix ranks
+// between the prerelease suffixes and the snapshot and patch ones.
+var apkSuffixRank = map[string]int{
+	"alpha": 0, "beta": 1, "pre": 2, "rc": 3, "": 4, "cvs": 5, "svn": 6, "git": 7, "hg": 8, "p": 9,
+}
+
+// CompareApkVersions compares two Alpine package versions following apk-tools:
+// versions are made like Gentoo ones of numbers, a letter and suffixes, which
+// also include the _cvs, _svn, _git and _hg snapshots, then a ~commit hash and
+// a -r package release.
+// Versions that are not valid apk versions fall back to dpkg-style ordering.
+func CompareApkVersions(v1, v2 string) int {
+	m1 := apkRegex.FindStringSubmatch(strings.TrimSpace(v1))
+	m2 := apkRegex.FindStringSubmatch(strings.TrimSpace(v2))
+	if m1 == nil || m2 == nil {
+		return dpkgVerRevCmp(v1, v2)
+	}
+	if c := compareSuffixedVersions(m1, m2, apkSuffixRegex, apkSuffixRank); c != 0 {
+		return c
+	}
+	if c := strings.Compare(m1[4], m2[4]); c != 0 {
+		return c
+	}
+	return compareNumericStrings(orZero(m1[5]), orZero(m2[5]))
+}
+
+// compareSuffixedVersions compares the numbers, letter and suffixes of two
+// Gentoo-style versions, matched as the first three groups of m1 and m2.
+func compareSuffixedVersions(m1, m2 []string, suffixRegex *regexp.Regexp, suffixRank map[string]int) int {
+	nums1, nums2 := strings.Split(m1[1], "."), strings.Split(m2[1], ".")
+	if c := compareNumericStrings(nums1[0], nums2[0]); c != 0 {
+		return c
+	}
+	for i := 1; i < len(nums1) && i < len(nums2); i++ {
+		var c int
+		if strings.HasPrefix(nums1[i], "0") || strings.HasPrefix(nums2[i], "0") {
+			// components with a leading zero compare as decimal fractions
+			c = strings.Compare(strings.TrimRight(nums1[i], "0"), strings.TrimRight(nums2[i], "0"))
+		} else {
+			c = compareNumericStrings(nums1[i], nums2[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	if c := sign(len(nums1) - len(nums2)); c != 0 {
+		return c
+	}
+	if c := strings.Compare(m1[2], m2[2]); c != 0 {
+		return c
+	}
+
+	suffixes1 := suffixRegex.FindAllStringSubmatch(m1[3], -1)
+	suffixes2 := suffixRegex.FindAllStringSubmatch(m2[3], -1)
+	for i := 0; i < len(suffixes1) || i < len(suffixes2); i++ {
+		s1, s2 := []string{"", "", "0"}, []string{"", "", "0"}
+		if i < len(suffixes1) {
+			s1 = suffixes1[i]
+		}
+		if i < len(suffixes2) {
+			s2 = suffixes2[i]
+		}
+		if c := suffixRank[s1[1]] - suffixRank[s2[1]]; c != 0 {
+			return sign(c)
+		}
+		if c := compareNumericStrings(orZero(s1[2]), orZero(s2[2])); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+var gemSegmentRegex = regexp.MustCompile(`[0-9]+|[a-zA-Z]+`)
+
+// CompareRubyGemsVersions compares two RubyGems versions following Gem::Version:
+// versions are split into numeric and alphabetic segments, a missing segment
+// counts as 0 and an alphabetic segment marks a prerelease, ordered before any
+// numeric segment.
+func CompareRubyGemsVersions(v1, v2 string) int {
+	segments1 := gemSegmentRegex.FindAllString(v1, -1)
+	segments2 := gemSegmentRegex.FindAllString(v2, -1)
+	for i := 0; i < len(segments1) || i < len(segments2); i++ {
+		s1, s2 := "0", "0"
+		if i < len(segments1) {
+			s1 = segments1[i]
+		}
+		if i < len(segments2) {
+			s2 = segments2[i]
+		}
+		n1, n2 := isNumeric(s1), isNumeric(s2)
+		var c int
+		switch {
+		case n1 && n2:
+			c = compareNumericStrings(s1, s2)
+		case n1:
+			c = 1
+		case n2:
+			c = -1
+		default:
+			c = strings.Compare(s1, s2)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+var mavenTokenRegex = regexp.MustCompile(`[0-9]+|[a-zA-Z]+`)
+
+// mavenQualifierRank orders the well-known Maven version qualifiers; a missing
+// qualifier is a release. Unknown qualifiers come after sp, in lexical order.
+var mavenQualifierRank = map[string]int{
+	"alpha": 0, "a": 0, "beta": 1, "b": 1, "milestone": 2, "m": 2, "rc": 3, "cr": 3,
+	"snapshot": 4, "": 5, "ga": 5, "final": 5, "release": 5, "sp": 6,
+}
+
+// CompareMavenVersions compares two Maven artifact versions in the spirit of
+// Maven's ComparableVersion: versions are split into numeric and qualifier
+// tokens, qualifiers such as alpha, rc or SNAPSHOT order before the release and
+// a number orders after any qualifier.
+func CompareMavenVersions(v1, v2 string) int {
+	tokens1 := mavenTokenRegex.FindAllString(strings.ToLower(v1), -1)
+	tokens2 := mavenTokenRegex.FindAllString(strings.ToLower(v2), -1)
+	for i := 0; i < len(tokens1) || i < len(tokens2); i++ {
+		t1, t2 := "", ""
+		if i < len(tokens1) {
+			t1 = tokens1[i]
+		}
+		if i < len(tokens2) {
+			t2 = tokens2[i]
+		}
+		if c := compareMavenTokens(t1, t2); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareMavenTokens(t1, t2 string) int {
+	n1, n2 := isNumeric(t1), isNumeric(t2)
+	switch {
+	case n1 && n2:
+		return compareNumericStrings(t1, t2)
+	case n1:
+		// a missing token counts as 0
+		if t2 == "" {
+			return compareNumericStrings(t1, "0")
+		}
+		return 1
+	case n2:
+		if t1 == "" {
+			return compareNumericStrings("0", t2)
+		}
+		return -1
+	}
+	rank1, known1 := mavenQualifierRank[t1]
+	rank2, known2 := mavenQualifierRank[t2]
+	switch {
+	case known1 && known2:
+		return sign(rank1 - rank2)
+	case known1:
+		return -1
+	case known2:
+		return 1
+	}
+	return strings.Compare(t1, t2)
+}
+
+// CompareRVersions compares two R package versions, made of at least two
+// non-negative integers separated by '.' or '-' as R's package_version.
+// Versions that are not valid R versions fall back to dpkg-style ordering.
+func CompareRVersions(v1, v2 string) int {
+	isSeparator := func(r rune) bool { return r == '.' || r == '-' }
+	r1, r2 := strings.FieldsFunc(v1, isSeparator), strings.FieldsFunc(v2, isSeparator)
+	for _, part := range append(append([]string{}, r1...), r2...) {
+		if !isNumeric(part) {
+			return sign(dpkgVerRevCmp(v1, v2))
+		}
+	}
+	// unlike releases, R versions with trailing zeros are longer, hence newer
+	if c := compareReleases(r1, r2); c != 0 {
+		return c
+	}
+	return sign(len(r1) - len(r2))
+}
+
+// compareNumericStrings compares two strings of digits by value, without
+// limiting their length.
+func compareNumericStrings(a, b string) int {
+	n1, ok1 := new(big.Int).SetString(orZero(a), 10)
+	n2, ok2 := new(big.Int).SetString(orZero(b), 10)
+	if !ok1 || !ok2 {
+		return strings.Compare(a, b)
+	}
+	return n1.Cmp(n2)
+}
+
+func atoiOrZero(s string) int {
+	n, ok := new(big.Int).SetString(orZero(s), 10)
+	if !ok || !n.IsInt64() || n.Int64() >= pep440Infinity {
+		return 0
+	}
+	return int(n.Int64())
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
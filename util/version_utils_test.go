@@ -0,0 +1,225 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+type versionTest struct {
+	v1       string
+	v2       string
+	expected int
+}
+
+func checkVersionComparator(t *testing.T, name string, compare VersionComparator, tests []versionTest) {
+	for _, test := range tests {
+		if actual := compare(test.v1, test.v2); actual != test.expected {
+			t.Errorf("%s(%q, %q): expected %d but got %d", name, test.v1, test.v2, test.expected, actual)
+		}
+		if actual := compare(test.v2, test.v1); actual != -test.expected {
+			t.Errorf("%s(%q, %q): expected %d but got %d", name, test.v2, test.v1, -test.expected, actual)
+		}
+	}
+}
+
+func TestCompareDebianVersions(t *testing.T) {
+	checkVersionComparator(t, "CompareDebianVersions", CompareDebianVersions, []versionTest{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1:2.3-4ubuntu1", "1:2.3-4", 1},
+		{"1:1.0", "2.0", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0", "1.0+dfsg", -1},
+		{"1.0-1", "1.0-1.1", -1},
+		{"3.0.2-0ubuntu1.10", "3.0.2-0ubuntu1.12", -1},
+		{"1.2a", "1.2+", -1},
+		{"007", "7", 0},
+	})
+}
+
+func TestCompareRPMVersions(t *testing.T) {
+	checkVersionComparator(t, "CompareRPMVersions", CompareRPMVersions, []versionTest{
+		{"1.0-1", "1.0-1", 0},
+		{"1.0-1", "1.0-2", -1},
+		{"1.0.1-1", "1.0-5", 1},
+		{"1:1.0-1", "2.0-1", 1},
+		{"1.0~rc1-1", "1.0-1", -1},
+		{"1.0^git1-1", "1.0-1", 1},
+		{"1.0a-1", "1.0.1-1", -1},
+		{"2.02-1.el8", "2.2-1.el8", 0},
+		{"1.0-1.el8", "1.0-1.el8_2", -1},
+	})
+}
+
+func TestComparePEP440Versions(t *testing.T) {
+	checkVersionComparator(t, "ComparePEP440Versions", ComparePEP440Versions, []versionTest{
+		{"1.0", "1.0.0", 0},
+		{"1.0.dev0", "1.0a1", -1},
+		{"1.0a1", "1.0b1", -1},
+		{"1.0rc1", "1.0", -1},
+		{"1.0", "1.0.post1", -1},
+		{"1.0.post1.dev1", "1.0.post1", -1},
+		{"1!0.1", "2.0", 1},
+		{"1.0", "1.0+local", -1},
+		{"1.0+abc", "1.0+5", -1},
+		{"2.0.0", "0.8.0", 1},
+		{"1.0-alpha-1", "1.0a1", 0},
+	})
+}
+
+func TestCompareSemverVersions(t *testing.T) {
+	checkVersionComparator(t, "CompareSemverVersions", CompareSemverVersions, []versionTest{
+		{"1.2.4", "0.1.1", 1},
+		{"1.0.0", "v1.0.0", 0},
+		{"1.0.0", "1.0.0+build.5", 0},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.10.0", "1.9.0", 1},
+	})
+}
+
+func TestCompareGentooVersions(t *testing.T) {
+	checkVersionComparator(t, "CompareGentooVersions", CompareGentooVersions, []versionTest{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0-r1", -1},
+		{"1.0_rc1", "1.0", -1},
+		{"1.0", "1.0_p1", -1},
+		{"1.0_alpha", "1.0_beta", -1},
+		{"1.0a", "1.0b", -1},
+		{"1.01", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"1.0", "1.0.1", -1},
+	})
+}
+
+func TestCompareApkVersions(t *testing.T) {
+	checkVersionComparator(t, "CompareApkVersions", CompareApkVersions, []versionTest{
+		{"1.2.3-r0", "1.2.3-r0", 0},
+		{"1.2.3-r0", "1.2.3-r1", -1},
+		{"3.0.8-r0", "3.0.10-r0", -1},
+		{"1.36.1-r2", "1.36.1-r15", -1},
+		{"2.40.0_rc1-r0", "2.40.0-r0", -1},
+		{"1.0_alpha1", "1.0_beta", -1},
+		{"1.0_pre2", "1.0_rc1", -1},
+		{"0.6.1_git20230101-r0", "0.6.1-r0", 1},
+		{"0.6.1_git20230101-r0", "0.6.2-r0", -1},
+		{"0.6.1_git20230101-r0", "0.6.1_git20230215-r0", -1},
+		{"1.0_cvs20050101", "1.0_svn1", -1},
+		{"1.0_svn1", "1.0_git1", -1},
+		{"1.0_git1", "1.0_hg1", -1},
+		{"1.0_hg1", "1.0_p1", -1},
+		{"1.1.1t-r0", "1.1.1u-r0", -1},
+		{"1.1.1w-r1", "3.0.0-r0", -1},
+		{"9.3_p2-r0", "9.3_p1-r3", 1},
+		{"1.0_git20230101_p1", "1.0_git20230101", 1},
+		{"2.0~a1b2c3-r0", "2.0~a1b2c4-r0", -1},
+	})
+}
+
+func TestCompareRubyGemsVersions(t *testing.T) {
+	checkVersionComparator(t, "CompareRubyGemsVersions", CompareRubyGemsVersions, []versionTest{
+		{"1.0", "1.0.0", 0},
+		{"1.13.9", "1.13.10", -1},
+		{"7.0.4.1", "7.0.4", 1},
+		{"7.1.0.rc1", "7.1.0", -1},
+		{"7.1.0.beta1", "7.1.0.rc1", -1},
+		{"1.0.a10", "1.0.a9", 1},
+	})
+}
+
+func TestCompareRVersions(t *testing.T) {
+	checkVersionComparator(t, "CompareRVersions", CompareRVersions, []versionTest{
+		{"1.2-3", "1.2.3", 0},
+		{"1.2-3", "1.2-10", -1},
+		{"1.0", "1.0-1", -1},
+		{"0.4.10", "0.4.9", 1},
+		{"3.1-162", "3.1-162.1", -1},
+	})
+}
+
+func TestCompareMavenVersions(t *testing.T) {
+	checkVersionComparator(t, "CompareMavenVersions", CompareMavenVersions, []versionTest{
+		{"1.0", "1.0.0", 0},
+		{"5.3.9", "5.3.10", -1},
+		{"2.0-SNAPSHOT", "2.0", -1},
+		{"2.0-alpha1", "2.0-beta1", -1},
+		{"2.0-rc1", "2.0-SNAPSHOT", -1},
+		{"5.3.23.RELEASE", "5.3.23", 0},
+		{"1.0-sp1", "1.0", 1},
+		{"1.0.1", "1.0-sp1", 1},
+		{"1.8.0_352", "11.0.17", -1},
+	})
+}
+
+func TestClassifyPackageDiff(t *testing.T) {
+	diff := PackageDiff{
+		InfoDiff: []Info{
+			{Package: "up", Info1: PackageInfo{Version: "1.0"}, Info2: PackageInfo{Version: "1.1"}},
+			{Package: "down", Info1: PackageInfo{Version: "1:2.3-4ubuntu1"}, Info2: PackageInfo{Version: "1:2.3-4"}},
+			{Package: "same", Info1: PackageInfo{Version: "007"}, Info2: PackageInfo{Version: "7"}},
+			{Package: "relicensed", Info1: PackageInfo{Version: "1.0", License: "GPL-2.0"}, Info2: PackageInfo{Version: "1.0", License: "MIT"}},
+		},
+	}
+	ClassifyPackageDiff(&diff, CompareDebianVersions)
+	expected := []VersionChange{VersionUpgrade, VersionDowngrade, VersionRebuild, MetadataChange}
+	for i, info := range diff.InfoDiff {
+		if info.Change != expected[i] {
+			t.Errorf("%s: expected %s but got %s", info.Package, expected[i], info.Change)
+		}
+	}
+
+	rpmDiff := PackageDiff{
+		InfoDiff: []Info{
+			{Package: "epoch-bump", Info1: PackageInfo{Version: "3.0-1"}, Info2: PackageInfo{Version: "2.0-1", Epoch: "1"}},
+			{Package: "epoch-drop", Info1: PackageInfo{Version: "1.0-1", Epoch: "2"}, Info2: PackageInfo{Version: "1.5-1", Epoch: "1"}},
+			{Package: "same-epoch", Info1: PackageInfo{Version: "1.0-1", Epoch: "1"}, Info2: PackageInfo{Version: "1.1-1", Epoch: "1"}},
+		},
+	}
+	ClassifyPackageDiff(&rpmDiff, CompareRPMVersions)
+	expected = []VersionChange{VersionUpgrade, VersionDowngrade, VersionUpgrade}
+	for i, info := range rpmDiff.InfoDiff {
+		if info.Change != expected[i] {
+			t.Errorf("%s: expected %s but got %s", info.Package, expected[i], info.Change)
+		}
+	}
+
+	OnlyDowngrades = true
+	defer func() { OnlyDowngrades = false }()
+	filtered := filterInfoDiff(diff.InfoDiff)
+	if len(filtered) != 1 || filtered[0].Package != "down" {
+		t.Errorf("expected only the downgrade to be kept but got %v", filtered)
+	}
+}
+
+func TestClassifyMultiVersionPackageDiff(t *testing.T) {
+	diff := MultiVersionPackageDiff{
+		InfoDiff: []MultiVersionInfo{
+			{Package: "pac1", Info1: []PackageInfo{{Version: "2.0"}, {Version: "3.0"}}, Info2: []PackageInfo{{Version: "2.5"}}},
+			{Package: "pac2", Info1: []PackageInfo{}, Info2: []PackageInfo{{Version: "1.0"}}},
+		},
+	}
+	ClassifyMultiVersionPackageDiff(&diff, CompareSemverVersions)
+	if diff.InfoDiff[0].Change != VersionDowngrade {
+		t.Errorf("expected pac1 to be a downgrade but got %q", diff.InfoDiff[0].Change)
+	}
+	if diff.InfoDiff[1].Change != "" {
+		t.Errorf("expected pac2 to be unclassified but got %q", diff.InfoDiff[1].Change)
+	}
+}
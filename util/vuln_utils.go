@@ -0,0 +1,514 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Vulnerability is a vulnerability of the OSV database affecting a package of an image.
+type Vulnerability struct {
+	ID      string
+	Aliases []string `json:",omitempty"`
+	Package string
+	// Path is the installation path of packages installed in several places
+	Path    string `json:",omitempty"`
+	Version string
+	// Manager is the package manager the package was installed with, e.g. apt or pip.
+	Manager string
+	// Ecosystem is the OSV ecosystem the vulnerability was matched in, e.g. Debian:12.
+	Ecosystem string
+	// Severity is one of CRITICAL, HIGH, MEDIUM, LOW or UNKNOWN, or the rating of
+	// the distribution for OS packages, e.g. negligible.
+	Severity string
+	// Score is the CVSS v3 base score, if the vulnerability has a CVSS v3 vector.
+	Score float64 `json:",omitempty"`
+	// Fixed is the first version fixing the vulnerability, if it is fixed.
+	Fixed   string `json:",omitempty"`
+	Summary string `json:",omitempty"`
+}
+
+// VulnerabilityDiff stores the vulnerabilities introduced in Image2, affecting
+// none of the packages of Image1, and those resolved, affecting none of the
+// packages of Image2.
+type VulnerabilityDiff struct {
+	Introduced []Vulnerability
+	Resolved   []Vulnerability
+}
+
+// osvEntry is a vulnerability in the Open Source Vulnerability format.
+type osvEntry struct {
+	ID               string                 `json:"id"`
+	Aliases          []string               `json:"aliases"`
+	Summary          string                 `json:"summary"`
+	Withdrawn        string                 `json:"withdrawn"`
+	Severity         []osvSeverity          `json:"severity"`
+	Affected         []osvAffected          `json:"affected"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges            []osvRange             `json:"ranges"`
+	Versions          []string               `json:"versions"`
+	Severity          []osvSeverity          `json:"severity"`
+	EcosystemSpecific map[string]interface{} `json:"ecosystem_specific"`
+	DatabaseSpecific  map[string]interface{} `json:"database_specific"`
+}
+
+type osvRange struct {
+	Type   string     `json:"type"`
+	Events []osvEvent `json:"events"`
+}
+
+type osvEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// VulnDatabase is an OSV vulnerability database, indexed by ecosystem and package name.
+type VulnDatabase struct {
+	entries map[string]map[string][]*osvEntry
+}
+
+// LoadVulnDatabase reads the OSV entries of a directory: the JSON files it
+// contains, as well as those of the zip archives OSV publishes per ecosystem.
+// Withdrawn entries are ignored.
+func LoadVulnDatabase(dir string) (*VulnDatabase, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	db := &VulnDatabase{entries: make(map[string]map[string][]*osvEntry)}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			db.add(path, content)
+		case ".zip":
+			return db.addArchive(path)
+		}
+		return nil
+	})
+	return db, err
+}
+
+func (db *VulnDatabase) addArchive(path string) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	for _, file := range archive.File {
+		if !strings.HasSuffix(strings.ToLower(file.Name), ".json") {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+		db.add(path+":"+file.Name, content)
+	}
+	return nil
+}
+
+func (db *VulnDatabase) add(path string, content []byte) {
+	var entry osvEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		logrus.Warnf("ignoring invalid OSV entry %s: %s", path, err)
+		return
+	}
+	if entry.ID == "" || entry.Withdrawn != "" {
+		return
+	}
+	indexed := make(map[string]bool)
+	for _, affected := range entry.Affected {
+		ecosystem := osvEcosystemName(affected.Package.Ecosystem)
+		name := osvPackageName(ecosystem, affected.Package.Name)
+		if indexed[ecosystem+"/"+name] {
+			continue
+		}
+		indexed[ecosystem+"/"+name] = true
+		if db.entries[ecosystem] == nil {
+			db.entries[ecosystem] = make(map[string][]*osvEntry)
+		}
+		db.entries[ecosystem][name] = append(db.entries[ecosystem][name], &entry)
+	}
+}
+
+// osvEcosystemName strips the release from an OSV ecosystem, e.g. Debian:12.
+func osvEcosystemName(ecosystem string) string {
+	return strings.SplitN(ecosystem, ":", 2)[0]
+}
+
+var pypiSeparators = regexp.MustCompile(`[-_.]+`)
+
+// osvPackageName normalizes package names of ecosystems with case or separator
+// insensitive names.
+func osvPackageName(ecosystem, name string) string {
+	if ecosystem == "PyPI" {
+		return pypiSeparators.ReplaceAllString(strings.ToLower(name), "-")
+	}
+	return name
+}
+
+// osvReleaseMatches reports whether the release of an OSV ecosystem, e.g. 12
+// in Debian:12 or v3.19 in Alpine:v3.19, applies to the distribution version.
+// Releases that aren't version numbers, such as Red Hat product streams, apply
+// to any version, as does any release when the version is unknown.
+func osvReleaseMatches(ecosystem, versionID string) bool {
+	parts := strings.SplitN(ecosystem, ":", 3)
+	if len(parts) < 2 || versionID == "" {
+		return true
+	}
+	release := strings.TrimPrefix(parts[1], "v")
+	if release == "" || release[0] < '0' || release[0] > '9' {
+		return true
+	}
+	return versionID == release || strings.HasPrefix(versionID, release+".")
+}
+
+// Match returns the vulnerabilities affecting the version of a package of the
+// given OSV ecosystem, e.g. Debian, for the distribution version, if any.
+// Versions are ordered by compare, the version ordering of the ecosystem.
+func (db *VulnDatabase) Match(ecosystem, versionID, name, version string, compare VersionComparator) []Vulnerability {
+	var vulns []Vulnerability
+	for _, entry := range db.entries[ecosystem][osvPackageName(ecosystem, name)] {
+		for _, affected := range entry.Affected {
+			if osvEcosystemName(affected.Package.Ecosystem) != ecosystem ||
+				osvPackageName(ecosystem, affected.Package.Name) != osvPackageName(ecosystem, name) ||
+				!osvReleaseMatches(affected.Package.Ecosystem, versionID) {
+				continue
+			}
+			fixed, isAffected := affectedVersion(affected, version, compare)
+			if !isAffected {
+				continue
+			}
+			severity, score := osvSeverityRating(entry, affected)
+			vulns = append(vulns, Vulnerability{
+				ID:        entry.ID,
+				Aliases:   entry.Aliases,
+				Package:   name,
+				Version:   version,
+				Ecosystem: affected.Package.Ecosystem,
+				Severity:  severity,
+				Score:     score,
+				Fixed:     fixed,
+				Summary:   entry.Summary,
+			})
+			break
+		}
+	}
+	return vulns
+}
+
+// affectedVersion reports whether version is affected, either listed as such or
+// within an ECOSYSTEM or SEMVER range, along with the version fixing it if known.
+func affectedVersion(affected osvAffected, version string, compare VersionComparator) (string, bool) {
+	for _, v := range affected.Versions {
+		if v == version {
+			return "", true
+		}
+	}
+	for _, r := range affected.Ranges {
+		if r.Type != "ECOSYSTEM" && r.Type != "SEMVER" {
+			continue
+		}
+		if fixed, ok := rangeAffects(r.Events, version, compare); ok {
+			return fixed, true
+		}
+	}
+	return "", false
+}
+
+// rangeAffects evaluates the events of an OSV range in version order: version
+// is affected once a lower or equal version introduced the vulnerability, until
+// a lower or equal version fixed it or a lower version was the last affected.
+func rangeAffects(events []osvEvent, version string, compare VersionComparator) (string, bool) {
+	eventVersion := func(e osvEvent) string {
+		return firstNonEmptyString(e.Introduced, e.Fixed, e.LastAffected)
+	}
+	sorted := append([]osvEvent{}, events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Introduced == "0" || sorted[j].Introduced == "0" {
+			return sorted[i].Introduced == "0" && sorted[j].Introduced != "0"
+		}
+		return compare(eventVersion(sorted[i]), eventVersion(sorted[j])) < 0
+	})
+
+	affected := false
+	fixed := ""
+	for _, event := range sorted {
+		switch {
+		case event.Introduced != "":
+			if event.Introduced == "0" || compare(version, event.Introduced) >= 0 {
+				affected = true
+			}
+		case event.Fixed != "":
+			if compare(version, event.Fixed) >= 0 {
+				affected = false
+			} else if affected && fixed == "" {
+				fixed = event.Fixed
+			}
+		case event.LastAffected != "":
+			if compare(version, event.LastAffected) > 0 {
+				affected = false
+			}
+		}
+	}
+	return fixed, affected
+}
+
+func firstNonEmptyString(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// osvSeverityRating returns the severity of a vulnerability: the rating given
+// by the database for the affected package or the vulnerability, e.g. GitHub
+// advisories, otherwise the rating of its CVSS v3 score or the severity given
+// by the distribution.
+func osvSeverityRating(entry *osvEntry, affected osvAffected) (string, float64) {
+	score := -1.0
+	distroSeverity := ""
+	for _, severity := range append(affected.Severity, entry.Severity...) {
+		switch severity.Type {
+		case "CVSS_V3":
+			if s, err := CVSSv3BaseScore(severity.Score); err == nil && score < 0 {
+				score = s
+			}
+		case "Ubuntu":
+			distroSeverity = ubuntuSeverityRating(severity.Score)
+		}
+	}
+	for _, specific := range []map[string]interface{}{affected.EcosystemSpecific, affected.DatabaseSpecific, entry.DatabaseSpecific} {
+		if rating, ok := specific["severity"].(string); ok && rating != "" {
+			rating = strings.ToUpper(rating)
+			if rating == "MODERATE" {
+				rating = "MEDIUM"
+			}
+			return rating, math.Max(score, 0)
+		}
+	}
+	if score >= 0 {
+		return CVSSRating(score), score
+	}
+	if distroSeverity != "" {
+		return distroSeverity, 0
+	}
+	return "UNKNOWN", 0
+}
+
+// CVSS v3 base metric weights
+var cvssWeights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// CVSSv3BaseScore computes the base score of a CVSS v3.0 or v3.1 vector, e.g.
+// CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H.
+func CVSSv3BaseScore(vector string) (float64, error) {
+	metrics := make(map[string]string)
+	for i, part := range strings.Split(vector, "/") {
+		fields := strings.SplitN(part, ":", 2)
+		if len(fields) != 2 {
+			return 0, fmt.Errorf("invalid CVSS vector %s", vector)
+		}
+		if i == 0 {
+			if fields[0] != "CVSS" || !strings.HasPrefix(fields[1], "3.") {
+				return 0, fmt.Errorf("not a CVSS v3 vector: %s", vector)
+			}
+			continue
+		}
+		metrics[fields[0]] = fields[1]
+	}
+	scopeChanged := metrics["S"] == "C"
+	if !scopeChanged && metrics["S"] != "U" {
+		return 0, fmt.Errorf("invalid CVSS scope in %s", vector)
+	}
+	weights := make(map[string]float64)
+	for metric, values := range cvssWeights {
+		weight, ok := values[metrics[metric]]
+		if !ok {
+			return 0, fmt.Errorf("invalid CVSS metric %s in %s", metric, vector)
+		}
+		weights[metric] = weight
+	}
+	if scopeChanged {
+		// privileges matter more when the scope changes
+		switch metrics["PR"] {
+		case "L":
+			weights["PR"] = 0.68
+		case "H":
+			weights["PR"] = 0.5
+		}
+	}
+
+	iss := 1 - (1-weights["C"])*(1-weights["I"])*(1-weights["A"])
+	impact := 6.42 * iss
+	if scopeChanged {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	exploitability := 8.22 * weights["AV"] * weights["AC"] * weights["PR"] * weights["UI"]
+	if impact <= 0 {
+		return 0, nil
+	}
+	if scopeChanged {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// cvssRoundUp rounds up to one decimal as specified by CVSS v3.1, avoiding
+// floating point errors.
+func cvssRoundUp(value float64) float64 {
+	i := int(math.Round(value * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
+
+// CVSSRating returns the qualitative severity rating of a CVSS score.
+func CVSSRating(score float64) string {
+	switch {
+	case score >= 9:
+		return "CRITICAL"
+	case score >= 7:
+		return "HIGH"
+	case score >= 4:
+		return "MEDIUM"
+	case score > 0:
+		return "LOW"
+	}
+	return "NONE"
+}
+
+// vulnerabilityKey identifies a vulnerability of a package regardless of its
+// version and installation path.
+func vulnerabilityKey(v Vulnerability) string {
+	return v.Manager + "/" + v.Package + "/" + v.ID
+}
+
+// GetVulnerabilityDiff returns the vulnerabilities introduced and resolved
+// between two images. A package which is upgraded but still vulnerable is
+// reported in neither.
+func GetVulnerabilityDiff(vulns1, vulns2 []Vulnerability) VulnerabilityDiff {
+	keys1 := make(map[string]bool)
+	for _, v := range vulns1 {
+		keys1[vulnerabilityKey(v)] = true
+	}
+	keys2 := make(map[string]bool)
+	for _, v := range vulns2 {
+		keys2[vulnerabilityKey(v)] = true
+	}
+
+	diff := VulnerabilityDiff{Introduced: []Vulnerability{}, Resolved: []Vulnerability{}}
+	for _, v := range vulns2 {
+		if !keys1[vulnerabilityKey(v)] {
+			diff.Introduced = append(diff.Introduced, v)
+		}
+	}
+	for _, v := range vulns1 {
+		if !keys2[vulnerabilityKey(v)] {
+			diff.Resolved = append(diff.Resolved, v)
+		}
+	}
+	SortVulnerabilities(diff.Introduced)
+	SortVulnerabilities(diff.Resolved)
+	return diff
+}
+
+// ubuntuSeverityRating maps an Ubuntu priority onto the CVSS rating scale.
+// Negligible issues rank below LOW, and untriaged ones are unknown.
+func ubuntuSeverityRating(priority string) string {
+	switch rating := strings.ToUpper(priority); rating {
+	case "CRITICAL", "HIGH", "MEDIUM", "LOW", "NEGLIGIBLE":
+		return rating
+	}
+	return ""
+}
+
+// severityOrder ranks the severities, unknown ones last
+var severityOrder = map[string]int{"CRITICAL": 0, "HIGH": 1, "MEDIUM": 2, "LOW": 3, "NEGLIGIBLE": 4}
+
+func severityRank(severity string) int {
+	if rank, ok := severityOrder[severity]; ok {
+		return rank
+	}
+	return len(severityOrder)
+}
+
+// SortVulnerabilities sorts vulnerabilities by decreasing severity, then by
+// package manager, package name, path and ID.
+func SortVulnerabilities(vulns []Vulnerability) {
+	sort.Slice(vulns, func(i, j int) bool {
+		v1, v2 := vulns[i], vulns[j]
+		if r1, r2 := severityRank(v1.Severity), severityRank(v2.Severity); r1 != r2 {
+			return r1 < r2
+		}
+		if v1.Manager != v2.Manager {
+			return v1.Manager < v2.Manager
+		}
+		if v1.Package != v2.Package {
+			return v1.Package < v2.Package
+		}
+		if v1.Path != v2.Path {
+			return v1.Path < v2.Path
+		}
+		return v1.ID < v2.ID
+	})
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2018 Google, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCVSSv3BaseScore(t *testing.T) {
+	testCases := []struct {
+		vector string
+		score  float64
+		valid  bool
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, true},
+		{"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", 5.5, true},
+		{"CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H", 9.9, true},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", 0, false},
+		{"CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 0, false},
+	}
+	for _, test := range testCases {
+		score, err := CVSSv3BaseScore(test.vector)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t but got error %v", test.vector, test.valid, err)
+			continue
+		}
+		if score != test.score {
+			t.Errorf("%s: expected %.1f but got %.1f", test.vector, test.score, score)
+		}
+	}
+}
+
+func TestRangeAffects(t *testing.T) {
+	events := []osvEvent{{Fixed: "1.2.0"}, {Introduced: "0"}, {Introduced: "2.0.0"}, {LastAffected: "2.1.0"}}
+	testCases := []struct {
+		version  string
+		affected bool
+		fixed    string
+	}{
+		{"1.0.0", true, "1.2.0"},
+		{"1.2.0", false, ""},
+		{"1.5.0", false, ""},
+		{"2.0.5", true, ""},
+		{"2.1.0", true, ""},
+		{"2.2.0", false, ""},
+	}
+	for _, test := range testCases {
+		fixed, affected := rangeAffects(events, test.version, CompareSemverVersions)
+		if affected != test.affected || fixed != test.fixed {
+			t.Errorf("%s: expected %t (fixed %q) but got %t (fixed %q)", test.version, test.affected, test.fixed, affected, fixed)
+		}
+	}
+}
+
+func TestRangeAffectsApk(t *testing.T) {
+	testCases := []struct {
+		fixed    string
+		version  string
+		affected bool
+	}{
+		// openssl in Alpine v3.17, ALPINE-CVE-2023-0464
+		{"3.0.8-r1", "3.0.8-r0", true},
+		{"3.0.8-r1", "3.0.8-r1", false},
+		{"3.0.8-r1", "3.0.10-r0", false},
+		// busybox in Alpine v3.18, ALPINE-CVE-2022-48174
+		{"1.36.1-r1", "1.36.0-r9", true},
+		{"1.36.1-r1", "1.36.1-r15", false},
+		// snapshots are newer than the release they are taken after
+		{"0.6.1_git20230101-r0", "0.6.1-r0", true},
+		{"0.6.1_git20230101-r0", "0.6.1_git20221215-r2", true},
+		{"0.6.1_git20230101-r0", "0.6.1_p1-r0", false},
+		{"2.40.0-r0", "2.40.0_rc1-r0", true},
+	}
+	for _, test := range testCases {
+		events := []osvEvent{{Introduced: "0"}, {Fixed: test.fixed}}
+		if _, affected := rangeAffects(events, test.version, CompareApkVersions); affected != test.affected {
+			t.Errorf("%s fixed in %s: expected affected %t but got %t", test.version, test.fixed, test.affected, affected)
+		}
+	}
+}
+
+func TestVulnDatabaseMatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osv")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	entries := map[string]string{
+		"DSA-5532-1.json": `{"id": "DSA-5532-1", "aliases": ["CVE-2023-5363"], "affected": [
+			{"package": {"ecosystem": "Debian:11", "name": "openssl"}, "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.1.1w-0+deb11u1"}]}]},
+			{"package": {"ecosystem": "Debian:12", "name": "openssl"}, "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "3.0.11-1~deb12u2"}]}]}]}`,
+		"GHSA-j8r2-6x86-q33q.json": `{"id": "GHSA-j8r2-6x86-q33q", "summary": "Unintended leak of Proxy-Authorization header",
+			"database_specific": {"severity": "MODERATE"}, "affected": [
+			{"package": {"ecosystem": "PyPI", "name": "Requests"}, "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.3.0"}, {"fixed": "2.31.0"}]}]}]}`,
+		"USN-6477-1.json": `{"id": "UBUNTU-CVE-2023-5678", "severity": [{"type": "Ubuntu", "score": "medium"}], "affected": [
+			{"package": {"ecosystem": "Ubuntu:22.04:LTS", "name": "openssl"}, "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "3.0.2-0ubuntu1.12"}]}]}]}`,
+		"PYSEC-withdrawn.json": `{"id": "PYSEC-0000-1", "withdrawn": "2023-01-01T00:00:00Z", "affected": [
+			{"package": {"ecosystem": "PyPI", "name": "requests"}, "versions": ["2.28.0"]}]}`,
+	}
+	for name, content := range entries {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write %s: %s", name, err)
+		}
+	}
+	archive, err := os.Create(filepath.Join(dir, "all.zip"))
+	if err != nil {
+		t.Fatalf("Unable to create archive: %s", err)
+	}
+	writer := zip.NewWriter(archive)
+	entry, _ := writer.Create("GHSA-c2qf-rxjj-qqgw.json")
+	entry.Write([]byte(`{"id": "GHSA-c2qf-rxjj-qqgw", "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"}],
+		"affected": [{"package": {"ecosystem": "npm", "name": "semver"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "7.0.0"}, {"fixed": "7.5.2"}]}]}]}`))
+	writer.Close()
+	archive.Close()
+
+	db, err := LoadVulnDatabase(dir)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %s", err)
+	}
+
+	expected := []Vulnerability{{ID: "DSA-5532-1", Aliases: []string{"CVE-2023-5363"}, Package: "openssl", Version: "3.0.9-1",
+		Ecosystem: "Debian:12", Severity: "UNKNOWN", Fixed: "3.0.11-1~deb12u2"}}
+	if vulns := db.Match("Debian", "12", "openssl", "3.0.9-1", CompareDebianVersions); !reflect.DeepEqual(vulns, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, vulns)
+	}
+	if vulns := db.Match("Debian", "12", "openssl", "3.0.11-1~deb12u2", CompareDebianVersions); len(vulns) != 0 {
+		t.Errorf("Expected fixed version not to be vulnerable but got: %v", vulns)
+	}
+
+	expected = []Vulnerability{{ID: "UBUNTU-CVE-2023-5678", Package: "openssl", Version: "3.0.2-0ubuntu1.10", Ecosystem: "Ubuntu:22.04:LTS",
+		Severity: "MEDIUM", Fixed: "3.0.2-0ubuntu1.12"}}
+	if vulns := db.Match("Ubuntu", "22.04", "openssl", "3.0.2-0ubuntu1.10", CompareDebianVersions); !reflect.DeepEqual(vulns, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, vulns)
+	}
+
+	expected = []Vulnerability{{ID: "GHSA-j8r2-6x86-q33q", Package: "requests", Version: "2.28.0", Ecosystem: "PyPI",
+		Severity: "MEDIUM", Fixed: "2.31.0", Summary: "Unintended leak of Proxy-Authorization header"}}
+	if vulns := db.Match("PyPI", "", "requests", "2.28.0", ComparePEP440Versions); !reflect.DeepEqual(vulns, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, vulns)
+	}
+
+	expected = []Vulnerability{{ID: "GHSA-c2qf-rxjj-qqgw", Package: "semver", Version: "7.5.1", Ecosystem: "npm",
+		Severity: "HIGH", Score: 7.5, Fixed: "7.5.2"}}
+	if vulns := db.Match("npm", "", "semver", "7.5.1", CompareSemverVersions); !reflect.DeepEqual(vulns, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, vulns)
+	}
+}
+
+func TestOSVReleaseMatches(t *testing.T) {
+	testCases := []struct {
+		ecosystem string
+		versionID string
+		matches   bool
+	}{
+		{"Debian:12", "12", true},
+		{"Debian:11", "12", false},
+		{"Alpine:v3.19", "3.19.1", true},
+		{"Alpine:v3.1", "3.19.1", false},
+		{"Ubuntu:22.04:LTS", "22.04", true},
+		{"Red Hat:enterprise_linux:9::appstream", "9.3", true},
+		{"Debian", "12", true},
+		{"Debian:12", "", true},
+	}
+	for _, test := range testCases {
+		if matches := osvReleaseMatches(test.ecosystem, test.versionID); matches != test.matches {
+			t.Errorf("%s for %s: expected %t but got %t", test.ecosystem, test.versionID, test.matches, matches)
+		}
+	}
+}
+
+func TestGetVulnerabilityDiff(t *testing.T) {
+	vulns1 := []Vulnerability{
+		{ID: "DSA-1", Package: "openssl", Version: "3.0.9-1", Manager: "apt", Severity: "HIGH"},
+		{ID: "DSA-2", Package: "curl", Version: "7.88.1-10", Manager: "apt", Severity: "UNKNOWN"},
+	}
+	vulns2 := []Vulnerability{
+		// upgraded but still vulnerable
+		{ID: "DSA-1", Package: "openssl", Version: "3.0.10-1", Manager: "apt", Severity: "HIGH"},
+		{ID: "GHSA-1", Package: "semver", Version: "7.5.1", Manager: "node", Severity: "LOW"},
+		{ID: "GHSA-2", Package: "requests", Version: "2.28.0", Manager: "pip", Severity: "CRITICAL"},
+		{ID: "UBUNTU-1", Package: "bash", Version: "5.1-6ubuntu1", Manager: "apt", Severity: "NEGLIGIBLE"},
+		{ID: "UBUNTU-2", Package: "zlib1g", Version: "1:1.2.11.dfsg-2ubuntu9", Manager: "apt", Severity: "MEDIUM"},
+	}
+	expected := VulnerabilityDiff{
+		Introduced: []Vulnerability{vulns2[2], vulns2[4], vulns2[1], vulns2[3]},
+		Resolved:   []Vulnerability{vulns1[1]},
+	}
+	if diff := GetVulnerabilityDiff(vulns1, vulns2); !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected: %v but got: %v", expected, diff)
+	}
+}